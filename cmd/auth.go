@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+
+	grpcDriver "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	"github.com/nullexp/finman-transaction-service/internal/config"
+)
+
+// newAuthInterceptor verifies tokens with the keys of the JWKS file or the PEM
+// public key files, accepts the API keys of apiKeys and authorizes calls with
+// grpc.Policy. It returns nil only when authentication is disabled, so a
+// missing configuration never leaves the server open.
+func newAuthInterceptor(cfg config.Auth, apiKeys auth.ApiKeyAuthenticator) (*auth.Interceptor, error) {
+	if cfg.Disabled {
+		return nil, nil
+	}
+
+	var keys auth.KeySet
+	var err error
+	switch {
+	case cfg.JWKSFile != "":
+		keys, err = auth.LoadJWKS(cfg.JWKSFile)
+	case len(cfg.PublicKeyFiles) > 0:
+		keys, err = auth.LoadPublicKeys(cfg.PublicKeyFiles...)
+	default:
+		err = errors.New("set a JWKS file or public key files, or disable authentication")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	options := []auth.VerifierOption{
+		auth.WithIssuer(cfg.Issuer),
+		auth.WithAudience(cfg.Audience),
+	}
+	if cfg.Leeway > 0 {
+		options = append(options, auth.WithLeeway(cfg.Leeway))
+	}
+	verifier, err := auth.NewVerifier(keys, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	interceptorOptions := []auth.InterceptorOption{
+		auth.WithPolicy(grpcDriver.Policy),
+		auth.WithApiKeys(apiKeys),
+		auth.WithPublicMethods("/grpc.reflection.v1.ServerReflection/", "/grpc.reflection.v1alpha.ServerReflection/", "/grpc.health.v1.Health/"),
+	}
+	if len(cfg.CertificateRoles) > 0 {
+		roles := make(map[string][]auth.Role, len(cfg.CertificateRoles))
+		for identity, names := range cfg.CertificateRoles {
+			for _, name := range names {
+				roles[identity] = append(roles[identity], auth.Role(name))
+			}
+		}
+		interceptorOptions = append(interceptorOptions, auth.WithCertificateRoles(roles))
+	}
+
+	return auth.NewInterceptor(verifier, interceptorOptions...), nil
+}
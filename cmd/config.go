@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/nullexp/finman-transaction-service/internal/config"
+)
+
+// loadConfig adds the variables of a .env file, when there is one, to the
+// environment and loads the configuration from args and the environment.
+func loadConfig(args []string) (config.Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return config.Config{}, fmt.Errorf("failed to load .env file: %w", err)
+	}
+	return config.Load(args, os.LookupEnv)
+}
+
+// printConfig implements "config print": it writes the effective configuration
+// with secrets redacted to stdout and reports whether it is valid.
+func printConfig(args []string) int {
+	cfg, err := loadConfig(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return exitFailed
+	}
+	return 0
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"github.com/nats-io/nats.go"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/broker"
+	"github.com/nullexp/finman-transaction-service/internal/config"
+)
+
+// newEventPublisher connects to the configured NATS server, events are not
+// published when there is none.
+func newEventPublisher(cfg config.NATS) (*broker.NatsEventPublisher, error) {
+	if cfg.URL == "" {
+		return nil, nil
+	}
+
+	conn, err := nats.Connect(cfg.URL, nats.Name("finman-transaction-service"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return broker.NewNatsEventPublisher(conn, broker.WithSubjectPrefix(cfg.SubjectPrefix), broker.WithTimeout(cfg.PublishTimeout)), nil
+}
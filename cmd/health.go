@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/health"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+)
+
+// newHealthChecker checks that the database is reachable, that its schema is
+// at least at the version migrated to on startup and that the notification
+// backends able to tell are healthy.
+func newHealthChecker(db *sql.DB, m *migrate.Migrate, backends map[string]driven.HealthChecker, options ...health.CheckerOption) (*health.Checker, error) {
+	migrated, _, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return nil, err
+	}
+
+	options = append(options,
+		health.WithCheck("database", db.PingContext),
+		health.WithCheck("migrations", func(ctx context.Context) error {
+			version, dirty, err := m.Version()
+			if err != nil {
+				return err
+			}
+			if dirty {
+				return fmt.Errorf("migration %d failed halfway", version)
+			}
+			if version < migrated {
+				return fmt.Errorf("schema version %d is older than %d", version, migrated)
+			}
+			return nil
+		}),
+	)
+	for name, backend := range backends {
+		options = append(options, health.WithCheck(name, backend.CheckHealth))
+	}
+	return health.NewChecker(options...), nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/health"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// newHTTPServer serves the default Prometheus registry, including the
+// connection pool statistics of db, at /metrics and the probes of checker at
+// /healthz and /readyz on addr.
+func newHTTPServer(addr string, db *sql.DB, checker *health.Checker) (*http.Server, error) {
+	if err := prometheus.Register(collectors.NewDBStatsCollector(db, "finman")); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/healthz", checker.LivenessHandler())
+	mux.Handle("/readyz", checker.ReadinessHandler())
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/lifecycle"
+	"google.golang.org/grpc"
+)
+
+const (
+	// exitFailed means the service failed to start or a component failed while running
+	exitFailed = 1
+	// exitUncleanShutdown means the service stopped on request, but not everything
+	// stopped in time or cleanly
+	exitUncleanShutdown = 2
+)
+
+// worker runs a background job until the service shuts down.
+func worker(name string, run func(ctx context.Context) error) lifecycle.Component {
+	return lifecycle.Component{Name: name, Run: run}
+}
+
+// closer releases a resource when the service shuts down.
+func closer(name string, close func() error) lifecycle.Component {
+	return lifecycle.Component{Name: name, Stop: func(context.Context) error {
+		return close()
+	}}
+}
+
+func httpComponent(server *http.Server) lifecycle.Component {
+	var lis net.Listener
+	return lifecycle.Component{
+		Name: "http server",
+		Start: func(ctx context.Context) error {
+			var err error
+			lis, err = net.Listen("tcp", server.Addr)
+			return err
+		},
+		Run: func(ctx context.Context) error {
+			slog.Info("HTTP server listening", "address", server.Addr)
+			if err := server.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: server.Shutdown,
+	}
+}
+
+// grpcComponent serves on addr. Stopping it runs leaveRotation, keeps serving
+// for drainDelay so clients notice, runs beforeStop to end long lived streams,
+// then refuses new calls and waits for the ones in flight, which are cancelled
+// once ctx is done.
+func grpcComponent(server *grpc.Server, addr string, drainDelay time.Duration, leaveRotation, beforeStop func()) lifecycle.Component {
+	var lis net.Listener
+	return lifecycle.Component{
+		Name: "grpc server",
+		Start: func(ctx context.Context) error {
+			var err error
+			lis, err = net.Listen("tcp", addr)
+			return err
+		},
+		Run: func(ctx context.Context) error {
+			slog.Info("gRPC server listening", "address", addr)
+			return server.Serve(lis)
+		},
+		Stop: func(ctx context.Context) error {
+			leaveRotation()
+			select {
+			case <-time.After(drainDelay):
+			case <-ctx.Done():
+			}
+			beforeStop()
+			stopped := make(chan struct{})
+			go func() {
+				server.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				server.Stop()
+				return fmt.Errorf("cancelled the calls in flight: %w", ctx.Err())
+			}
+		},
+	}
+}
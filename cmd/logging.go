@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/logging"
+	"github.com/nullexp/finman-transaction-service/internal/config"
+)
+
+// newLogger writes records in the configured format at the configured level
+// and above through the redacting handler.
+func newLogger(cfg config.Log) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", cfg.Level)
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, options)
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, options)
+	default:
+		return nil, fmt.Errorf("log format must be json or text, got %q", cfg.Format)
+	}
+	return slog.New(logging.NewHandler(handler)), nil
+}
+
+// fatal logs err and exits like log.Fatal.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -1,101 +1,322 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
-	adapterDriven "github.com/nullexp/finman-transaction-service/internal/adapter/driven"
 	drivenDb "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
 	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
 
 	grpcDriver "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/logging"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/metrics"
 	txv1 "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/proto/transaction/v1"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/health"
 	driver "github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	"github.com/nullexp/finman-transaction-service/internal/config"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/lifecycle"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 func main() {
-	log.Println("Starting the server")
+	args := os.Args[1:]
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		os.Exit(printConfig(args[2:]))
+	}
+	if len(args) >= 2 && args[0] == "outbox" && args[1] == "requeue" {
+		os.Exit(requeueOutboxMessage(args[2:]))
+	}
 
-	err := godotenv.Load()
+	cfg, err := loadConfig(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err == nil {
+		err = cfg.Validate()
+	}
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		fatal("invalid configuration", err)
 	}
 
-	m, err := migrate.New("file://internal/adapter/driven/db/migration",
-		fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-			os.Getenv("DB_USER"),
-			os.Getenv("DB_PASSWORD"),
-			os.Getenv("DB_HOST"),
-			os.Getenv("DB_PORT"),
-			os.Getenv("DB_NAME")))
+	logger, err := newLogger(cfg.Log)
 	if err != nil {
-		log.Fatal("Error loading migration files ", err)
+		fatal("failed to configure logging", err)
 	}
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		log.Fatal("Error calling up function ", err)
+	slog.SetDefault(logger)
+	slog.Info("starting the server")
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	manager := lifecycle.NewManager(lifecycle.WithShutdownTimeout(cfg.Server.ShutdownTimeout))
+	code := 0
+	if err := start(ctx, cfg, manager, logger); err != nil {
+		slog.Error("failed to start", "error", err)
+		code = exitFailed
+	} else {
+		slog.Info("successfully initialized")
+		if err := manager.Wait(ctx); err != nil {
+			slog.Error("shutting down after a failure", "error", err)
+			code = exitFailed
+		} else {
+			slog.Info("shutting down")
+		}
 	}
+	// A second signal kills the process instead of waiting for the shutdown
+	stop()
 
-	dsn := "host=" + os.Getenv("DB_HOST") +
-		" user=" + os.Getenv("DB_USER") +
-		" password=" + os.Getenv("DB_PASSWORD") +
-		" dbname=" + os.Getenv("DB_NAME") +
-		" port=" + os.Getenv("DB_PORT") +
-		" sslmode=disable"
+	if err := manager.Shutdown(); err != nil {
+		slog.Error("failed to shut down cleanly", "error", err)
+		if code == 0 {
+			code = exitUncleanShutdown
+		}
+	}
+	if code == 0 {
+		slog.Info("stopped")
+	}
+	os.Exit(code)
+}
+
+// start starts the components of the service in order: tracing, the database
+// and its migrations, the background workers and finally the servers. The
+// manager stops them in reverse order.
+func start(ctx context.Context, cfg config.Config, manager *lifecycle.Manager, logger *slog.Logger) error {
+	shutdownTracing, err := setupTracing(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to configure tracing: %w", err)
+	}
+	// Stopped last, so the spans of the shutdown are flushed too
+	if err := manager.Start(ctx, lifecycle.Component{Name: "tracing", Stop: shutdownTracing}); err != nil {
+		return err
+	}
 
-	log.Println("DSN:", dsn)
+	m, err := migrate.New("file://"+cfg.Database.MigrationsDir, cfg.Database.MigrationURL())
+	if err != nil {
+		return fmt.Errorf("failed to load migration files: %w", err)
+	}
+	if err := manager.Start(ctx, closer("migrations", func() error {
+		sourceErr, dbErr := m.Close()
+		return errors.Join(sourceErr, dbErr)
+	})); err != nil {
+		return err
+	}
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("failed to migrate the database: %w", err)
+	}
 
+	dsn := cfg.Database.DSN()
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		log.Fatalf("failed to connect database: %v", err)
+		return fmt.Errorf("failed to connect database: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
+	if err := manager.Start(ctx, closer("database", db.Close)); err != nil {
+		return err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	err = db.Ping()
+	txFactory := drivenDb.NewPostgresDbTransactionFactory(db)
+	apiKeys := driver.NewApiKeyService(repository.NewApiKeyRepositoryFactory(), txFactory)
+	if err := manager.Start(ctx, worker("api key usage", apiKeys.RecordUsage)); err != nil {
+		return err
+	}
+	apiKeyService := grpcDriver.NewApiKeyService(apiKeys)
+
+	metricsInterceptor, err := metrics.NewInterceptor(prometheus.DefaultRegisterer)
+	if err != nil {
+		return fmt.Errorf("failed to register RPC metrics: %w", err)
+	}
+	logInterceptor := logging.NewInterceptor(logger)
+	serverOptions := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(metricsInterceptor.Unary(), logInterceptor.Unary()),
+		grpc.ChainStreamInterceptor(metricsInterceptor.Stream(), logInterceptor.Stream()),
+	}
+	creds, err := newServerCredentials(cfg.TLS)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	if creds != nil {
+		serverOptions = append(serverOptions, grpc.Creds(creds))
+	} else {
+		slog.Warn("TLS mode is none, connections are not encrypted")
+	}
+	if peerLimiter := newPeerRateLimiter(cfg.RateLimit); peerLimiter != nil {
+		serverOptions = append(serverOptions, grpc.ChainUnaryInterceptor(peerLimiter.Unary()), grpc.ChainStreamInterceptor(peerLimiter.Stream()))
+	}
+	authInterceptor, err := newAuthInterceptor(cfg.Auth, apiKeyService)
+	if err != nil {
+		return fmt.Errorf("failed to configure authentication: %w", err)
+	}
+	if authInterceptor != nil {
+		serverOptions = append(serverOptions,
+			grpc.ChainUnaryInterceptor(authInterceptor.Unary(), logInterceptor.UnaryCaller()),
+			grpc.ChainStreamInterceptor(authInterceptor.Stream(), logInterceptor.StreamCaller()))
+	} else {
+		slog.Warn("authentication is disabled, RPCs are not authenticated")
+	}
+	limiter, err := newRateLimiter(cfg.RateLimit, txFactory)
 	if err != nil {
-		log.Fatalf("failed to ping database: %v", err)
+		return fmt.Errorf("failed to configure rate limits: %w", err)
 	}
+	if limiter != nil {
+		serverOptions = append(serverOptions, grpc.ChainUnaryInterceptor(limiter.Unary()), grpc.ChainStreamInterceptor(limiter.Stream()))
+	}
+
+	// Create a new gRPC server
+	s := grpc.NewServer(serverOptions...)
 
-	port := os.Getenv("PORT")
-	ip := os.Getenv("IP")
+	repoFactory := repository.NewTracedTransactionRepositoryFactory(repository.NewTransactionRepositoryFactory())
+	outboxFactory := repository.NewOutboxRepositoryFactory()
 
-	addr := fmt.Sprintf("%s:%v", ip, port)
-	// Create a TCP listener
-	lis, err := net.Listen("tcp", addr)
+	secretCipher, err := repository.NewSecretCipher(cfg.Notifications.WebhookSecretKey)
+	if err != nil {
+		return fmt.Errorf("failed to configure webhook secret encryption: %w", err)
+	}
+	channels, err := newNotificationChannels(cfg.Notifications, db, secretCipher)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		return fmt.Errorf("failed to configure notifications: %w", err)
 	}
-	_ = lis
+	renderer, err := newNotificationRenderer(cfg.Notifications.TemplatesDir)
+	if err != nil {
+		return fmt.Errorf("failed to load notification templates: %w", err)
+	}
+	preferencesFactory := repository.NewNotificationPreferencesRepositoryFactory()
+	dispatcher := driver.NewNotificationDispatcher(txFactory, preferencesFactory, outboxFactory, renderer, channels)
+	relay := driver.NewOutboxRelay(txFactory, outboxFactory)
+	relay.Handle(domainModel.OutboxKindNotification, dispatcher.Handle)
+	relay.Handle(domainModel.OutboxKindDigest, dispatcher.HandleDigest)
 
-	log.Println("successfully initialized")
+	if cfg.Features.Digests {
+		digestJob := driver.NewDigestJob(txFactory, preferencesFactory, repoFactory, repository.NewDigestRunRepositoryFactory(), outboxFactory)
+		if err := manager.Start(ctx, worker("digest job", digestJob.Run)); err != nil {
+			return err
+		}
+	}
 
-	// Create a new gRPC server
-	s := grpc.NewServer()
+	var options []driver.Option
+	publisher, err := newEventPublisher(cfg.NATS)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	if publisher != nil {
+		// Closed after the relay stopped publishing
+		if err := manager.Start(ctx, closer("nats", publisher.Close)); err != nil {
+			return err
+		}
+		relay.Handle(domainModel.OutboxKindEvent, driver.NewEventPublishingHandler(publisher))
+		options = append(options, driver.WithEventPublishing())
+		slog.Info("publishing transaction events to NATS")
+	}
+	if err := manager.Start(ctx, worker("outbox relay", relay.Run)); err != nil {
+		return err
+	}
 
-	// Register the Greeter service
+	pageTokenCodec := driver.NewRandomPageTokenCodec()
+	if cfg.Server.PageTokenSecret != "" {
+		pageTokenCodec = driver.NewPageTokenCodec([]byte(cfg.Server.PageTokenSecret))
+	} else {
+		slog.Warn("no page token secret is set, page tokens will not survive restarts or work across replicas")
+	}
+	options = append(options, driver.WithPageTokenCodec(pageTokenCodec))
+	options = append(options, driver.WithAllTransactionsLimit(cfg.Server.AllTransactionsLimit))
 
-	txFactory := drivenDb.NewPostgresDbTransactionFactory(db)
-	repoFactory := repository.NewTransactionRepositoryFactory()
+	// Ends the subscriptions when the gRPC server stops
+	closeSubscriptions := func() {}
+	if cfg.Features.Subscriptions {
+		eventListener := drivenDb.NewPostgresEventListener(dsn, db)
+		if err := manager.Start(ctx, worker("transaction event listener", eventListener.Run)); err != nil {
+			return err
+		}
+		options = append(options, driver.WithTransactionEvents(repository.NewTransactionEventRepositoryFactory(), eventListener))
+		closeSubscriptions = eventListener.Close
+	}
+
+	alertSettingsFactory := repository.NewAlertSettingsRepositoryFactory()
+	if cfg.Features.Alerts {
+		options = append(options, driver.WithAlerter(driver.NewAlerter(repoFactory, alertSettingsFactory, outboxFactory)))
+	}
 
-	ns := adapterDriven.NewMockNotificationService()
-	txService := driver.NewTransactionService(repoFactory, txFactory, ns)
-	grpcService := grpcDriver.NewTransactionService(txService)
+	txService := driver.NewTransactionService(repoFactory, txFactory, outboxFactory, options...)
+	grpcService := grpcDriver.NewTransactionService(driver.NewTracedTransactionService(txService))
 	txv1.RegisterTransactionServiceServer(s, grpcService)
 
-	// Register reflection service on gRPC server.
-	reflection.Register(s)
+	webhookService := driver.NewWebhookService(repository.NewWebhookRepositoryFactory(secretCipher), txFactory, pageTokenCodec)
+	txv1.RegisterWebhookServiceServer(s, grpcDriver.NewWebhookService(webhookService))
+
+	preferencesService := driver.NewNotificationPreferencesService(preferencesFactory, txFactory)
+	txv1.RegisterNotificationPreferencesServiceServer(s, grpcDriver.NewNotificationPreferencesService(preferencesService))
+
+	alertSettingsService := driver.NewAlertSettingsService(alertSettingsFactory, txFactory)
+	txv1.RegisterAlertSettingsServiceServer(s, grpcDriver.NewAlertSettingsService(alertSettingsService))
+
+	txv1.RegisterApiKeyServiceServer(s, apiKeyService)
 
-	// Log and start the server
-	log.Printf("gRPC server listening on %s", addr)
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	backends := make(map[string]driven.HealthChecker)
+	for name, channel := range channels {
+		if backend, ok := channel.(driven.HealthChecker); ok {
+			backends["notifications_"+name] = backend
+		}
+	}
+	if publisher != nil {
+		backends["nats"] = publisher
+	}
+	checker, err := newHealthChecker(db, m, backends, health.WithInterval(cfg.Health.Interval), health.WithCheckTimeout(cfg.Health.CheckTimeout), health.WithServices(
+		txv1.TransactionService_ServiceDesc.ServiceName,
+		txv1.WebhookService_ServiceDesc.ServiceName,
+		txv1.NotificationPreferencesService_ServiceDesc.ServiceName,
+		txv1.AlertSettingsService_ServiceDesc.ServiceName,
+		txv1.ApiKeyService_ServiceDesc.ServiceName,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to configure health checks: %w", err)
+	}
+	healthpb.RegisterHealthServer(s, checker.Server())
+	if err := manager.Start(ctx, worker("health checks", func(ctx context.Context) error {
+		checker.Run(ctx)
+		return nil
+	})); err != nil {
+		return err
 	}
+
+	httpServer, err := newHTTPServer(cfg.Server.HTTPAddr, db, checker)
+	if err != nil {
+		return fmt.Errorf("failed to configure the HTTP server: %w", err)
+	}
+	// Stopped after the gRPC server, so probes and metrics stay available while it drains
+	if err := manager.Start(ctx, httpComponent(httpServer)); err != nil {
+		return err
+	}
+
+	// Register reflection service on gRPC server.
+	if cfg.Features.Reflection {
+		reflection.Register(s)
+	}
+
+	addr := net.JoinHostPort(cfg.Server.IP, strconv.Itoa(cfg.Server.Port))
+	return manager.Start(ctx, grpcComponent(s, addr, cfg.Server.DrainDelay, checker.Shutdown, closeSubscriptions))
 }
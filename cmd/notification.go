@@ -0,0 +1,83 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+
+	adapterDriven "github.com/nullexp/finman-transaction-service/internal/adapter/driven"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/directory"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/email"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/i18n"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/webhook"
+	"github.com/nullexp/finman-transaction-service/internal/config"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+)
+
+// newNotificationChannels creates the configured channels, "webhook" by
+// default so registered webhook subscriptions are always served.
+func newNotificationChannels(cfg config.Notifications, db *sql.DB, secretCipher *repository.SecretCipher) (map[string]driven.NotificationService, error) {
+	channels := make(map[string]driven.NotificationService)
+	for _, name := range cfg.Channels {
+		var service driven.NotificationService
+		var err error
+		switch name {
+		case "log":
+			service = adapterDriven.NewMockNotificationService()
+		case "webhook":
+			service = newWebhookNotificationService(cfg.WebhookEndpoints, db, secretCipher)
+		case "email":
+			service, err = newEmailNotificationService(cfg.SMTP, cfg.UserEmails)
+		default:
+			err = fmt.Errorf("unknown notification channel %q", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("sending notifications", "channel", name)
+		channels[name] = service
+	}
+	return channels, nil
+}
+
+// newNotificationRenderer loads the built-in notification templates, replaced
+// by those in dir when set.
+func newNotificationRenderer(dir string) (*i18n.Renderer, error) {
+	if dir != "" {
+		return i18n.LoadRenderer(os.DirFS(dir))
+	}
+	return i18n.NewRenderer()
+}
+
+func newWebhookNotificationService(endpoints map[string][]config.WebhookEndpoint, db *sql.DB, secretCipher *repository.SecretCipher) driven.NotificationService {
+	subscriptions := webhook.NewSubscriptions(repository.NewWebhookRepository(db, secretCipher))
+	resolvers := webhook.Resolvers{subscriptions}
+	if len(endpoints) > 0 {
+		static := make(webhook.StaticEndpoints, len(endpoints))
+		for userId, configured := range endpoints {
+			for _, endpoint := range configured {
+				static[userId] = append(static[userId], webhook.Endpoint{Id: endpoint.Id, URL: endpoint.URL, Secret: endpoint.Secret})
+			}
+		}
+		resolvers = append(resolvers, static)
+	}
+	return webhook.NewNotificationService(resolvers, webhook.WithDeliveryRecorder(subscriptions))
+}
+
+func newEmailNotificationService(cfg config.SMTP, emails map[string]string) (driven.NotificationService, error) {
+	tlsMode := email.TLSMode(cfg.TLS)
+	if tlsMode != email.TLSStartTLS && tlsMode != email.TLSNone {
+		return nil, fmt.Errorf("invalid SMTP TLS mode %q, use starttls or none", tlsMode)
+	}
+
+	return email.NewNotificationService(email.Config{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		From:     cfg.From,
+		TLS:      tlsMode,
+	}, directory.StaticUserDirectory(emails)), nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	drivenDb "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	driver "github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+)
+
+// requeueOutboxMessage implements "outbox requeue <id>": it sets a dead outbox
+// message back to pending, so it is attempted again and releases the later
+// messages of its ordering key.
+func requeueOutboxMessage(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: outbox requeue <id> [flags]")
+		return exitFailed
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid outbox message id %q\n", args[0])
+		return exitFailed
+	}
+	cfg, err := loadConfig(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+
+	db, err := sql.Open("postgres", cfg.Database.DSN())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	defer db.Close()
+
+	relay := driver.NewOutboxRelay(drivenDb.NewPostgresDbTransactionFactory(db), repository.NewOutboxRepositoryFactory())
+	if err := relay.Requeue(context.Background(), id); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to requeue outbox message %d: %v\n", id, err)
+		return exitFailed
+	}
+	fmt.Printf("requeued outbox message %d\n", id)
+	return 0
+}
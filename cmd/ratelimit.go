@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/ratelimit"
+	"github.com/nullexp/finman-transaction-service/internal/config"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+)
+
+// newRateLimiter applies the configured limits of full method names with "*"
+// for the default, and returns nil when there are none. The postgres store
+// shares the limits between replicas.
+func newRateLimiter(cfg config.RateLimit, txFactory db.DbTransactionFactory) (*ratelimit.Limiter, error) {
+	if len(cfg.Limits) == 0 {
+		return nil, nil
+	}
+
+	var options []ratelimit.LimiterOption
+	for method, configured := range cfg.Limits {
+		if configured.Rate <= 0 || configured.Burst < 1 {
+			return nil, fmt.Errorf("limit of %s needs a positive rate and burst", method)
+		}
+		limit := domainModel.RateLimit{Rate: configured.Rate, Burst: configured.Burst}
+		if method == "*" {
+			options = append(options, ratelimit.WithDefaultLimit(limit))
+		} else {
+			options = append(options, ratelimit.WithLimit(method, limit))
+		}
+	}
+
+	var store ratelimit.Store
+	switch cfg.Store {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "postgres":
+		store = ratelimit.NewRepositoryStore(txFactory, repository.NewRateLimitRepositoryFactory())
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q, use memory or postgres", cfg.Store)
+	}
+	return ratelimit.NewLimiter(store, options...), nil
+}
+
+// newPeerRateLimiter limits calls per peer address ahead of authentication, so
+// unauthenticated calls cannot exhaust the verification of tokens and API keys.
+// Its buckets are always kept in memory to not reach the database either.
+func newPeerRateLimiter(cfg config.RateLimit) *ratelimit.Limiter {
+	if cfg.PeerRate <= 0 || cfg.PeerBurst < 1 {
+		return nil
+	}
+	limit := domainModel.RateLimit{Rate: cfg.PeerRate, Burst: cfg.PeerBurst}
+	return ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.WithDefaultLimit(limit), ratelimit.WithSubject(ratelimit.PeerSubject))
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/transport"
+	"github.com/nullexp/finman-transaction-service/internal/config"
+	"google.golang.org/grpc/credentials"
+)
+
+// newServerCredentials secures the listener as configured by the TLS mode,
+// which has to be set explicitly so the server never falls back to plaintext.
+func newServerCredentials(cfg config.TLS) (credentials.TransportCredentials, error) {
+	if cfg.Mode == "" {
+		return nil, errors.New("set the TLS mode to tls, mtls or none")
+	}
+	return transport.NewServerCredentials(transport.Config{
+		Mode:         transport.Mode(cfg.Mode),
+		CertFile:     cfg.CertFile,
+		KeyFile:      cfg.KeyFile,
+		ClientCAFile: cfg.ClientCAFile,
+	})
+}
@@ -0,0 +1,87 @@
+package broker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	eventsv1 "github.com/nullexp/finman-transaction-service/internal/schema/transaction/events/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// SchemaName identifies the protobuf message of published events, it changes
+// with every incompatible version of the schema.
+const SchemaName = "transaction.events.v1.TransactionEvent"
+
+var eventTypes = map[model.TransactionEventType]eventsv1.TransactionEventType{
+	model.TransactionCreated: eventsv1.TransactionEventType_TRANSACTION_EVENT_TYPE_CREATED,
+	model.TransactionUpdated: eventsv1.TransactionEventType_TRANSACTION_EVENT_TYPE_UPDATED,
+	model.TransactionDeleted: eventsv1.TransactionEventType_TRANSACTION_EVENT_TYPE_DELETED,
+}
+
+// MarshalTransactionEvent encodes event with the published protobuf schema.
+func MarshalTransactionEvent(event model.TransactionEvent) ([]byte, error) {
+	eventType, ok := eventTypes[event.Type]
+	if !ok {
+		return nil, fmt.Errorf("events of type %q are not published", event.Type)
+	}
+
+	return proto.Marshal(&eventsv1.TransactionEvent{
+		Id:       event.Id,
+		Sequence: event.Sequence,
+		Type:     eventType,
+		UserId:   event.UserId,
+		Transaction: &eventsv1.Transaction{
+			Id:          event.Transaction.Id,
+			UserId:      event.Transaction.UserId,
+			Type:        event.Transaction.Type,
+			Amount:      event.Transaction.Amount,
+			Date:        timestamp(event.Transaction.Date),
+			Description: event.Transaction.Description,
+			CreatedAt:   timestamp(event.Transaction.CreatedAt),
+			UpdatedAt:   timestamp(event.Transaction.UpdatedAt),
+		},
+		OccurredAt: timestamp(event.OccurredAt),
+	})
+}
+
+// UnmarshalTransactionEvent decodes an event published by MarshalTransactionEvent.
+func UnmarshalTransactionEvent(data []byte) (*model.TransactionEvent, error) {
+	var message eventsv1.TransactionEvent
+	if err := proto.Unmarshal(data, &message); err != nil {
+		return nil, err
+	}
+
+	event := &model.TransactionEvent{
+		Id:         message.Id,
+		Sequence:   message.Sequence,
+		UserId:     message.UserId,
+		OccurredAt: message.OccurredAt.AsTime(),
+	}
+	for domainType, eventType := range eventTypes {
+		if eventType == message.Type {
+			event.Type = domainType
+		}
+	}
+	if transaction := message.Transaction; transaction != nil {
+		event.Transaction = model.Transaction{
+			Id:          transaction.Id,
+			UserId:      transaction.UserId,
+			Type:        transaction.Type,
+			Amount:      transaction.Amount,
+			Date:        transaction.Date.AsTime(),
+			Description: transaction.Description,
+			CreatedAt:   transaction.CreatedAt.AsTime(),
+			UpdatedAt:   transaction.UpdatedAt.AsTime(),
+		}
+	}
+	return event, nil
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,94 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+const (
+	// DefaultSubjectPrefix is followed by the event type, e.g. finman.transactions.v1.created
+	DefaultSubjectPrefix = "finman.transactions.v1"
+	// SchemaHeader names the protobuf message of the payload
+	SchemaHeader = "Finman-Schema"
+	// UserIdHeader carries the user of the event so consumers can route without decoding
+	UserIdHeader = "Finman-User-Id"
+
+	defaultTimeout = 5 * time.Second
+)
+
+// NatsEventPublisher publishes transaction events to NATS. Every message
+// carries the event id in the Nats-Msg-Id header, so a JetStream stream on the
+// subjects drops duplicates within its deduplication window.
+type NatsEventPublisher struct {
+	conn          *nats.Conn
+	subjectPrefix string
+	timeout       time.Duration
+}
+
+// NatsOption configures a NatsEventPublisher.
+type NatsOption func(*NatsEventPublisher)
+
+func WithSubjectPrefix(prefix string) NatsOption {
+	return func(p *NatsEventPublisher) {
+		p.subjectPrefix = prefix
+	}
+}
+
+// WithTimeout bounds how long a publish waits for the server to receive the event.
+func WithTimeout(timeout time.Duration) NatsOption {
+	return func(p *NatsEventPublisher) {
+		p.timeout = timeout
+	}
+}
+
+func NewNatsEventPublisher(conn *nats.Conn, options ...NatsOption) *NatsEventPublisher {
+	p := &NatsEventPublisher{conn: conn, subjectPrefix: DefaultSubjectPrefix, timeout: defaultTimeout}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Subject returns the subject events of eventType are published on.
+func (p *NatsEventPublisher) Subject(eventType model.TransactionEventType) string {
+	return p.subjectPrefix + "." + string(eventType)
+}
+
+// Close flushes the events not yet sent and closes the connection.
+func (p *NatsEventPublisher) Close() error {
+	defer p.conn.Close()
+	return p.conn.Flush()
+}
+
+// PublishTransactionEvent returns once the server received the event.
+func (p *NatsEventPublisher) PublishTransactionEvent(ctx context.Context, event model.TransactionEvent) error {
+	data, err := MarshalTransactionEvent(event)
+	if err != nil {
+		return err
+	}
+
+	message := nats.NewMsg(p.Subject(event.Type))
+	message.Data = data
+	message.Header.Set(nats.MsgIdHdr, event.Id)
+	message.Header.Set(SchemaHeader, SchemaName)
+	message.Header.Set(UserIdHeader, event.UserId)
+	if err := p.conn.PublishMsg(message); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return p.conn.FlushWithContext(ctx)
+}
+
+// CheckHealth fails unless the connection to the server is established.
+func (p *NatsEventPublisher) CheckHealth(ctx context.Context) error {
+	if status := p.conn.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("nats connection is %s", status)
+	}
+	return nil
+}
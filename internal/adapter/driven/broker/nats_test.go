@@ -0,0 +1,88 @@
+package broker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/broker"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func runServer(t *testing.T) *server.Server {
+	s, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, NoLog: true, NoSigs: true})
+	assert.NoError(t, err)
+	go s.Start()
+	if !s.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+func TestNatsEventPublisher(t *testing.T) {
+	s := runServer(t)
+	conn, err := nats.Connect(s.ClientURL())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	messages := make(chan *nats.Msg, 10)
+	subscription, err := conn.ChanSubscribe(broker.DefaultSubjectPrefix+".>", messages)
+	assert.NoError(t, err)
+	defer subscription.Unsubscribe()
+	assert.NoError(t, conn.Flush())
+
+	publisher := broker.NewNatsEventPublisher(conn)
+	userId := uuid.New().String()
+	event := model.TransactionEvent{
+		Id:       uuid.New().String(),
+		Sequence: 7,
+		Type:     model.TransactionUpdated,
+		UserId:   userId,
+		Transaction: model.Transaction{
+			Id:          uuid.New().String(),
+			UserId:      userId,
+			Type:        "deposit",
+			Amount:      250,
+			Date:        time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+			Description: "salary",
+			CreatedAt:   time.Date(2024, 3, 1, 10, 0, 1, 0, time.UTC),
+			UpdatedAt:   time.Date(2024, 3, 2, 9, 30, 0, 0, time.UTC),
+		},
+		OccurredAt: time.Date(2024, 3, 2, 9, 30, 0, 0, time.UTC),
+	}
+	assert.NoError(t, publisher.PublishTransactionEvent(context.Background(), event))
+
+	select {
+	case message := <-messages:
+		assert.Equal(t, "finman.transactions.v1.updated", message.Subject)
+		assert.Equal(t, event.Id, message.Header.Get(nats.MsgIdHdr))
+		assert.Equal(t, broker.SchemaName, message.Header.Get(broker.SchemaHeader))
+		assert.Equal(t, userId, message.Header.Get(broker.UserIdHeader))
+
+		decoded, err := broker.UnmarshalTransactionEvent(message.Data)
+		assert.NoError(t, err)
+		assert.Equal(t, event, *decoded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not received")
+	}
+
+	// Only changes to stored transactions are published
+	event.Type = model.InsufficientBalance
+	assert.Error(t, publisher.PublishTransactionEvent(context.Background(), event))
+}
+
+func TestNatsEventPublisherFailsWhenDisconnected(t *testing.T) {
+	s := runServer(t)
+	conn, err := nats.Connect(s.ClientURL(), nats.NoReconnect())
+	assert.NoError(t, err)
+	conn.Close()
+
+	publisher := broker.NewNatsEventPublisher(conn, broker.WithTimeout(time.Second))
+	err = publisher.PublishTransactionEvent(context.Background(), model.TransactionEvent{Id: uuid.New().String(), Type: model.TransactionCreated})
+	assert.Error(t, err)
+}
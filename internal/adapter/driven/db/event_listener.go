@@ -0,0 +1,186 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/event"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	portRepository "github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+const catchUpBatchSize = 500
+
+// PostgresEventListener receives committed transaction events from every
+// replica through Postgres LISTEN/NOTIFY and fans them out to local subscribers.
+//
+// Sequences are assigned on insert but become visible on commit, so across
+// users events can commit out of sequence order. Only the events of a single
+// user are serialized, which is why the last published sequence is tracked
+// per user.
+type PostgresEventListener struct {
+	db         *sql.DB
+	listener   *pq.Listener
+	repository portRepository.TransactionEventRepository
+	hub        *event.Hub
+
+	mu sync.Mutex
+	// sequences holds the last published sequence of every subscribed user
+	sequences map[string]int64
+}
+
+func NewPostgresEventListener(dsn string, db *sql.DB) *PostgresEventListener {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Error("transaction event listener failed", "error", err)
+		}
+	})
+	return &PostgresEventListener{
+		db:         db,
+		listener:   listener,
+		repository: repository.NewTransactionEventRepository(db),
+		hub:        event.NewHub(),
+		sequences:  make(map[string]int64),
+	}
+}
+
+// Subscribe registers with the hub before reading the latest sequence, so an
+// event committed in between is published to the subscriber rather than lost.
+func (l *PostgresEventListener) Subscribe(ctx context.Context, userId string) (<-chan model.TransactionEvent, error) {
+	events, err := l.hub.Subscribe(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	sequence, err := l.repository.GetLatestSequence(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.sequences[userId]; !ok {
+		l.sequences[userId] = sequence
+	}
+	return events, nil
+}
+
+// Close ends all subscriptions, which would otherwise keep their streams
+// open during a graceful shutdown.
+func (l *PostgresEventListener) Close() {
+	l.hub.Close()
+}
+
+// Done is closed once Close ended all subscriptions.
+func (l *PostgresEventListener) Done() <-chan struct{} {
+	return l.hub.Done()
+}
+
+// Run listens for events until ctx is done.
+func (l *PostgresEventListener) Run(ctx context.Context) error {
+	defer l.listener.Close()
+
+	if err := l.listener.Listen(repository.TransactionEventsChannel); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-l.listener.Notify:
+			if notification == nil {
+				// The connection was re-established and notifications may have been lost
+				l.catchUp(ctx)
+				continue
+			}
+			l.handle(ctx, notification.Extra)
+		case <-ticker.C:
+			if err := l.listener.Ping(); err != nil {
+				slog.ErrorContext(ctx, "transaction event listener ping failed", "error", err)
+			}
+			l.forgetUnsubscribed()
+		}
+	}
+}
+
+func (l *PostgresEventListener) handle(ctx context.Context, payload string) {
+	sequence, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		slog.ErrorContext(ctx, "transaction event listener received an invalid payload", "payload", payload)
+		return
+	}
+
+	event, err := l.repository.GetEventBySequence(ctx, sequence)
+	if err != nil {
+		slog.ErrorContext(ctx, "transaction event listener failed to load event", "sequence", sequence, "error", err)
+		return
+	}
+	if event == nil {
+		return
+	}
+	l.publish(*event)
+}
+
+// catchUp publishes the events each subscribed user missed since its last
+// published sequence.
+func (l *PostgresEventListener) catchUp(ctx context.Context) {
+	l.mu.Lock()
+	sequences := make(map[string]int64, len(l.sequences))
+	for userId, sequence := range l.sequences {
+		sequences[userId] = sequence
+	}
+	l.mu.Unlock()
+
+	for userId, sequence := range sequences {
+		for {
+			events, err := l.repository.GetEventsAfter(ctx, userId, sequence, catchUpBatchSize)
+			if err != nil {
+				slog.ErrorContext(ctx, "transaction event listener failed to catch up", "user_id", userId, "error", err)
+				break
+			}
+			for _, event := range events {
+				l.publish(event)
+				sequence = event.Sequence
+			}
+			if len(events) < catchUpBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// publish hands an event to the subscribers of its user unless it was
+// published already, e.g. by a catch up racing its notification.
+func (l *PostgresEventListener) publish(event model.TransactionEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if sequence, ok := l.sequences[event.UserId]; ok {
+		if event.Sequence <= sequence {
+			return
+		}
+		l.sequences[event.UserId] = event.Sequence
+	}
+	l.hub.Publish(event)
+}
+
+// forgetUnsubscribed drops the sequences of users without subscribers.
+func (l *PostgresEventListener) forgetUnsubscribed() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for userId := range l.sequences {
+		if !l.hub.HasSubscribers(userId) {
+			delete(l.sequences, userId)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/event"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventListenerCatchesUpEventsCommittedOutOfOrder(t *testing.T) {
+	repo := repository.NewInMemoryTransactionEventRepository(nil)
+	listener := &PostgresEventListener{repository: repo, hub: event.NewHub(), sequences: make(map[string]int64)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	alice, bob := uuid.New().String(), uuid.New().String()
+	events, err := listener.Subscribe(ctx, alice)
+	require.NoError(t, err)
+
+	// The event of alice gets the lower sequence but commits after the one of
+	// bob, and its notification is lost while reconnecting
+	late, err := repo.AppendEvent(ctx, model.TransactionEvent{Type: model.TransactionCreated, UserId: alice})
+	require.NoError(t, err)
+	early, err := repo.AppendEvent(ctx, model.TransactionEvent{Type: model.TransactionCreated, UserId: bob})
+	require.NoError(t, err)
+	require.Less(t, late.Sequence, early.Sequence)
+	listener.publish(*early)
+
+	listener.catchUp(ctx)
+	// A notification arriving after the catch up is not published twice
+	listener.publish(*late)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, late.Id, (<-events).Id)
+}
+
+// committingEventRepository commits an event of the user while the latest
+// sequence of a subscription is read.
+type committingEventRepository struct {
+	*repository.InMemoryTransactionEventRepository
+	listener *PostgresEventListener
+}
+
+func (r *committingEventRepository) GetLatestSequence(ctx context.Context, userId string) (int64, error) {
+	committed, err := r.AppendEvent(ctx, model.TransactionEvent{Type: model.TransactionCreated, UserId: userId})
+	if err != nil {
+		return 0, err
+	}
+	r.listener.publish(*committed)
+	return r.InMemoryTransactionEventRepository.GetLatestSequence(ctx, userId)
+}
+
+func TestEventListenerDeliversEventsCommittedWhileSubscribing(t *testing.T) {
+	listener := &PostgresEventListener{hub: event.NewHub(), sequences: make(map[string]int64)}
+	listener.repository = &committingEventRepository{InMemoryTransactionEventRepository: repository.NewInMemoryTransactionEventRepository(nil), listener: listener}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := listener.Subscribe(ctx, uuid.New().String())
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	transactionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "finman",
+		Name:      "db_transaction_duration_seconds",
+		Help:      "Time from beginning a database transaction until it committed or rolled back, by outcome.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"outcome"})
+
+	transactionRollbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "finman",
+		Name:      "db_transaction_rollbacks_total",
+		Help:      "Database transactions rolled back.",
+	})
+)
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_user_id_date;
+DROP INDEX IF EXISTS idx_transactions_description_tsv;
+ALTER TABLE transactions DROP COLUMN IF EXISTS description_tsv;
\ No newline at end of file
@@ -0,0 +1,6 @@
+ALTER TABLE transactions
+    ADD COLUMN description_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(description, ''))) STORED;
+
+CREATE INDEX idx_transactions_description_tsv ON transactions USING GIN (description_tsv);
+CREATE INDEX idx_transactions_user_id_date ON transactions (user_id, date DESC);
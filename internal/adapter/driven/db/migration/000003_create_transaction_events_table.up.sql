@@ -0,0 +1,11 @@
+CREATE TABLE transaction_events (
+    sequence BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE DEFAULT uuid_generate_v4(),
+    type TEXT NOT NULL CHECK (type IN ('created', 'updated', 'deleted')),
+    user_id UUID NOT NULL,
+    transaction_id UUID NOT NULL,
+    transaction JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transaction_events_user_id_sequence ON transaction_events (user_id, sequence);
@@ -0,0 +1,11 @@
+CREATE TABLE notification_preferences (
+    user_id UUID PRIMARY KEY,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    channels TEXT[] NOT NULL DEFAULT '{}',
+    quiet_hours_start INT NOT NULL DEFAULT 0 CHECK (quiet_hours_start BETWEEN 0 AND 1439),
+    quiet_hours_end INT NOT NULL DEFAULT 0 CHECK (quiet_hours_end BETWEEN 0 AND 1439),
+    time_zone TEXT NOT NULL DEFAULT '',
+    min_amount BIGINT NOT NULL DEFAULT 0,
+    locale TEXT NOT NULL DEFAULT 'en',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+DROP INDEX idx_outbox_ordering_key;
+
+ALTER TABLE outbox DROP COLUMN ordering_key;
@@ -0,0 +1,3 @@
+ALTER TABLE outbox ADD COLUMN ordering_key TEXT;
+
+CREATE INDEX idx_outbox_ordering_key ON outbox (ordering_key, id) WHERE status = 'pending' AND ordering_key IS NOT NULL;
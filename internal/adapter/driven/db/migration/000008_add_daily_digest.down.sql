@@ -0,0 +1,5 @@
+DROP TABLE digest_runs;
+
+DROP INDEX idx_notification_preferences_daily_digest;
+
+ALTER TABLE notification_preferences DROP COLUMN daily_digest;
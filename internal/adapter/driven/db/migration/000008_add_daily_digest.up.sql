@@ -0,0 +1,10 @@
+ALTER TABLE notification_preferences ADD COLUMN daily_digest BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_notification_preferences_daily_digest ON notification_preferences (user_id) WHERE daily_digest;
+
+CREATE TABLE digest_runs (
+    user_id UUID NOT NULL,
+    day DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, day)
+);
@@ -0,0 +1,8 @@
+CREATE TABLE alert_settings (
+    user_id UUID PRIMARY KEY,
+    low_balance_threshold BIGINT NOT NULL DEFAULT 0 CHECK (low_balance_threshold >= 0),
+    large_transaction_amount BIGINT NOT NULL DEFAULT 0 CHECK (large_transaction_amount >= 0),
+    low_balance BOOLEAN NOT NULL DEFAULT FALSE,
+    low_balance_alerted_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
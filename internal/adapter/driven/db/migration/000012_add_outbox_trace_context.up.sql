@@ -0,0 +1 @@
+ALTER TABLE outbox ADD COLUMN trace_context JSONB;
@@ -0,0 +1,6 @@
+CREATE TABLE outbox_channel_deliveries (
+    outbox_id BIGINT NOT NULL REFERENCES outbox (id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (outbox_id, channel)
+);
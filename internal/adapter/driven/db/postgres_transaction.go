@@ -4,14 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"log"
+	"log/slog"
+	"time"
 
 	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type PostgresDbTransaction struct {
 	db        *sql.DB
 	tx        *sql.Tx
+	begunAt   time.Time
 	committed bool
 }
 
@@ -19,12 +25,25 @@ func NewPostgresDbTransaction(db *sql.DB) *PostgresDbTransaction {
 	return &PostgresDbTransaction{db: db}
 }
 
+const instrumentationName = "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+
+// startSpan starts the span of a transaction statement such as "BEGIN".
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, "DbTransaction."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", operation),
+	))
+}
+
 func (p *PostgresDbTransaction) Begin(ctx context.Context) (db.DbHandler, error) {
+	ctx, span := startSpan(ctx, "Begin")
 	tx, err := p.db.BeginTx(ctx, nil)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
 	p.tx = tx
+	p.begunAt = time.Now()
 	return p, nil
 }
 
@@ -32,9 +51,12 @@ func (p *PostgresDbTransaction) Commit(ctx context.Context) error {
 	if p.tx == nil {
 		return errors.New("no transaction in progress")
 	}
+	_, span := startSpan(ctx, "Commit")
 	err := p.tx.Commit()
+	tracing.EndSpan(span, err)
 	if err == nil {
 		p.committed = true
+		transactionDuration.WithLabelValues("commit").Observe(time.Since(p.begunAt).Seconds())
 	}
 	return err
 }
@@ -43,14 +65,21 @@ func (p *PostgresDbTransaction) Rollback(ctx context.Context) error {
 	if p.tx == nil {
 		return errors.New("no transaction in progress")
 	}
-	return p.tx.Rollback()
+	_, span := startSpan(ctx, "Rollback")
+	err := p.tx.Rollback()
+	tracing.EndSpan(span, err)
+	if err == nil {
+		transactionRollbacks.Inc()
+		transactionDuration.WithLabelValues("rollback").Observe(time.Since(p.begunAt).Seconds())
+	}
+	return err
 }
 
 func (p *PostgresDbTransaction) RollbackUnlessCommitted(ctx context.Context) {
 	if !p.committed {
 		err := p.Rollback(ctx)
 		if err != nil {
-			log.Print(err)
+			slog.ErrorContext(ctx, "failed to roll back transaction", "error", err)
 		}
 	}
 }
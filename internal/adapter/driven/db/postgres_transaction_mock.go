@@ -2,7 +2,7 @@ package db
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
 	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
 )
@@ -11,23 +11,23 @@ import (
 type PostgresTransactionMock struct{}
 
 func (m PostgresTransactionMock) Begin(ctx context.Context) (db.DbHandler, error) {
-	log.Println("Begin transaction")
+	slog.Debug("Begin transaction")
 	// Simulate returning a handler (could be a mock handler)
 	return &MockDbHandler{}, nil
 }
 
 func (m PostgresTransactionMock) Commit(ctx context.Context) error {
-	log.Println("Commit transaction")
+	slog.Debug("Commit transaction")
 	return nil
 }
 
 func (m PostgresTransactionMock) Rollback(ctx context.Context) error {
-	log.Println("Rollback transaction")
+	slog.Debug("Rollback transaction")
 	return nil
 }
 
 func (m PostgresTransactionMock) RollbackUnlessCommitted(ctx context.Context) {
-	log.Println("Rollback unless committed transaction")
+	slog.Debug("Rollback unless committed transaction")
 }
 
 // PostgresTransactionMockFactory is a mock implementation of the DbTransactionFactory interface
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type AlertSettingsRepositoryFactory struct{}
+
+func NewAlertSettingsRepositoryFactory() *AlertSettingsRepositoryFactory {
+	return &AlertSettingsRepositoryFactory{}
+}
+
+func (f *AlertSettingsRepositoryFactory) New(handler db.DbHandler) repository.AlertSettingsRepository {
+	return NewAlertSettingsRepository(handler)
+}
+
+type AlertSettingsRepository struct {
+	handler db.DbHandler
+}
+
+func NewAlertSettingsRepository(handler db.DbHandler) *AlertSettingsRepository {
+	return &AlertSettingsRepository{handler: handler}
+}
+
+func (r *AlertSettingsRepository) GetAlertSettings(ctx context.Context, userId string) (*model.AlertSettings, error) {
+	return r.get(ctx, userId, "")
+}
+
+func (r *AlertSettingsRepository) LockAlertSettings(ctx context.Context, userId string) (*model.AlertSettings, error) {
+	return r.get(ctx, userId, " FOR UPDATE")
+}
+
+func (r *AlertSettingsRepository) get(ctx context.Context, userId, lock string) (*model.AlertSettings, error) {
+	query := `SELECT user_id, low_balance_threshold, large_transaction_amount, low_balance, low_balance_alerted_at, updated_at 
+	          FROM alert_settings 
+	          WHERE user_id = $1` + lock
+	var settings model.AlertSettings
+	var alertedAt sql.NullTime
+	err := r.handler.QueryRowContext(ctx, query, userId).Scan(&settings.UserId, &settings.LowBalanceThreshold, &settings.LargeTransactionAmount,
+		&settings.State.LowBalance, &alertedAt, &settings.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if alertedAt.Valid {
+		settings.State.LowBalanceAlertedAt = &alertedAt.Time
+	}
+	return &settings, nil
+}
+
+func (r *AlertSettingsRepository) SaveAlertSettings(ctx context.Context, settings model.AlertSettings) error {
+	query := `INSERT INTO alert_settings (user_id, low_balance_threshold, large_transaction_amount, updated_at) 
+	          VALUES ($1, $2, $3, $4) 
+	          ON CONFLICT (user_id) DO UPDATE 
+	          SET low_balance_threshold = EXCLUDED.low_balance_threshold, large_transaction_amount = EXCLUDED.large_transaction_amount, 
+	              updated_at = EXCLUDED.updated_at`
+	_, err := r.handler.ExecContext(ctx, query, settings.UserId, settings.LowBalanceThreshold, settings.LargeTransactionAmount, time.Now())
+	return err
+}
+
+func (r *AlertSettingsRepository) SaveAlertState(ctx context.Context, userId string, state model.AlertState) error {
+	query := `UPDATE alert_settings 
+	          SET low_balance = $1, low_balance_alerted_at = $2 
+	          WHERE user_id = $3`
+	_, err := r.handler.ExecContext(ctx, query, state.LowBalance, state.LowBalanceAlertedAt, userId)
+	return err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type InMemoryAlertSettingsRepositoryFactory struct {
+	repo *InMemoryAlertSettingsRepository
+}
+
+func NewInMemoryAlertSettingsRepositoryFactory(repo *InMemoryAlertSettingsRepository) *InMemoryAlertSettingsRepositoryFactory {
+	return &InMemoryAlertSettingsRepositoryFactory{repo: repo}
+}
+
+func (f *InMemoryAlertSettingsRepositoryFactory) New(handler db.DbHandler) repository.AlertSettingsRepository {
+	return f.repo
+}
+
+// InMemoryAlertSettingsRepository implements AlertSettingsRepository using
+// in-memory storage. Locking is a no-op.
+type InMemoryAlertSettingsRepository struct {
+	settings map[string]model.AlertSettings
+	mu       sync.RWMutex
+}
+
+func NewInMemoryAlertSettingsRepository() *InMemoryAlertSettingsRepository {
+	return &InMemoryAlertSettingsRepository{settings: make(map[string]model.AlertSettings)}
+}
+
+func (r *InMemoryAlertSettingsRepository) GetAlertSettings(ctx context.Context, userId string) (*model.AlertSettings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	settings, ok := r.settings[userId]
+	if !ok {
+		return nil, nil
+	}
+	return &settings, nil
+}
+
+func (r *InMemoryAlertSettingsRepository) LockAlertSettings(ctx context.Context, userId string) (*model.AlertSettings, error) {
+	return r.GetAlertSettings(ctx, userId)
+}
+
+func (r *InMemoryAlertSettingsRepository) SaveAlertSettings(ctx context.Context, settings model.AlertSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settings.State = r.settings[settings.UserId].State
+	settings.UpdatedAt = time.Now()
+	r.settings[settings.UserId] = settings
+	return nil
+}
+
+func (r *InMemoryAlertSettingsRepository) SaveAlertState(ctx context.Context, userId string, state model.AlertState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settings, ok := r.settings[userId]
+	if !ok {
+		return nil
+	}
+	settings.State = state
+	r.settings[userId] = settings
+	return nil
+}
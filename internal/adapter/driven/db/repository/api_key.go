@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type ApiKeyRepositoryFactory struct{}
+
+func NewApiKeyRepositoryFactory() *ApiKeyRepositoryFactory {
+	return &ApiKeyRepositoryFactory{}
+}
+
+func (f *ApiKeyRepositoryFactory) New(handler db.DbHandler) repository.ApiKeyRepository {
+	return NewApiKeyRepository(handler)
+}
+
+type ApiKeyRepository struct {
+	handler db.DbHandler
+}
+
+func NewApiKeyRepository(handler db.DbHandler) *ApiKeyRepository {
+	return &ApiKeyRepository{handler: handler}
+}
+
+const apiKeyColumns = `id, service, secret_hash, scopes, created_at, revoked_at, last_used_at, request_count`
+
+func (r *ApiKeyRepository) CreateApiKey(ctx context.Context, key model.ApiKey) error {
+	query := `INSERT INTO api_keys (id, service, secret_hash, scopes) 
+	          VALUES ($1, $2, $3, $4)`
+	_, err := r.handler.ExecContext(ctx, query, key.Id, key.Service, key.SecretHash, pq.Array(scopeStrings(key.Scopes)))
+	return err
+}
+
+func (r *ApiKeyRepository) GetApiKeyById(ctx context.Context, id string) (*model.ApiKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` 
+	          FROM api_keys 
+	          WHERE id = $1`
+	key, err := scanApiKey(r.handler.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *ApiKeyRepository) ListApiKeys(ctx context.Context, service string) ([]model.ApiKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` 
+	          FROM api_keys 
+	          WHERE $1 = '' OR service = $1 
+	          ORDER BY created_at, id`
+	rows, err := r.handler.QueryContext(ctx, query, service)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []model.ApiKey
+	for rows.Next() {
+		key, err := scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *ApiKeyRepository) RevokeApiKey(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+	_, err := r.handler.ExecContext(ctx, query, at, id)
+	return err
+}
+
+func (r *ApiKeyRepository) RecordApiKeyUsage(ctx context.Context, id string, requests int64, lastUsedAt time.Time) error {
+	query := `UPDATE api_keys 
+	          SET request_count = request_count + $1, last_used_at = GREATEST(last_used_at, $2) 
+	          WHERE id = $3`
+	_, err := r.handler.ExecContext(ctx, query, requests, lastUsedAt, id)
+	return err
+}
+
+func scanApiKey(row scanner) (*model.ApiKey, error) {
+	var key model.ApiKey
+	var scopes []string
+	err := row.Scan(&key.Id, &key.Service, &key.SecretHash, pq.Array(&scopes), &key.CreatedAt, &key.RevokedAt, &key.LastUsedAt, &key.RequestCount)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, model.ApiKeyScope(scope))
+	}
+	return &key, nil
+}
+
+func scopeStrings(scopes []model.ApiKeyScope) []string {
+	result := make([]string, len(scopes))
+	for i, scope := range scopes {
+		result[i] = string(scope)
+	}
+	return result
+}
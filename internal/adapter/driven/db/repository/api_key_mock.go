@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type InMemoryApiKeyRepositoryFactory struct {
+	repo *InMemoryApiKeyRepository
+}
+
+func NewInMemoryApiKeyRepositoryFactory(repo *InMemoryApiKeyRepository) *InMemoryApiKeyRepositoryFactory {
+	return &InMemoryApiKeyRepositoryFactory{repo: repo}
+}
+
+func (f *InMemoryApiKeyRepositoryFactory) New(handler db.DbHandler) repository.ApiKeyRepository {
+	return f.repo
+}
+
+// InMemoryApiKeyRepository implements ApiKeyRepository using in-memory storage.
+type InMemoryApiKeyRepository struct {
+	keys []model.ApiKey
+	mu   sync.RWMutex
+}
+
+func NewInMemoryApiKeyRepository() *InMemoryApiKeyRepository {
+	return &InMemoryApiKeyRepository{}
+}
+
+func (r *InMemoryApiKeyRepository) CreateApiKey(ctx context.Context, key model.ApiKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key.CreatedAt = time.Now()
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *InMemoryApiKeyRepository) GetApiKeyById(ctx context.Context, id string) (*model.ApiKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Id == id {
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *InMemoryApiKeyRepository) ListApiKeys(ctx context.Context, service string) ([]model.ApiKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var keys []model.ApiKey
+	for _, key := range r.keys {
+		if service == "" || key.Service == service {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *InMemoryApiKeyRepository) RevokeApiKey(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, key := range r.keys {
+		if key.Id == id && key.RevokedAt == nil {
+			r.keys[i].RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryApiKeyRepository) RecordApiKeyUsage(ctx context.Context, id string, requests int64, lastUsedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, key := range r.keys {
+		if key.Id == id {
+			r.keys[i].RequestCount += requests
+			if key.LastUsedAt == nil || lastUsedAt.After(*key.LastUsedAt) {
+				r.keys[i].LastUsedAt = &lastUsedAt
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type DigestRunRepositoryFactory struct{}
+
+func NewDigestRunRepositoryFactory() *DigestRunRepositoryFactory {
+	return &DigestRunRepositoryFactory{}
+}
+
+func (f *DigestRunRepositoryFactory) New(handler db.DbHandler) repository.DigestRunRepository {
+	return NewDigestRunRepository(handler)
+}
+
+type DigestRunRepository struct {
+	handler db.DbHandler
+}
+
+func NewDigestRunRepository(handler db.DbHandler) *DigestRunRepository {
+	return &DigestRunRepository{handler: handler}
+}
+
+func (r *DigestRunRepository) RecordDigestRun(ctx context.Context, userId, day string) (bool, error) {
+	query := `INSERT INTO digest_runs (user_id, day) 
+	          VALUES ($1, $2) 
+	          ON CONFLICT (user_id, day) DO NOTHING`
+	result, err := r.handler.ExecContext(ctx, query, userId, day)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
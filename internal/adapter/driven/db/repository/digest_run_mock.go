@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type InMemoryDigestRunRepositoryFactory struct {
+	repo *InMemoryDigestRunRepository
+}
+
+func NewInMemoryDigestRunRepositoryFactory(repo *InMemoryDigestRunRepository) *InMemoryDigestRunRepositoryFactory {
+	return &InMemoryDigestRunRepositoryFactory{repo: repo}
+}
+
+func (f *InMemoryDigestRunRepositoryFactory) New(handler db.DbHandler) repository.DigestRunRepository {
+	return f.repo
+}
+
+// InMemoryDigestRunRepository implements DigestRunRepository using in-memory storage.
+type InMemoryDigestRunRepository struct {
+	runs map[string]bool
+	mu   sync.Mutex
+}
+
+func NewInMemoryDigestRunRepository() *InMemoryDigestRunRepository {
+	return &InMemoryDigestRunRepository{runs: make(map[string]bool)}
+}
+
+func (r *InMemoryDigestRunRepository) RecordDigestRun(ctx context.Context, userId, day string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := userId + "/" + day
+	if r.runs[key] {
+		return false, nil
+	}
+	r.runs[key] = true
+	return true, nil
+}
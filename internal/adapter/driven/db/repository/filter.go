@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+// sortColumns whitelists the columns transactions can be ordered by, sort
+// fields are never interpolated into SQL directly.
+var sortColumns = map[model.SortField]string{
+	model.SortByDate:      "date",
+	model.SortByAmount:    "amount",
+	model.SortByType:      "type",
+	model.SortByCreatedAt: "created_at",
+	model.SortByUpdatedAt: "updated_at",
+}
+
+// queryBuilder collects WHERE conditions together with their positional arguments.
+type queryBuilder struct {
+	args       []interface{}
+	conditions []string
+}
+
+// arg registers a query argument and returns its placeholder.
+func (b *queryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// where adds a condition, every %s in format is replaced by the placeholder of the matching value.
+func (b *queryBuilder) where(format string, values ...interface{}) {
+	placeholders := make([]interface{}, len(values))
+	for i, value := range values {
+		placeholders[i] = b.arg(value)
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf(format, placeholders...))
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+func (b *queryBuilder) filter(filter model.TransactionFilter) {
+	if len(filter.UserIds) > 0 {
+		b.where("user_id = ANY(%s::uuid[])", pq.Array(filter.UserIds))
+	}
+	if len(filter.Types) > 0 {
+		b.where("type = ANY(%s)", pq.Array(filter.Types))
+	}
+	if filter.FromDate != nil {
+		b.where("date >= %s", *filter.FromDate)
+	}
+	if filter.ToDate != nil {
+		b.where("date <= %s", *filter.ToDate)
+	}
+	if filter.MinAmount != nil {
+		b.where("amount >= %s", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		b.where("amount <= %s", *filter.MaxAmount)
+	}
+	if filter.DescriptionContains != "" {
+		b.where("description ILIKE %s", "%"+escapeLike(filter.DescriptionContains)+"%")
+	}
+	if filter.CreatedFrom != nil {
+		b.where("created_at >= %s", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		b.where("created_at <= %s", *filter.CreatedTo)
+	}
+	if filter.UpdatedFrom != nil {
+		b.where("updated_at >= %s", *filter.UpdatedFrom)
+	}
+	if filter.UpdatedTo != nil {
+		b.where("updated_at <= %s", *filter.UpdatedTo)
+	}
+}
+
+// after restricts the query to rows that come after the cursor in the sort
+// order, using a row comparison on the sort column and id.
+func (b *queryBuilder) after(sort model.TransactionSort, cursor *model.TransactionCursor) {
+	if cursor == nil {
+		return
+	}
+	operator := ">"
+	if sort.Descending {
+		operator = "<"
+	}
+	b.where(fmt.Sprintf("(%s, id) %s (%%s, %%s::uuid)", sortColumn(sort.Field), operator), cursorValue(sort.Field, cursor), cursor.Id)
+}
+
+func cursorValue(field model.SortField, cursor *model.TransactionCursor) interface{} {
+	switch field {
+	case model.SortByAmount:
+		return cursor.Amount
+	case model.SortByType:
+		return cursor.Type
+	case model.SortByCreatedAt:
+		return cursor.CreatedAt
+	case model.SortByUpdatedAt:
+		return cursor.UpdatedAt
+	default:
+		return cursor.Date
+	}
+}
+
+func sortColumn(field model.SortField) string {
+	column, ok := sortColumns[field]
+	if !ok {
+		return sortColumns[model.DefaultTransactionSort.Field]
+	}
+	return column
+}
+
+// orderBy renders an ORDER BY clause for a whitelisted sort field with id as tie breaker.
+func orderBy(sort model.TransactionSort) string {
+	column := sortColumn(sort.Field)
+	direction := "ASC"
+	if sort.Descending {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("ORDER BY %s %s, id %s", column, direction, direction)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes LIKE wildcards so user input is matched literally.
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
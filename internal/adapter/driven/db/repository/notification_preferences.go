@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type NotificationPreferencesRepositoryFactory struct{}
+
+func NewNotificationPreferencesRepositoryFactory() *NotificationPreferencesRepositoryFactory {
+	return &NotificationPreferencesRepositoryFactory{}
+}
+
+func (f *NotificationPreferencesRepositoryFactory) New(handler db.DbHandler) repository.NotificationPreferencesRepository {
+	return NewNotificationPreferencesRepository(handler)
+}
+
+type NotificationPreferencesRepository struct {
+	handler db.DbHandler
+}
+
+func NewNotificationPreferencesRepository(handler db.DbHandler) *NotificationPreferencesRepository {
+	return &NotificationPreferencesRepository{handler: handler}
+}
+
+func (r *NotificationPreferencesRepository) GetPreferences(ctx context.Context, userId string) (*model.NotificationPreferences, error) {
+	query := `SELECT ` + preferencesColumns + ` 
+	          FROM notification_preferences 
+	          WHERE user_id = $1`
+	preferences, err := scanPreferences(r.handler.QueryRowContext(ctx, query, userId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (r *NotificationPreferencesRepository) ListDigestSubscribers(ctx context.Context, afterUserId string, limit int) ([]model.NotificationPreferences, error) {
+	query := `SELECT ` + preferencesColumns + ` 
+	          FROM notification_preferences 
+	          WHERE daily_digest AND ($1 = '' OR user_id > $1::uuid) 
+	          ORDER BY user_id 
+	          LIMIT $2`
+	rows, err := r.handler.QueryContext(ctx, query, afterUserId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscribers []model.NotificationPreferences
+	for rows.Next() {
+		preferences, err := scanPreferences(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscribers = append(subscribers, *preferences)
+	}
+	return subscribers, rows.Err()
+}
+
+const preferencesColumns = `user_id, event_types, channels, quiet_hours_start, quiet_hours_end, time_zone, min_amount, locale, daily_digest, updated_at`
+
+func scanPreferences(row scanner) (*model.NotificationPreferences, error) {
+	var preferences model.NotificationPreferences
+	var eventTypes []string
+	err := row.Scan(&preferences.UserId, pq.Array(&eventTypes), pq.Array(&preferences.Channels), &preferences.QuietHoursStart, &preferences.QuietHoursEnd,
+		&preferences.TimeZone, &preferences.MinAmount, &preferences.Locale, &preferences.DailyDigest, &preferences.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, eventType := range eventTypes {
+		preferences.EventTypes = append(preferences.EventTypes, model.TransactionEventType(eventType))
+	}
+	return &preferences, nil
+}
+
+func (r *NotificationPreferencesRepository) SavePreferences(ctx context.Context, preferences model.NotificationPreferences) error {
+	query := `INSERT INTO notification_preferences (user_id, event_types, channels, quiet_hours_start, quiet_hours_end, time_zone, min_amount, locale, daily_digest, updated_at) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) 
+	          ON CONFLICT (user_id) DO UPDATE 
+	          SET event_types = EXCLUDED.event_types, channels = EXCLUDED.channels, quiet_hours_start = EXCLUDED.quiet_hours_start, 
+	              quiet_hours_end = EXCLUDED.quiet_hours_end, time_zone = EXCLUDED.time_zone, min_amount = EXCLUDED.min_amount, 
+	              locale = EXCLUDED.locale, daily_digest = EXCLUDED.daily_digest, updated_at = EXCLUDED.updated_at`
+	channels := preferences.Channels
+	if channels == nil {
+		channels = []string{}
+	}
+	_, err := r.handler.ExecContext(ctx, query, preferences.UserId, pq.Array(eventTypeStrings(preferences.EventTypes)), pq.Array(channels),
+		preferences.QuietHoursStart, preferences.QuietHoursEnd, preferences.TimeZone, preferences.MinAmount, preferences.Locale, preferences.DailyDigest, time.Now())
+	return err
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type InMemoryNotificationPreferencesRepositoryFactory struct {
+	repo *InMemoryNotificationPreferencesRepository
+}
+
+func NewInMemoryNotificationPreferencesRepositoryFactory(repo *InMemoryNotificationPreferencesRepository) *InMemoryNotificationPreferencesRepositoryFactory {
+	return &InMemoryNotificationPreferencesRepositoryFactory{repo: repo}
+}
+
+func (f *InMemoryNotificationPreferencesRepositoryFactory) New(handler db.DbHandler) repository.NotificationPreferencesRepository {
+	return f.repo
+}
+
+// InMemoryNotificationPreferencesRepository implements NotificationPreferencesRepository using in-memory storage.
+type InMemoryNotificationPreferencesRepository struct {
+	preferences map[string]model.NotificationPreferences
+	mu          sync.RWMutex
+}
+
+func NewInMemoryNotificationPreferencesRepository() *InMemoryNotificationPreferencesRepository {
+	return &InMemoryNotificationPreferencesRepository{preferences: make(map[string]model.NotificationPreferences)}
+}
+
+func (r *InMemoryNotificationPreferencesRepository) GetPreferences(ctx context.Context, userId string) (*model.NotificationPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	preferences, ok := r.preferences[userId]
+	if !ok {
+		return nil, nil
+	}
+	return &preferences, nil
+}
+
+func (r *InMemoryNotificationPreferencesRepository) SavePreferences(ctx context.Context, preferences model.NotificationPreferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	preferences.UpdatedAt = time.Now()
+	r.preferences[preferences.UserId] = preferences
+	return nil
+}
+
+func (r *InMemoryNotificationPreferencesRepository) ListDigestSubscribers(ctx context.Context, afterUserId string, limit int) ([]model.NotificationPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subscribers []model.NotificationPreferences
+	for userId, preferences := range r.preferences {
+		if preferences.DailyDigest && userId > afterUserId {
+			subscribers = append(subscribers, preferences)
+		}
+	}
+	sort.Slice(subscribers, func(i, j int) bool { return subscribers[i].UserId < subscribers[j].UserId })
+	if len(subscribers) > limit {
+		subscribers = subscribers[:limit]
+	}
+	return subscribers, nil
+}
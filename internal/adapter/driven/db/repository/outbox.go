@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type OutboxRepositoryFactory struct{}
+
+func NewOutboxRepositoryFactory() *OutboxRepositoryFactory {
+	return &OutboxRepositoryFactory{}
+}
+
+func (f *OutboxRepositoryFactory) New(handler db.DbHandler) repository.OutboxRepository {
+	return NewOutboxRepository(handler)
+}
+
+type OutboxRepository struct {
+	handler db.DbHandler
+}
+
+func NewOutboxRepository(handler db.DbHandler) *OutboxRepository {
+	return &OutboxRepository{handler: handler}
+}
+
+func (r *OutboxRepository) Enqueue(ctx context.Context, message model.OutboxMessage) (int64, error) {
+	var traceContext []byte
+	if len(message.TraceContext) > 0 {
+		var err error
+		traceContext, err = json.Marshal(message.TraceContext)
+		if err != nil {
+			return 0, err
+		}
+	}
+	query := `INSERT INTO outbox (kind, user_id, ordering_key, trace_context, payload) 
+	          VALUES ($1, $2, NULLIF($3, ''), $4, $5) 
+	          RETURNING id`
+	var id int64
+	err := r.handler.QueryRowContext(ctx, query, message.Kind, message.UserId, message.OrderingKey, traceContext, message.Payload).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *OutboxRepository) LeasePending(ctx context.Context, now, lockedUntil time.Time, limit int) ([]model.OutboxMessage, error) {
+	query := `UPDATE outbox 
+	          SET locked_until = $2, attempts = attempts + 1 
+	          WHERE id IN (
+	              SELECT id FROM outbox 
+	              WHERE status = 'pending' AND next_attempt_at <= $1 AND (locked_until IS NULL OR locked_until <= $1) 
+	                AND (ordering_key IS NULL OR NOT EXISTS (
+	                    SELECT 1 FROM outbox earlier 
+	                    WHERE earlier.ordering_key = outbox.ordering_key AND earlier.status IN ('pending', 'dead') AND earlier.id < outbox.id)) 
+	              ORDER BY id 
+	              LIMIT $3 
+	              FOR UPDATE SKIP LOCKED) 
+	          RETURNING id, kind, user_id, COALESCE(ordering_key, ''), trace_context, payload, status, attempts, next_attempt_at, locked_until, COALESCE(last_error, ''), created_at, delivered_at`
+	rows, err := r.handler.QueryContext(ctx, query, now, lockedUntil, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []model.OutboxMessage
+	for rows.Next() {
+		var message model.OutboxMessage
+		var traceContext []byte
+		var lockedUntil, deliveredAt sql.NullTime
+		if err := rows.Scan(&message.Id, &message.Kind, &message.UserId, &message.OrderingKey, &traceContext, &message.Payload, &message.Status, &message.Attempts, &message.NextAttemptAt, &lockedUntil, &message.LastError, &message.CreatedAt, &deliveredAt); err != nil {
+			return nil, err
+		}
+		if lockedUntil.Valid {
+			message.LockedUntil = &lockedUntil.Time
+		}
+		if deliveredAt.Valid {
+			message.DeliveredAt = &deliveredAt.Time
+		}
+		if traceContext != nil {
+			if err := json.Unmarshal(traceContext, &message.TraceContext); err != nil {
+				return nil, err
+			}
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// RETURNING does not keep the order of the subquery
+	slices.SortFunc(messages, func(a, b model.OutboxMessage) int { return cmp.Compare(a.Id, b.Id) })
+	return messages, nil
+}
+
+// MarkDelivered, like MarkFailed and MarkDead, matches the attempts counted by
+// LeasePending, so a relay whose lease expired cannot overwrite the outcome of
+// the relay that leased the message next.
+func (r *OutboxRepository) MarkDelivered(ctx context.Context, message model.OutboxMessage, deliveredAt time.Time) error {
+	query := `UPDATE outbox 
+	          SET status = 'delivered', delivered_at = $1, last_error = NULL, locked_until = NULL 
+	          WHERE id = $2 AND attempts = $3 AND status = 'pending'`
+	_, err := r.handler.ExecContext(ctx, query, deliveredAt, message.Id, message.Attempts)
+	return err
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, message model.OutboxMessage, attempts int, nextAttemptAt time.Time, lastError string) error {
+	query := `UPDATE outbox 
+	          SET attempts = $1, next_attempt_at = $2, last_error = $3, locked_until = NULL 
+	          WHERE id = $4 AND attempts = $5 AND status = 'pending'`
+	_, err := r.handler.ExecContext(ctx, query, attempts, nextAttemptAt, lastError, message.Id, message.Attempts)
+	return err
+}
+
+func (r *OutboxRepository) MarkDead(ctx context.Context, message model.OutboxMessage, lastError string) error {
+	query := `UPDATE outbox 
+	          SET status = 'dead', last_error = $1, locked_until = NULL 
+	          WHERE id = $2 AND attempts = $3 AND status = 'pending'`
+	_, err := r.handler.ExecContext(ctx, query, lastError, message.Id, message.Attempts)
+	return err
+}
+
+func (r *OutboxRepository) RequeueDead(ctx context.Context, id int64, now time.Time) (bool, error) {
+	query := `UPDATE outbox 
+	          SET status = 'pending', attempts = 0, next_attempt_at = $1, locked_until = NULL 
+	          WHERE id = $2 AND status = 'dead'`
+	result, err := r.handler.ExecContext(ctx, query, now, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *OutboxRepository) CountBlockedOrderingKeys(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(DISTINCT ordering_key) FROM outbox WHERE status = 'dead' AND ordering_key IS NOT NULL`
+	var count int
+	if err := r.handler.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *OutboxRepository) GetDeliveredChannels(ctx context.Context, messageId int64) ([]string, error) {
+	query := `SELECT channel FROM outbox_channel_deliveries WHERE outbox_id = $1 ORDER BY channel`
+	rows, err := r.handler.QueryContext(ctx, query, messageId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []string
+	for rows.Next() {
+		var channel string
+		if err := rows.Scan(&channel); err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	return channels, rows.Err()
+}
+
+func (r *OutboxRepository) MarkChannelDelivered(ctx context.Context, messageId int64, channel string, deliveredAt time.Time) error {
+	query := `INSERT INTO outbox_channel_deliveries (outbox_id, channel, delivered_at) 
+	          VALUES ($1, $2, $3) 
+	          ON CONFLICT (outbox_id, channel) DO NOTHING`
+	_, err := r.handler.ExecContext(ctx, query, messageId, channel, deliveredAt)
+	return err
+}
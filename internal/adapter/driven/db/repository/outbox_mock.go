@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type InMemoryOutboxRepositoryFactory struct {
+	repo *InMemoryOutboxRepository
+}
+
+func NewInMemoryOutboxRepositoryFactory(repo *InMemoryOutboxRepository) *InMemoryOutboxRepositoryFactory {
+	return &InMemoryOutboxRepositoryFactory{repo: repo}
+}
+
+func (f *InMemoryOutboxRepositoryFactory) New(handler db.DbHandler) repository.OutboxRepository {
+	return f.repo
+}
+
+// InMemoryOutboxRepository implements OutboxRepository using in-memory storage.
+type InMemoryOutboxRepository struct {
+	messages []model.OutboxMessage
+	channels map[int64][]string
+	mu       sync.RWMutex
+}
+
+func NewInMemoryOutboxRepository() *InMemoryOutboxRepository {
+	return &InMemoryOutboxRepository{channels: make(map[int64][]string)}
+}
+
+func (r *InMemoryOutboxRepository) Enqueue(ctx context.Context, message model.OutboxMessage) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message.Id = int64(len(r.messages) + 1)
+	message.Status = model.OutboxPending
+	message.CreatedAt = time.Now()
+	if message.NextAttemptAt.IsZero() {
+		message.NextAttemptAt = message.CreatedAt
+	}
+	r.messages = append(r.messages, message)
+	return message.Id, nil
+}
+
+func (r *InMemoryOutboxRepository) LeasePending(ctx context.Context, now, lockedUntil time.Time, limit int) ([]model.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var messages []model.OutboxMessage
+	held := make(map[string]bool)
+	for i := range r.messages {
+		message := &r.messages[i]
+		if message.Status == model.OutboxDead && message.OrderingKey != "" {
+			held[message.OrderingKey] = true
+		}
+		if message.Status != model.OutboxPending {
+			continue
+		}
+		if message.OrderingKey != "" {
+			if held[message.OrderingKey] {
+				continue
+			}
+			held[message.OrderingKey] = true
+		}
+		if !message.NextAttemptAt.After(now) && (message.LockedUntil == nil || !message.LockedUntil.After(now)) {
+			message.Attempts++
+			message.LockedUntil = &lockedUntil
+			messages = append(messages, *message)
+			if len(messages) == limit {
+				break
+			}
+		}
+	}
+	return messages, nil
+}
+
+func (r *InMemoryOutboxRepository) MarkDelivered(ctx context.Context, message model.OutboxMessage, deliveredAt time.Time) error {
+	return r.update(message, func(message *model.OutboxMessage) {
+		message.Status = model.OutboxDelivered
+		message.DeliveredAt = &deliveredAt
+		message.LastError = ""
+	})
+}
+
+func (r *InMemoryOutboxRepository) MarkFailed(ctx context.Context, message model.OutboxMessage, attempts int, nextAttemptAt time.Time, lastError string) error {
+	return r.update(message, func(message *model.OutboxMessage) {
+		message.Attempts = attempts
+		message.NextAttemptAt = nextAttemptAt
+		message.LastError = lastError
+	})
+}
+
+func (r *InMemoryOutboxRepository) MarkDead(ctx context.Context, message model.OutboxMessage, lastError string) error {
+	return r.update(message, func(message *model.OutboxMessage) {
+		message.Status = model.OutboxDead
+		message.LastError = lastError
+	})
+}
+
+func (r *InMemoryOutboxRepository) RequeueDead(ctx context.Context, id int64, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.messages {
+		message := &r.messages[i]
+		if message.Id == id && message.Status == model.OutboxDead {
+			message.Status = model.OutboxPending
+			message.Attempts = 0
+			message.NextAttemptAt = now
+			message.LockedUntil = nil
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *InMemoryOutboxRepository) CountBlockedOrderingKeys(ctx context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	blocked := make(map[string]bool)
+	for _, message := range r.messages {
+		if message.Status == model.OutboxDead && message.OrderingKey != "" {
+			blocked[message.OrderingKey] = true
+		}
+	}
+	return len(blocked), nil
+}
+
+func (r *InMemoryOutboxRepository) GetDeliveredChannels(ctx context.Context, messageId int64) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.channels[messageId]), nil
+}
+
+func (r *InMemoryOutboxRepository) MarkChannelDelivered(ctx context.Context, messageId int64, channel string, deliveredAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !slices.Contains(r.channels[messageId], channel) {
+		r.channels[messageId] = append(r.channels[messageId], channel)
+		slices.Sort(r.channels[messageId])
+	}
+	return nil
+}
+
+// Messages returns a copy of every stored message.
+func (r *InMemoryOutboxRepository) Messages() []model.OutboxMessage {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	messages := make([]model.OutboxMessage, len(r.messages))
+	copy(messages, r.messages)
+	return messages
+}
+
+// update changes a leased message and ends its lease, unless it was leased again.
+func (r *InMemoryOutboxRepository) update(leased model.OutboxMessage, change func(*model.OutboxMessage)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.messages {
+		message := &r.messages[i]
+		if message.Id == leased.Id {
+			if message.Attempts == leased.Attempts && message.Status == model.OutboxPending {
+				change(message)
+				message.LockedUntil = nil
+			}
+			return nil
+		}
+	}
+	return errors.New("outbox message not found")
+}
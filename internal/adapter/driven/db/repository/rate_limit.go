@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type RateLimitRepositoryFactory struct{}
+
+func NewRateLimitRepositoryFactory() *RateLimitRepositoryFactory {
+	return &RateLimitRepositoryFactory{}
+}
+
+func (f *RateLimitRepositoryFactory) New(handler db.DbHandler) repository.RateLimitRepository {
+	return NewRateLimitRepository(handler)
+}
+
+type RateLimitRepository struct {
+	handler db.DbHandler
+}
+
+func NewRateLimitRepository(handler db.DbHandler) *RateLimitRepository {
+	return &RateLimitRepository{handler: handler}
+}
+
+func (r *RateLimitRepository) LockBucket(ctx context.Context, initial model.TokenBucket, fullAt time.Time) (*model.TokenBucket, error) {
+	insert := `INSERT INTO rate_limit_buckets (key, tokens, updated_at, full_at) 
+	           VALUES ($1, $2, $3, $4) 
+	           ON CONFLICT (key) DO NOTHING`
+	if _, err := r.handler.ExecContext(ctx, insert, initial.Key, initial.Tokens, initial.UpdatedAt, fullAt); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT key, tokens, updated_at 
+	          FROM rate_limit_buckets 
+	          WHERE key = $1 
+	          FOR UPDATE`
+	var bucket model.TokenBucket
+	if err := r.handler.QueryRowContext(ctx, query, initial.Key).Scan(&bucket.Key, &bucket.Tokens, &bucket.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+func (r *RateLimitRepository) SaveBucket(ctx context.Context, bucket model.TokenBucket, fullAt time.Time) error {
+	query := `UPDATE rate_limit_buckets SET tokens = $1, updated_at = $2, full_at = $3 WHERE key = $4`
+	_, err := r.handler.ExecContext(ctx, query, bucket.Tokens, bucket.UpdatedAt, fullAt, bucket.Key)
+	return err
+}
+
+func (r *RateLimitRepository) DeleteFullBuckets(ctx context.Context, now time.Time) error {
+	query := `DELETE FROM rate_limit_buckets WHERE full_at < $1`
+	_, err := r.handler.ExecContext(ctx, query, now)
+	return err
+}
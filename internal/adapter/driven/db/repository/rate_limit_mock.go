@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type InMemoryRateLimitRepositoryFactory struct {
+	repo *InMemoryRateLimitRepository
+}
+
+func NewInMemoryRateLimitRepositoryFactory(repo *InMemoryRateLimitRepository) *InMemoryRateLimitRepositoryFactory {
+	return &InMemoryRateLimitRepositoryFactory{repo: repo}
+}
+
+func (f *InMemoryRateLimitRepositoryFactory) New(handler db.DbHandler) repository.RateLimitRepository {
+	return f.repo
+}
+
+type storedBucket struct {
+	bucket model.TokenBucket
+	fullAt time.Time
+}
+
+// InMemoryRateLimitRepository implements RateLimitRepository using in-memory
+// storage. Locking is a no-op.
+type InMemoryRateLimitRepository struct {
+	buckets map[string]storedBucket
+	mu      sync.RWMutex
+}
+
+func NewInMemoryRateLimitRepository() *InMemoryRateLimitRepository {
+	return &InMemoryRateLimitRepository{buckets: make(map[string]storedBucket)}
+}
+
+func (r *InMemoryRateLimitRepository) LockBucket(ctx context.Context, initial model.TokenBucket, fullAt time.Time) (*model.TokenBucket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.buckets[initial.Key]
+	if !ok {
+		stored = storedBucket{bucket: initial, fullAt: fullAt}
+		r.buckets[initial.Key] = stored
+	}
+	bucket := stored.bucket
+	return &bucket, nil
+}
+
+func (r *InMemoryRateLimitRepository) SaveBucket(ctx context.Context, bucket model.TokenBucket, fullAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buckets[bucket.Key] = storedBucket{bucket: bucket, fullAt: fullAt}
+	return nil
+}
+
+func (r *InMemoryRateLimitRepository) DeleteFullBuckets(ctx context.Context, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, stored := range r.buckets {
+		if stored.fullAt.Before(now) {
+			delete(r.buckets, key)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of stored buckets.
+func (r *InMemoryRateLimitRepository) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.buckets)
+}
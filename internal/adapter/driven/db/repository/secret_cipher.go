@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const encryptedSecretPrefix = "enc:v1:"
+
+// SecretCipher encrypts secrets with AES-GCM before they are stored, so a
+// database dump or backup does not reveal them.
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+// SecretKeySize is the size of the AES-256 key given to NewSecretCipher.
+const SecretKeySize = 32
+
+// NewSecretCipher uses key, SecretKeySize random bytes encoded in base64, as
+// the AES-256 key. Every replica must share it. Unlike a key derived from a
+// passphrase it cannot be guessed from a database dump.
+func NewSecretCipher(key string) (*SecretCipher, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != SecretKeySize {
+		return nil, fmt.Errorf("the secret encryption key must be %d random bytes encoded in base64", SecretKeySize)
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretCipher{aead: aead}, nil
+}
+
+func (c *SecretCipher) Encrypt(secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return encryptedSecretPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns secrets stored before they were encrypted as they are.
+func (c *SecretCipher) Decrypt(stored string) (string, error) {
+	encoded, ok := strings.CutPrefix(stored, encryptedSecretPrefix)
+	if !ok {
+		return stored, nil
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("the encrypted secret is too short")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	secret, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,37 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretCipher(t *testing.T) {
+	cipher, err := repository.NewSecretCipher("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+	require.NoError(t, err)
+
+	encrypted, err := cipher.Encrypt("whsec_1")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "whsec_1")
+	decrypted, err := cipher.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "whsec_1", decrypted)
+
+	// Secrets stored before encryption are returned as they are
+	decrypted, err = cipher.Decrypt("whsec_2")
+	require.NoError(t, err)
+	assert.Equal(t, "whsec_2", decrypted)
+
+	other, err := repository.NewSecretCipher("HxwdHhscGRoXGBkWFRYTFBESDxAODA0KCwgJBgcEBQI=")
+	require.NoError(t, err)
+	_, err = other.Decrypt(encrypted)
+	assert.Error(t, err)
+
+	// Passphrases and short keys are refused
+	for _, key := range []string{"", "change-me", "AAECAwQFBgcICQoLDA0ODw=="} {
+		_, err = repository.NewSecretCipher(key)
+		assert.Error(t, err, key)
+	}
+}
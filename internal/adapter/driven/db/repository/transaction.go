@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/nullexp/finman-transaction-service/internal/domain/model"
@@ -57,10 +59,26 @@ func (r *TransactionRepository) GetTransactionById(ctx context.Context, id strin
 	return &transaction, nil
 }
 
-func (r *TransactionRepository) GetAllTransactions(ctx context.Context) ([]model.Transaction, error) {
+func (r *TransactionRepository) UpdateTransaction(ctx context.Context, transaction model.Transaction) error {
+	query := `UPDATE transactions 
+	          SET user_id = $1, type = $2, amount = $3, description = $4, updated_at = $5 
+	          WHERE id = $6`
+	_, err := r.handler.ExecContext(ctx, query, transaction.UserId, transaction.Type, transaction.Amount, transaction.Description, time.Now(), transaction.Id)
+	return err
+}
+
+func (r *TransactionRepository) DeleteTransaction(ctx context.Context, id string) error {
+	query := `DELETE FROM transactions WHERE id = $1`
+	_, err := r.handler.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *TransactionRepository) GetTransactionsWithPagination(ctx context.Context, offset, limit int) ([]model.Transaction, error) {
 	query := `SELECT id, user_id, type, amount, date, description, created_at, updated_at 
-	          FROM transactions`
-	rows, err := r.handler.QueryContext(ctx, query)
+	          FROM transactions 
+	          ORDER BY date DESC 
+	          LIMIT $1 OFFSET $2`
+	rows, err := r.handler.QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -77,25 +95,72 @@ func (r *TransactionRepository) GetAllTransactions(ctx context.Context) ([]model
 	return transactions, rows.Err()
 }
 
-func (r *TransactionRepository) UpdateTransaction(ctx context.Context, transaction model.Transaction) error {
-	query := `UPDATE transactions 
-	          SET user_id = $1, type = $2, amount = $3, description = $4, updated_at = $5 
-	          WHERE id = $6`
-	_, err := r.handler.ExecContext(ctx, query, transaction.UserId, transaction.Type, transaction.Amount, transaction.Description, time.Now(), transaction.Id)
-	return err
+func (r *TransactionRepository) GetBalanceByUserId(ctx context.Context, userId string) (int64, error) {
+	var balance int64
+
+	query := `
+		SELECT 
+			COALESCE(SUM(CASE WHEN type = 'deposit' THEN amount ELSE 0 END) - SUM(CASE WHEN type = 'withdrawal' THEN amount ELSE 0 END), 0) 
+		FROM 
+			transactions 
+		WHERE 
+			user_id = $1`
+
+	err := r.handler.QueryRowContext(ctx, query, userId).Scan(&balance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return balance, nil
 }
 
-func (r *TransactionRepository) DeleteTransaction(ctx context.Context, id string) error {
-	query := `DELETE FROM transactions WHERE id = $1`
-	_, err := r.handler.ExecContext(ctx, query, id)
-	return err
+func (r *TransactionRepository) SearchTransactions(ctx context.Context, search model.TransactionSearch) ([]model.TransactionSearchResult, error) {
+	var builder queryBuilder
+	tsQuery := builder.arg(toTsQuery(search.Terms))
+	builder.where("user_id = %s", search.UserId)
+	builder.where("description_tsv @@ query")
+	builder.filter(search.Filter)
+	if search.After != nil {
+		builder.where("(ts_rank_cd(description_tsv, query), date, id) < (%s::real, %s, %s::uuid)", search.After.Rank, search.After.Date, search.After.Id)
+	}
+
+	query := fmt.Sprintf(`SELECT id, user_id, type, amount, date, description, created_at, updated_at, ts_rank_cd(description_tsv, query) AS rank 
+	          FROM transactions, to_tsquery('simple', %s) query 
+	          %s 
+	          ORDER BY rank DESC, date DESC, id DESC 
+	          LIMIT %s`, tsQuery, builder.whereClause(), builder.arg(search.Limit))
+	rows, err := r.handler.QueryContext(ctx, query, builder.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []model.TransactionSearchResult
+	for rows.Next() {
+		var result model.TransactionSearchResult
+		transaction := &result.Transaction
+		if err := rows.Scan(&transaction.Id, &transaction.UserId, &transaction.Type, &transaction.Amount, &transaction.Date, &transaction.Description, &transaction.CreatedAt, &transaction.UpdatedAt, &result.Rank); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
 }
 
-func (r *TransactionRepository) GetTransactionsByUserId(ctx context.Context, userId string) ([]model.Transaction, error) {
-	query := `SELECT id, user_id, type, amount, date, description, created_at, updated_at 
+func (r *TransactionRepository) ListTransactions(ctx context.Context, filter model.TransactionFilter, sort model.TransactionSort, after *model.TransactionCursor, limit int) ([]model.Transaction, error) {
+	var builder queryBuilder
+	builder.filter(filter)
+	builder.after(sort, after)
+
+	query := fmt.Sprintf(`SELECT id, user_id, type, amount, date, description, created_at, updated_at 
 	          FROM transactions 
-	          WHERE user_id = $1`
-	rows, err := r.handler.QueryContext(ctx, query, userId)
+	          %s 
+	          %s 
+	          LIMIT %s`, builder.whereClause(), orderBy(sort), builder.arg(limit))
+	rows, err := r.handler.QueryContext(ctx, query, builder.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -112,12 +177,60 @@ func (r *TransactionRepository) GetTransactionsByUserId(ctx context.Context, use
 	return transactions, rows.Err()
 }
 
-func (r *TransactionRepository) GetTransactionsWithPagination(ctx context.Context, offset, limit int) ([]model.Transaction, error) {
-	query := `SELECT id, user_id, type, amount, date, description, created_at, updated_at 
+func (r *TransactionRepository) CountTransactions(ctx context.Context, filter model.TransactionFilter) (int64, error) {
+	var builder queryBuilder
+	builder.filter(filter)
+
+	var count int64
+	query := `SELECT COUNT(*) FROM transactions ` + builder.whereClause()
+	if err := r.handler.QueryRowContext(ctx, query, builder.args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// StreamTransactions reads through a server side cursor, so only one batch is
+// held in memory at a time. It must run inside a database transaction.
+func (r *TransactionRepository) StreamTransactions(ctx context.Context, filter model.TransactionFilter, sort model.TransactionSort, batchSize int, fn func([]model.Transaction) error) error {
+	var builder queryBuilder
+	builder.filter(filter)
+
+	query := fmt.Sprintf(`DECLARE transactions_stream NO SCROLL CURSOR FOR 
+	          SELECT id, user_id, type, amount, date, description, created_at, updated_at 
 	          FROM transactions 
-	          ORDER BY date DESC 
-	          LIMIT $1 OFFSET $2`
-	rows, err := r.handler.QueryContext(ctx, query, limit, offset)
+	          %s 
+	          %s`, builder.whereClause(), orderBy(sort))
+	if _, err := r.handler.ExecContext(ctx, query, builder.args...); err != nil {
+		return err
+	}
+
+	fetch := fmt.Sprintf(`FETCH FORWARD %d FROM transactions_stream`, batchSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		batch, err := r.fetchBatch(ctx, fetch)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	_, err := r.handler.ExecContext(ctx, `CLOSE transactions_stream`)
+	return err
+}
+
+func (r *TransactionRepository) fetchBatch(ctx context.Context, fetch string) ([]model.Transaction, error) {
+	rows, err := r.handler.QueryContext(ctx, fetch)
 	if err != nil {
 		return nil, err
 	}
@@ -134,24 +247,48 @@ func (r *TransactionRepository) GetTransactionsWithPagination(ctx context.Contex
 	return transactions, rows.Err()
 }
 
-func (r *TransactionRepository) GetBalanceByUserId(ctx context.Context, userId string) (int64, error) {
-	var balance int64
-
-	query := `
-		SELECT 
-			COALESCE(SUM(CASE WHEN type = 'deposit' THEN amount ELSE 0 END) - SUM(CASE WHEN type = 'withdrawal' THEN amount ELSE 0 END), 0) 
-		FROM 
-			transactions 
-		WHERE 
-			user_id = $1`
+// toTsQuery renders search terms in to_tsquery syntax. Words only contain
+// letters and digits, so they can never inject tsquery operators.
+func toTsQuery(terms []model.SearchTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		words := make([]string, len(term.Words))
+		copy(words, term.Words)
+		if term.Prefix {
+			words[len(words)-1] += ":*"
+		}
+		parts = append(parts, "("+strings.Join(words, " <-> ")+")")
+	}
+	return strings.Join(parts, " & ")
+}
 
-	err := r.handler.QueryRowContext(ctx, query, userId).Scan(&balance)
+func (r *TransactionRepository) SummarizeTransactions(ctx context.Context, userId string, from, to time.Time) (*model.TransactionSummary, error) {
+	query := `SELECT 
+	              COUNT(*) FILTER (WHERE date >= $2), 
+	              COALESCE(SUM(amount) FILTER (WHERE date >= $2 AND type = 'deposit'), 0), 
+	              COALESCE(SUM(amount) FILTER (WHERE date >= $2 AND type = 'withdrawal'), 0), 
+	              COALESCE(SUM(CASE WHEN type = 'deposit' THEN amount ELSE -amount END), 0) 
+	          FROM transactions 
+	          WHERE user_id = $1 AND date < $3`
+	var summary model.TransactionSummary
+	err := r.handler.QueryRowContext(ctx, query, userId, from, to).Scan(&summary.Count, &summary.TotalIn, &summary.TotalOut, &summary.ClosingBalance)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return 0, nil
-		}
-		return 0, err
+		return nil, err
+	}
+	if summary.Count == 0 {
+		return &summary, nil
 	}
 
-	return balance, nil
+	query = `SELECT id, user_id, type, amount, date, description, created_at, updated_at 
+	         FROM transactions 
+	         WHERE user_id = $1 AND date >= $2 AND date < $3 
+	         ORDER BY amount DESC, date, id 
+	         LIMIT 1`
+	var largest model.Transaction
+	err = r.handler.QueryRowContext(ctx, query, userId, from, to).Scan(&largest.Id, &largest.UserId, &largest.Type, &largest.Amount, &largest.Date, &largest.Description, &largest.CreatedAt, &largest.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	summary.Largest = &largest
+	return &summary, nil
 }
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+// TransactionEventsChannel is the Postgres NOTIFY channel carrying the sequence of every appended event.
+const TransactionEventsChannel = "transaction_events"
+
+type TransactionEventRepositoryFactory struct{}
+
+func NewTransactionEventRepositoryFactory() *TransactionEventRepositoryFactory {
+	return &TransactionEventRepositoryFactory{}
+}
+
+func (f *TransactionEventRepositoryFactory) New(handler db.DbHandler) repository.TransactionEventRepository {
+	return NewTransactionEventRepository(handler)
+}
+
+type TransactionEventRepository struct {
+	handler db.DbHandler
+}
+
+func NewTransactionEventRepository(handler db.DbHandler) *TransactionEventRepository {
+	return &TransactionEventRepository{handler: handler}
+}
+
+func (r *TransactionEventRepository) AppendEvent(ctx context.Context, event model.TransactionEvent) (*model.TransactionEvent, error) {
+	// Serialize writers per user until commit, so a user's sequences are
+	// assigned in commit order and resuming after a sequence never skips events.
+	if _, err := r.handler.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, event.UserId); err != nil {
+		return nil, err
+	}
+
+	transaction, err := json.Marshal(event.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `INSERT INTO transaction_events (type, user_id, transaction_id, transaction)
+	          VALUES ($1, $2, $3, $4)
+	          RETURNING id, sequence, occurred_at`
+	err = r.handler.QueryRowContext(ctx, query, event.Type, event.UserId, event.Transaction.Id, transaction).Scan(&event.Id, &event.Sequence, &event.OccurredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTIFY is only delivered when the transaction commits
+	if _, err := r.handler.ExecContext(ctx, `SELECT pg_notify($1, $2)`, TransactionEventsChannel, strconv.FormatInt(event.Sequence, 10)); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *TransactionEventRepository) GetEventBySequence(ctx context.Context, sequence int64) (*model.TransactionEvent, error) {
+	query := `SELECT id, sequence, type, user_id, transaction, occurred_at
+	          FROM transaction_events
+	          WHERE sequence = $1`
+	event, err := scanEvent(r.handler.QueryRowContext(ctx, query, sequence))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+func (r *TransactionEventRepository) GetEventsAfter(ctx context.Context, userId string, sequence int64, limit int) ([]model.TransactionEvent, error) {
+	query := `SELECT id, sequence, type, user_id, transaction, occurred_at
+	          FROM transaction_events
+	          WHERE sequence > $1 AND ($2 = '' OR user_id::text = $2)
+	          ORDER BY sequence
+	          LIMIT $3`
+	rows, err := r.handler.QueryContext(ctx, query, sequence, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []model.TransactionEvent
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	return events, rows.Err()
+}
+
+func (r *TransactionEventRepository) GetLatestSequence(ctx context.Context, userId string) (int64, error) {
+	query := `SELECT COALESCE(MAX(sequence), 0)
+	          FROM transaction_events
+	          WHERE $1 = '' OR user_id::text = $1`
+	var sequence int64
+	err := r.handler.QueryRowContext(ctx, query, userId).Scan(&sequence)
+	return sequence, err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEvent(row scanner) (*model.TransactionEvent, error) {
+	var event model.TransactionEvent
+	var transaction []byte
+	if err := row.Scan(&event.Id, &event.Sequence, &event.Type, &event.UserId, &transaction, &event.OccurredAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(transaction, &event.Transaction); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
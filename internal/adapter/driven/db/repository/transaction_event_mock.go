@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type InMemoryTransactionEventRepositoryFactory struct {
+	repo *InMemoryTransactionEventRepository
+}
+
+func NewInMemoryTransactionEventRepositoryFactory(repo *InMemoryTransactionEventRepository) *InMemoryTransactionEventRepositoryFactory {
+	return &InMemoryTransactionEventRepositoryFactory{repo: repo}
+}
+
+func (f *InMemoryTransactionEventRepositoryFactory) New(handler db.DbHandler) repository.TransactionEventRepository {
+	return f.repo
+}
+
+// InMemoryTransactionEventRepository implements TransactionEventRepository using in-memory storage.
+// Without real transactions it hands every appended event to notify right away.
+type InMemoryTransactionEventRepository struct {
+	events []model.TransactionEvent
+	notify func(model.TransactionEvent)
+	mu     sync.RWMutex
+}
+
+func NewInMemoryTransactionEventRepository(notify func(model.TransactionEvent)) *InMemoryTransactionEventRepository {
+	return &InMemoryTransactionEventRepository{notify: notify}
+}
+
+func (r *InMemoryTransactionEventRepository) AppendEvent(ctx context.Context, event model.TransactionEvent) (*model.TransactionEvent, error) {
+	r.mu.Lock()
+	event.Id = uuid.New().String()
+	event.Sequence = int64(len(r.events) + 1)
+	event.OccurredAt = time.Now()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+
+	if r.notify != nil {
+		r.notify(event)
+	}
+	return &event, nil
+}
+
+func (r *InMemoryTransactionEventRepository) GetEventBySequence(ctx context.Context, sequence int64) (*model.TransactionEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if sequence < 1 || sequence > int64(len(r.events)) {
+		return nil, nil
+	}
+	event := r.events[sequence-1]
+	return &event, nil
+}
+
+func (r *InMemoryTransactionEventRepository) GetEventsAfter(ctx context.Context, userId string, sequence int64, limit int) ([]model.TransactionEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var events []model.TransactionEvent
+	for _, event := range r.events {
+		if event.Sequence > sequence && (userId == "" || event.UserId == userId) {
+			events = append(events, event)
+			if len(events) == limit {
+				break
+			}
+		}
+	}
+	return events, nil
+}
+
+func (r *InMemoryTransactionEventRepository) GetLatestSequence(ctx context.Context, userId string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sequence int64
+	for _, event := range r.events {
+		if userId == "" || event.UserId == userId {
+			sequence = event.Sequence
+		}
+	}
+	return sequence, nil
+}
@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"errors"
+	"math"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -63,13 +66,6 @@ func (r *InMemoryTransactionRepository) GetTransactionById(ctx context.Context,
 	return nil, errors.New("transaction not found")
 }
 
-func (r *InMemoryTransactionRepository) GetAllTransactions(ctx context.Context) ([]model.Transaction, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	return r.transactions, nil
-}
-
 func (r *InMemoryTransactionRepository) UpdateTransaction(ctx context.Context, transaction model.Transaction) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -97,19 +93,6 @@ func (r *InMemoryTransactionRepository) DeleteTransaction(ctx context.Context, i
 	return errors.New("transaction not found")
 }
 
-func (r *InMemoryTransactionRepository) GetTransactionsByUserId(ctx context.Context, userId string) ([]model.Transaction, error) {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
-	var userTransactions []model.Transaction
-	for _, t := range r.transactions {
-		if t.UserId == userId {
-			userTransactions = append(userTransactions, t)
-		}
-	}
-	return userTransactions, nil
-}
-
 func (r *InMemoryTransactionRepository) GetTransactionsWithPagination(ctx context.Context, offset, limit int) ([]model.Transaction, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -138,3 +121,145 @@ func (r *InMemoryTransactionRepository) GetBalanceByUserId(ctx context.Context,
 	}
 	return depositSum - withdrawalSum, nil
 }
+
+// SearchTransactions is a simple stand-in for the Postgres full-text search. A
+// term matches when its words appear consecutively in the description and the
+// rank is the number of times all terms matched.
+func (r *InMemoryTransactionRepository) SearchTransactions(ctx context.Context, search model.TransactionSearch) ([]model.TransactionSearchResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []model.TransactionSearchResult
+	for _, t := range r.transactions {
+		if t.UserId != search.UserId || !search.Filter.Matches(t) {
+			continue
+		}
+
+		words := model.SearchWords(t.Description)
+		rank := 0
+		for _, term := range search.Terms {
+			matches := countTermMatches(words, term)
+			if matches == 0 {
+				rank = 0
+				break
+			}
+			rank += matches
+		}
+		result := model.TransactionSearchResult{Transaction: t, Rank: float64(rank)}
+		if rank > 0 && (search.After == nil || search.After.IsAfter(result)) {
+			results = append(results, result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return model.SearchCursorOf(results[i]).IsAfter(results[j])
+	})
+	if search.Limit > 0 && len(results) > search.Limit {
+		results = results[:search.Limit]
+	}
+	return results, nil
+}
+
+func countTermMatches(words []string, term model.SearchTerm) int {
+	count := 0
+	for start := 0; start+len(term.Words) <= len(words); start++ {
+		matched := true
+		for i, word := range term.Words {
+			last := i == len(term.Words)-1
+			if words[start+i] != word && !(last && term.Prefix && strings.HasPrefix(words[start+i], word)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *InMemoryTransactionRepository) ListTransactions(ctx context.Context, filter model.TransactionFilter, order model.TransactionSort, after *model.TransactionCursor, limit int) ([]model.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var transactions []model.Transaction
+	for _, t := range r.transactions {
+		if filter.Matches(t) && (after == nil || order.IsAfter(t, *after)) {
+			transactions = append(transactions, t)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return order.Less(transactions[i], transactions[j])
+	})
+
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+	}
+	return transactions, nil
+}
+
+func (r *InMemoryTransactionRepository) CountTransactions(ctx context.Context, filter model.TransactionFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, t := range r.transactions {
+		if filter.Matches(t) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *InMemoryTransactionRepository) StreamTransactions(ctx context.Context, filter model.TransactionFilter, order model.TransactionSort, batchSize int, fn func([]model.Transaction) error) error {
+	transactions, err := r.ListTransactions(ctx, filter, order, nil, math.MaxInt)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(transactions); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := start + batchSize
+		if end > len(transactions) {
+			end = len(transactions)
+		}
+		if err := fn(transactions[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryTransactionRepository) SummarizeTransactions(ctx context.Context, userId string, from, to time.Time) (*model.TransactionSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var summary model.TransactionSummary
+	for _, t := range r.transactions {
+		if t.UserId != userId || !t.Date.Before(to) {
+			continue
+		}
+		if t.Type == "deposit" {
+			summary.ClosingBalance += t.Amount
+		} else {
+			summary.ClosingBalance -= t.Amount
+		}
+		if t.Date.Before(from) {
+			continue
+		}
+
+		summary.Count++
+		if t.Type == "deposit" {
+			summary.TotalIn += t.Amount
+		} else {
+			summary.TotalOut += t.Amount
+		}
+		if summary.Largest == nil || t.Amount > summary.Largest.Amount || (t.Amount == summary.Largest.Amount && t.Date.Before(summary.Largest.Date)) {
+			largest := t
+			summary.Largest = &largest
+		}
+	}
+	return &summary, nil
+}
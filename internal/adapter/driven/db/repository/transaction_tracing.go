@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+
+// TracedTransactionRepositoryFactory wraps the repositories of another
+// factory so every query gets a span.
+type TracedTransactionRepositoryFactory struct {
+	next repository.TransactionRepositoryFactory
+}
+
+func NewTracedTransactionRepositoryFactory(next repository.TransactionRepositoryFactory) *TracedTransactionRepositoryFactory {
+	return &TracedTransactionRepositoryFactory{next: next}
+}
+
+func (f *TracedTransactionRepositoryFactory) New(handler db.DbHandler) repository.TransactionRepository {
+	return &tracedTransactionRepository{next: f.next.New(handler)}
+}
+
+type tracedTransactionRepository struct {
+	next repository.TransactionRepository
+}
+
+// startQuerySpan starts the span of a TransactionRepository operation.
+func startQuerySpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, "TransactionRepository."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", operation),
+	))
+}
+
+func (r *tracedTransactionRepository) CreateTransaction(ctx context.Context, transaction model.Transaction) (string, error) {
+	ctx, span := startQuerySpan(ctx, "CreateTransaction")
+	id, err := r.next.CreateTransaction(ctx, transaction)
+	tracing.EndSpan(span, err)
+	return id, err
+}
+
+func (r *tracedTransactionRepository) GetTransactionById(ctx context.Context, id string) (*model.Transaction, error) {
+	ctx, span := startQuerySpan(ctx, "GetTransactionById")
+	transaction, err := r.next.GetTransactionById(ctx, id)
+	tracing.EndSpan(span, err)
+	return transaction, err
+}
+
+func (r *tracedTransactionRepository) UpdateTransaction(ctx context.Context, transaction model.Transaction) error {
+	ctx, span := startQuerySpan(ctx, "UpdateTransaction")
+	err := r.next.UpdateTransaction(ctx, transaction)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (r *tracedTransactionRepository) DeleteTransaction(ctx context.Context, id string) error {
+	ctx, span := startQuerySpan(ctx, "DeleteTransaction")
+	err := r.next.DeleteTransaction(ctx, id)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (r *tracedTransactionRepository) GetTransactionsWithPagination(ctx context.Context, offset, limit int) ([]model.Transaction, error) {
+	ctx, span := startQuerySpan(ctx, "GetTransactionsWithPagination")
+	transactions, err := r.next.GetTransactionsWithPagination(ctx, offset, limit)
+	tracing.EndSpan(span, err)
+	return transactions, err
+}
+
+func (r *tracedTransactionRepository) GetBalanceByUserId(ctx context.Context, userId string) (int64, error) {
+	ctx, span := startQuerySpan(ctx, "GetBalanceByUserId")
+	balance, err := r.next.GetBalanceByUserId(ctx, userId)
+	tracing.EndSpan(span, err)
+	return balance, err
+}
+
+func (r *tracedTransactionRepository) SearchTransactions(ctx context.Context, search model.TransactionSearch) ([]model.TransactionSearchResult, error) {
+	ctx, span := startQuerySpan(ctx, "SearchTransactions")
+	results, err := r.next.SearchTransactions(ctx, search)
+	tracing.EndSpan(span, err)
+	return results, err
+}
+
+func (r *tracedTransactionRepository) ListTransactions(ctx context.Context, filter model.TransactionFilter, sort model.TransactionSort, after *model.TransactionCursor, limit int) ([]model.Transaction, error) {
+	ctx, span := startQuerySpan(ctx, "ListTransactions")
+	transactions, err := r.next.ListTransactions(ctx, filter, sort, after, limit)
+	tracing.EndSpan(span, err)
+	return transactions, err
+}
+
+func (r *tracedTransactionRepository) CountTransactions(ctx context.Context, filter model.TransactionFilter) (int64, error) {
+	ctx, span := startQuerySpan(ctx, "CountTransactions")
+	count, err := r.next.CountTransactions(ctx, filter)
+	tracing.EndSpan(span, err)
+	return count, err
+}
+
+func (r *tracedTransactionRepository) SummarizeTransactions(ctx context.Context, userId string, from, to time.Time) (*model.TransactionSummary, error) {
+	ctx, span := startQuerySpan(ctx, "SummarizeTransactions")
+	summary, err := r.next.SummarizeTransactions(ctx, userId, from, to)
+	tracing.EndSpan(span, err)
+	return summary, err
+}
+
+func (r *tracedTransactionRepository) StreamTransactions(ctx context.Context, filter model.TransactionFilter, sort model.TransactionSort, batchSize int, fn func([]model.Transaction) error) error {
+	ctx, span := startQuerySpan(ctx, "StreamTransactions")
+	err := r.next.StreamTransactions(ctx, filter, sort, batchSize, fn)
+	tracing.EndSpan(span, err)
+	return err
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type WebhookRepositoryFactory struct {
+	cipher *SecretCipher
+}
+
+func NewWebhookRepositoryFactory(cipher *SecretCipher) *WebhookRepositoryFactory {
+	return &WebhookRepositoryFactory{cipher: cipher}
+}
+
+func (f *WebhookRepositoryFactory) New(handler db.DbHandler) repository.WebhookRepository {
+	return NewWebhookRepository(handler, f.cipher)
+}
+
+// WebhookRepository stores the secrets of subscriptions encrypted with cipher.
+type WebhookRepository struct {
+	handler db.DbHandler
+	cipher  *SecretCipher
+}
+
+func NewWebhookRepository(handler db.DbHandler, cipher *SecretCipher) *WebhookRepository {
+	return &WebhookRepository{handler: handler, cipher: cipher}
+}
+
+const subscriptionColumns = `id, user_id, url, secret, COALESCE(previous_secret, ''), previous_secret_expires_at, event_types, status, created_at, updated_at`
+
+func (r *WebhookRepository) LockSubscriptions(ctx context.Context, userId string) error {
+	_, err := r.handler.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('webhook_subscriptions:' || $1))`, userId)
+	return err
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription model.WebhookSubscription) (string, error) {
+	secret, err := r.cipher.Encrypt(subscription.Secret)
+	if err != nil {
+		return "", err
+	}
+	query := `INSERT INTO webhook_subscriptions (user_id, url, secret, event_types, status) 
+	          VALUES ($1, $2, $3, $4, $5) 
+	          RETURNING id`
+	var id string
+	err = r.handler.QueryRowContext(ctx, query, subscription.UserId, subscription.Url, secret, pq.Array(eventTypeStrings(subscription.EventTypes)), subscription.Status).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *WebhookRepository) GetSubscriptionById(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` 
+	          FROM webhook_subscriptions 
+	          WHERE id = $1`
+	subscription, err := r.scanSubscription(r.handler.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (r *WebhookRepository) GetSubscriptionsByUserId(ctx context.Context, userId string) ([]model.WebhookSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` 
+	          FROM webhook_subscriptions 
+	          WHERE user_id = $1 
+	          ORDER BY created_at, id`
+	return r.querySubscriptions(ctx, query, userId)
+}
+
+func (r *WebhookRepository) GetActiveSubscriptions(ctx context.Context, userId string, eventType model.TransactionEventType) ([]model.WebhookSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` 
+	          FROM webhook_subscriptions 
+	          WHERE user_id = $1 AND status = 'active' AND $2 = ANY(event_types) 
+	          ORDER BY created_at, id`
+	return r.querySubscriptions(ctx, query, userId, eventType)
+}
+
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, subscription model.WebhookSubscription) error {
+	secret, err := r.cipher.Encrypt(subscription.Secret)
+	if err != nil {
+		return err
+	}
+	previousSecret, err := r.cipher.Encrypt(subscription.PreviousSecret)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE webhook_subscriptions 
+	          SET url = $1, secret = $2, previous_secret = NULLIF($3, ''), previous_secret_expires_at = $4, event_types = $5, status = $6, updated_at = $7 
+	          WHERE id = $8`
+	_, err = r.handler.ExecContext(ctx, query, subscription.Url, secret, previousSecret, subscription.PreviousSecretExpiresAt,
+		pq.Array(eventTypeStrings(subscription.EventTypes)), subscription.Status, time.Now(), subscription.Id)
+	return err
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	query := `DELETE FROM webhook_subscriptions WHERE id = $1`
+	_, err := r.handler.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *WebhookRepository) RecordDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, attempt, status_code, latency_ms, error, attempted_at) 
+	          VALUES ($1, $2, $3, $4, NULLIF($5, 0), $6, NULLIF($7, ''), $8)`
+	_, err := r.handler.ExecContext(ctx, query, delivery.SubscriptionId, delivery.EventId, delivery.EventType, delivery.Attempt,
+		delivery.StatusCode, delivery.Latency.Milliseconds(), delivery.Error, delivery.AttemptedAt)
+	return err
+}
+
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, subscriptionId string, beforeId int64, limit int) ([]model.WebhookDelivery, error) {
+	query := `SELECT id, subscription_id, event_id, event_type, attempt, COALESCE(status_code, 0), latency_ms, COALESCE(error, ''), attempted_at 
+	          FROM webhook_deliveries 
+	          WHERE subscription_id = $1 AND ($2 = 0 OR id < $2) 
+	          ORDER BY id DESC 
+	          LIMIT $3`
+	rows, err := r.handler.QueryContext(ctx, query, subscriptionId, beforeId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		var delivery model.WebhookDelivery
+		var latency int64
+		if err := rows.Scan(&delivery.Id, &delivery.SubscriptionId, &delivery.EventId, &delivery.EventType, &delivery.Attempt, &delivery.StatusCode, &latency, &delivery.Error, &delivery.AttemptedAt); err != nil {
+			return nil, err
+		}
+		delivery.Latency = time.Duration(latency) * time.Millisecond
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepository) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]model.WebhookSubscription, error) {
+	rows, err := r.handler.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []model.WebhookSubscription
+	for rows.Next() {
+		subscription, err := r.scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+func (r *WebhookRepository) scanSubscription(row scanner) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	var previousSecretExpiresAt sql.NullTime
+	var eventTypes []string
+	err := row.Scan(&subscription.Id, &subscription.UserId, &subscription.Url, &subscription.Secret, &subscription.PreviousSecret, &previousSecretExpiresAt,
+		pq.Array(&eventTypes), &subscription.Status, &subscription.CreatedAt, &subscription.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if previousSecretExpiresAt.Valid {
+		subscription.PreviousSecretExpiresAt = &previousSecretExpiresAt.Time
+	}
+	if subscription.Secret, err = r.cipher.Decrypt(subscription.Secret); err != nil {
+		return nil, err
+	}
+	if subscription.PreviousSecret, err = r.cipher.Decrypt(subscription.PreviousSecret); err != nil {
+		return nil, err
+	}
+	for _, eventType := range eventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, model.TransactionEventType(eventType))
+	}
+	return &subscription, nil
+}
+
+func eventTypeStrings(eventTypes []model.TransactionEventType) []string {
+	strings := make([]string, len(eventTypes))
+	for i, eventType := range eventTypes {
+		strings[i] = string(eventType)
+	}
+	return strings
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+type InMemoryWebhookRepositoryFactory struct {
+	repo *InMemoryWebhookRepository
+}
+
+func NewInMemoryWebhookRepositoryFactory(repo *InMemoryWebhookRepository) *InMemoryWebhookRepositoryFactory {
+	return &InMemoryWebhookRepositoryFactory{repo: repo}
+}
+
+func (f *InMemoryWebhookRepositoryFactory) New(handler db.DbHandler) repository.WebhookRepository {
+	return f.repo
+}
+
+// InMemoryWebhookRepository implements WebhookRepository using in-memory storage.
+type InMemoryWebhookRepository struct {
+	subscriptions []model.WebhookSubscription
+	deliveries    []model.WebhookDelivery
+	lastDelivery  int64
+	mu            sync.RWMutex
+}
+
+func NewInMemoryWebhookRepository() *InMemoryWebhookRepository {
+	return &InMemoryWebhookRepository{}
+}
+
+// LockSubscriptions does nothing, every method of the repository runs under its mutex.
+func (r *InMemoryWebhookRepository) LockSubscriptions(ctx context.Context, userId string) error {
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) CreateSubscription(ctx context.Context, subscription model.WebhookSubscription) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription.Id = uuid.New().String()
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+	r.subscriptions = append(r.subscriptions, subscription)
+	return subscription.Id, nil
+}
+
+func (r *InMemoryWebhookRepository) GetSubscriptionById(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, subscription := range r.subscriptions {
+		if subscription.Id == id {
+			return &subscription, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *InMemoryWebhookRepository) GetSubscriptionsByUserId(ctx context.Context, userId string) ([]model.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subscriptions []model.WebhookSubscription
+	for _, subscription := range r.subscriptions {
+		if subscription.UserId == userId {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (r *InMemoryWebhookRepository) GetActiveSubscriptions(ctx context.Context, userId string, eventType model.TransactionEventType) ([]model.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subscriptions []model.WebhookSubscription
+	for _, subscription := range r.subscriptions {
+		if subscription.UserId == userId && subscription.Accepts(eventType) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (r *InMemoryWebhookRepository) UpdateSubscription(ctx context.Context, subscription model.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.subscriptions {
+		if r.subscriptions[i].Id == subscription.Id {
+			subscription.UpdatedAt = time.Now()
+			r.subscriptions[i] = subscription
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.subscriptions {
+		if r.subscriptions[i].Id == id {
+			r.subscriptions = append(r.subscriptions[:i], r.subscriptions[i+1:]...)
+			break
+		}
+	}
+
+	deliveries := r.deliveries[:0]
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionId != id {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	r.deliveries = deliveries
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) RecordDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastDelivery++
+	delivery.Id = r.lastDelivery
+	r.deliveries = append(r.deliveries, delivery)
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) GetDeliveries(ctx context.Context, subscriptionId string, beforeId int64, limit int) ([]model.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []model.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionId == subscriptionId && (beforeId == 0 || delivery.Id < beforeId) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].Id > deliveries[j].Id })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
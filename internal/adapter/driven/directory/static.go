@@ -0,0 +1,10 @@
+package directory
+
+import "context"
+
+// StaticUserDirectory serves email addresses from configuration, keyed by user id.
+type StaticUserDirectory map[string]string
+
+func (d StaticUserDirectory) GetUserEmail(ctx context.Context, userId string) (string, error) {
+	return d[userId], nil
+}
@@ -0,0 +1,267 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+)
+
+type TLSMode string
+
+const (
+	// TLSStartTLS upgrades the connection with STARTTLS and fails when the server does not offer it.
+	TLSStartTLS TLSMode = "starttls"
+	// TLSNone sends in plain text, only meant for local relays.
+	TLSNone TLSMode = "none"
+)
+
+var ErrStartTLSUnsupported = errors.New("smtp server does not support STARTTLS")
+
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLS      TLSMode
+	// TLSConfig overrides the TLS settings used for STARTTLS.
+	TLSConfig *tls.Config
+	// Timeout limits the whole exchange with the server, defaults to 30 seconds.
+	Timeout time.Duration
+	// Templates overrides the templates of event types, others use DefaultTemplates.
+	Templates map[model.TransactionEventType]Template
+}
+
+// NotificationService emails transaction notifications to the address the
+// UserDirectory knows for the user. Users without an address are skipped. The
+// subject and message of the notification replace those of the template.
+type NotificationService struct {
+	config    Config
+	directory driven.UserDirectory
+	templates map[model.TransactionEventType]Template
+}
+
+func NewNotificationService(config Config, directory driven.UserDirectory) *NotificationService {
+	if config.TLS == "" {
+		config.TLS = TLSStartTLS
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	templates := make(map[model.TransactionEventType]Template)
+	for eventType, template := range DefaultTemplates {
+		templates[eventType] = template
+	}
+	for eventType, template := range config.Templates {
+		templates[eventType] = template
+	}
+	return &NotificationService{config: config, directory: directory, templates: templates}
+}
+
+func (s *NotificationService) SendTransactionNotification(ctx context.Context, notification model.Notification) error {
+	event := notification.Event
+	to, err := s.directory.GetUserEmail(ctx, event.UserId)
+	if err != nil {
+		return err
+	}
+	if to == "" {
+		slog.InfoContext(ctx, "no email address, skipping notification", "user_id", event.UserId, "event_id", event.Id)
+		return nil
+	}
+
+	from, err := mail.ParseAddress(s.config.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid email address of user %s: %w", event.UserId, err)
+	}
+
+	message, err := s.render(notification, from, recipient)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, from.Address, recipient.Address, message)
+}
+
+// render builds a multipart/alternative message with a plain text and an HTML body.
+func (s *NotificationService) render(notification model.Notification, from, to *mail.Address) ([]byte, error) {
+	event := notification.Event
+	template, ok := s.templates[event.Type]
+	if !ok {
+		return nil, fmt.Errorf("no email template for %s events", event.Type)
+	}
+	data := templateData{
+		Event:       event,
+		Transaction: event.Transaction,
+		Date:        event.Transaction.Date.UTC().Format("2006-01-02 15:04 MST"),
+		Message:     notification.Message,
+	}
+	if notification.Digest != nil {
+		data.Digest = notification.Digest
+		data.Date = notification.Digest.Day
+	}
+
+	var subject, text, html bytes.Buffer
+	if notification.Subject != "" {
+		subject.WriteString(notification.Subject)
+	} else if err := template.Subject.Execute(&subject, data); err != nil {
+		return nil, err
+	}
+	if err := template.Text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if err := template.HTML.Execute(&html, data); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	body := multipart.NewWriter(&message)
+	headers := []struct{ key, value string }{
+		{"From", from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject.String())},
+		{"Date", event.OccurredAt.Format(time.RFC1123Z)},
+		{"Message-ID", "<" + event.Id + "@finman-transaction-service>"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + body.Boundary()},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&message, "%s: %s\r\n", header.key, header.value)
+	}
+	message.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		writer, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
+
+func (s *NotificationService) send(ctx context.Context, from, to string, message []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// CheckHealth connects and authenticates to the SMTP server without sending anything.
+func (s *NotificationService) CheckHealth(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Noop(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// dial connects to the SMTP server, upgrades the connection with STARTTLS
+// unless disabled and authenticates. The connection is bound to the deadline
+// of ctx.
+func (s *NotificationService) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if s.config.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, ErrStartTLSUnsupported
+		}
+		tlsConfig := &tls.Config{ServerName: s.config.Host}
+		if s.config.TLSConfig != nil {
+			tlsConfig = s.config.TLSConfig.Clone()
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	if s.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
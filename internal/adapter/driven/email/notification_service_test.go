@@ -0,0 +1,337 @@
+package email_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/directory"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/email"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// received is a message accepted by smtpServer.
+type received struct {
+	from, to string
+	tls      bool
+	auth     string
+	data     string
+}
+
+// smtpServer is a minimal in-process SMTP server supporting STARTTLS and AUTH PLAIN.
+type smtpServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	mu        sync.Mutex
+	messages  []received
+}
+
+func newSMTPServer(t *testing.T, tlsConfig *tls.Config) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := &smtpServer{listener: listener, tlsConfig: tlsConfig}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *smtpServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) received() []received {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]received{}, s.messages...)
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpServer) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var message received
+	reply("220 localhost ESMTP ready")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			if s.tlsConfig != nil && !message.tls {
+				reply("250-localhost")
+				reply("250-STARTTLS")
+			} else {
+				reply("250-localhost")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			reader = bufio.NewReader(conn)
+			message = received{tls: true}
+		case "AUTH":
+			credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(command, "AUTH PLAIN "))
+			message.auth = string(credentials)
+			reply("235 Authentication successful")
+		case "MAIL":
+			message.from = strings.TrimSuffix(strings.TrimPrefix(command, "MAIL FROM:<"), ">")
+			reply("250 OK")
+		case "RCPT":
+			message.to = strings.TrimSuffix(strings.TrimPrefix(command, "RCPT TO:<"), ">")
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			message.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// newCertificate returns a self-signed certificate for 127.0.0.1 and a pool trusting it.
+func newCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(certificate)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func newEvent(userId string) model.TransactionEvent {
+	return model.TransactionEvent{
+		Id:         uuid.New().String(),
+		Type:       model.TransactionCreated,
+		UserId:     userId,
+		OccurredAt: time.Now(),
+		Transaction: model.Transaction{
+			Id:          uuid.New().String(),
+			UserId:      userId,
+			Type:        "deposit",
+			Amount:      1250,
+			Date:        time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC),
+			Description: "Salary <June>",
+		},
+	}
+}
+
+// parts returns the decoded bodies of a multipart message by content type.
+func parts(t *testing.T, data string) (*mail.Message, map[string]string) {
+	message, err := mail.ReadMessage(strings.NewReader(data))
+	assert.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	bodies := make(map[string]string)
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		content, _ := io.ReadAll(part)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[contentType] = string(content)
+	}
+	return message, bodies
+}
+
+func TestSendTransactionNotificationWithStartTLS(t *testing.T) {
+	certificate, pool := newCertificate(t)
+	server := newSMTPServer(t, &tls.Config{Certificates: []tls.Certificate{certificate}})
+
+	userId := uuid.New().String()
+	service := email.NewNotificationService(email.Config{
+		Host:      "127.0.0.1",
+		Port:      server.port(),
+		Username:  "finman",
+		Password:  "secret",
+		From:      "Finman <no-reply@finman.test>",
+		TLSConfig: &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"},
+	}, directory.StaticUserDirectory{userId: "jane@example.com"})
+
+	event := newEvent(userId)
+	err := service.SendTransactionNotification(context.Background(), model.Notification{Event: event})
+	assert.NoError(t, err)
+
+	messages := server.received()
+	assert.Equal(t, 1, len(messages))
+	assert.True(t, messages[0].tls)
+	assert.Equal(t, "\x00finman\x00secret", messages[0].auth)
+	assert.Equal(t, "no-reply@finman.test", messages[0].from)
+	assert.Equal(t, "jane@example.com", messages[0].to)
+
+	message, bodies := parts(t, messages[0].data)
+	assert.Equal(t, "<jane@example.com>", message.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Your deposit of 1250 was recorded", subject)
+
+	assert.Contains(t, bodies["text/plain"], "Amount: 1250")
+	assert.Contains(t, bodies["text/plain"], "Description: Salary <June>")
+	assert.Contains(t, bodies["text/plain"], "Date: 2024-06-01 12:30 UTC")
+	assert.Contains(t, bodies["text/plain"], event.Transaction.Id)
+	// HTML bodies are escaped
+	assert.Contains(t, bodies["text/html"], "Salary &lt;June&gt;")
+	assert.Contains(t, bodies["text/html"], "<td>1250</td>")
+}
+
+func TestSendTransactionNotificationRequiresStartTLS(t *testing.T) {
+	server := newSMTPServer(t, nil)
+	userId := uuid.New().String()
+	users := directory.StaticUserDirectory{userId: "jane@example.com"}
+
+	service := email.NewNotificationService(email.Config{Host: "127.0.0.1", Port: server.port(), From: "no-reply@finman.test"}, users)
+	err := service.SendTransactionNotification(context.Background(), model.Notification{Event: newEvent(userId)})
+	assert.ErrorIs(t, err, email.ErrStartTLSUnsupported)
+	assert.Equal(t, 0, len(server.received()))
+
+	// Plain text relays have to be allowed explicitly
+	service = email.NewNotificationService(email.Config{Host: "127.0.0.1", Port: server.port(), From: "no-reply@finman.test", TLS: email.TLSNone}, users)
+	event := newEvent(userId)
+	event.Type = model.InsufficientBalance
+	event.Transaction.Id = ""
+	err = service.SendTransactionNotification(context.Background(), model.Notification{Event: event})
+	assert.NoError(t, err)
+
+	messages := server.received()
+	assert.Equal(t, 1, len(messages))
+	assert.False(t, messages[0].tls)
+	_, bodies := parts(t, messages[0].data)
+	assert.Contains(t, bodies["text/plain"], "declined because your balance is insufficient")
+	assert.NotContains(t, bodies["text/plain"], "Reference")
+}
+
+func TestSendTransactionNotificationSkipsUsersWithoutEmail(t *testing.T) {
+	server := newSMTPServer(t, nil)
+	service := email.NewNotificationService(email.Config{Host: "127.0.0.1", Port: server.port(), From: "no-reply@finman.test", TLS: email.TLSNone}, directory.StaticUserDirectory{})
+
+	err := service.SendTransactionNotification(context.Background(), model.Notification{Event: newEvent(uuid.New().String())})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(server.received()))
+}
+
+func TestSendTransactionNotificationCustomTemplate(t *testing.T) {
+	server := newSMTPServer(t, nil)
+	userId := uuid.New().String()
+	service := email.NewNotificationService(email.Config{
+		Host: "127.0.0.1",
+		Port: server.port(),
+		From: "no-reply@finman.test",
+		TLS:  email.TLSNone,
+		Templates: map[model.TransactionEventType]email.Template{
+			model.TransactionCreated: email.NewTemplate("Receipt {{ .Transaction.Id }}", "Thanks for {{ .Transaction.Amount }}", "<b>{{ .Transaction.Amount }}</b>"),
+		},
+	}, directory.StaticUserDirectory{userId: "jane@example.com"})
+
+	event := newEvent(userId)
+	err := service.SendTransactionNotification(context.Background(), model.Notification{Event: event})
+	assert.NoError(t, err)
+
+	message, bodies := parts(t, server.received()[0].data)
+	assert.Equal(t, "Receipt "+event.Transaction.Id, message.Header.Get("Subject"))
+	assert.Equal(t, "Thanks for 1250", bodies["text/plain"])
+	assert.Equal(t, "<b>1250</b>", bodies["text/html"])
+}
+
+func TestSendTransactionNotificationUsesRenderedMessage(t *testing.T) {
+	server := newSMTPServer(t, nil)
+	userId := uuid.New().String()
+	service := email.NewNotificationService(email.Config{Host: "127.0.0.1", Port: server.port(), From: "no-reply@finman.test", TLS: email.TLSNone},
+		directory.StaticUserDirectory{userId: "jane@example.com"})
+
+	err := service.SendTransactionNotification(context.Background(), model.Notification{
+		Event:   newEvent(userId),
+		Locale:  "de",
+		Subject: "Einzahlung über 1250 verbucht",
+		Message: "Ihre Einzahlung über 1250 wurde verbucht.",
+	})
+	assert.NoError(t, err)
+
+	message, bodies := parts(t, server.received()[0].data)
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Einzahlung über 1250 verbucht", subject)
+	assert.True(t, strings.HasPrefix(bodies["text/plain"], "Ihre Einzahlung über 1250 wurde verbucht.\r\n"))
+	assert.Contains(t, bodies["text/html"], "<h2>Ihre Einzahlung über 1250 wurde verbucht.</h2>")
+}
+
+func TestCheckHealth(t *testing.T) {
+	server := newSMTPServer(t, nil)
+	service := email.NewNotificationService(email.Config{Host: "127.0.0.1", Port: server.port(), From: "no-reply@finman.test", TLS: email.TLSNone}, directory.StaticUserDirectory{})
+	assert.NoError(t, service.CheckHealth(context.Background()))
+	assert.Equal(t, 0, len(server.received()))
+
+	service = email.NewNotificationService(email.Config{Host: "127.0.0.1", Port: server.port(), From: "no-reply@finman.test"}, directory.StaticUserDirectory{})
+	assert.ErrorIs(t, service.CheckHealth(context.Background()), email.ErrStartTLSUnsupported)
+}
@@ -0,0 +1,106 @@
+package email
+
+import (
+	htmlTemplate "html/template"
+	textTemplate "text/template"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+// Template renders the subject and both bodies of an email about an event.
+type Template struct {
+	Subject *textTemplate.Template
+	Text    *textTemplate.Template
+	HTML    *htmlTemplate.Template
+}
+
+// NewTemplate parses a template, it panics on syntax errors like template.Must.
+func NewTemplate(subject, text, html string) Template {
+	return Template{
+		Subject: textTemplate.Must(textTemplate.New("subject").Parse(subject)),
+		Text:    textTemplate.Must(textTemplate.New("text").Parse(text)),
+		HTML:    htmlTemplate.Must(htmlTemplate.New("html").Parse(html)),
+	}
+}
+
+// templateData is what templates are rendered with. Message is the localized
+// notification text, empty when none was rendered. Digest is only set for
+// daily digests, whose Date is the day they summarize.
+type templateData struct {
+	Event       model.TransactionEvent
+	Transaction model.Transaction
+	Digest      *model.DailyDigest
+	Date        string
+	Message     string
+}
+
+const textFooter = `
+You receive this email because notifications are enabled for your finman account.
+`
+
+const htmlLayout = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif">
+<h2>{{ template "title" . }}</h2>
+<table>
+<tr><td>Type</td><td>{{ .Transaction.Type }}</td></tr>
+<tr><td>Amount</td><td>{{ .Transaction.Amount }}</td></tr>
+<tr><td>Date</td><td>{{ .Date }}</td></tr>
+{{ if .Transaction.Description }}<tr><td>Description</td><td>{{ .Transaction.Description }}</td></tr>{{ end }}
+{{ if .Transaction.Id }}<tr><td>Reference</td><td>{{ .Transaction.Id }}</td></tr>{{ end }}
+</table>
+<p style="color: #888">You receive this email because notifications are enabled for your finman account.</p>
+</body>
+</html>
+`
+
+const textDetails = `
+Type: {{ .Transaction.Type }}
+Amount: {{ .Transaction.Amount }}
+Date: {{ .Date }}
+{{ if .Transaction.Description }}Description: {{ .Transaction.Description }}
+{{ end }}{{ if .Transaction.Id }}Reference: {{ .Transaction.Id }}
+{{ end }}`
+
+func defaultTemplate(subject, title string) Template {
+	title = `{{ if .Message }}{{ .Message }}{{ else }}` + title + `{{ end }}`
+	return NewTemplate(subject, title+"\n"+textDetails+textFooter, `{{ define "title" }}`+title+`{{ end }}`+htmlLayout)
+}
+
+const digestTitle = `{{ if .Message }}{{ .Message }}{{ else }}Your transactions on {{ .Date }}{{ end }}`
+
+const digestText = digestTitle + `
+
+Transactions: {{ .Digest.Count }}
+Total in: {{ .Digest.TotalIn }}
+Total out: {{ .Digest.TotalOut }}
+Closing balance: {{ .Digest.ClosingBalance }}
+{{ with .Digest.Largest }}Largest: {{ .Type }} of {{ .Amount }}
+{{ end }}` + textFooter
+
+const digestHTML = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif">
+<h2>` + digestTitle + `</h2>
+<table>
+<tr><td>Transactions</td><td>{{ .Digest.Count }}</td></tr>
+<tr><td>Total in</td><td>{{ .Digest.TotalIn }}</td></tr>
+<tr><td>Total out</td><td>{{ .Digest.TotalOut }}</td></tr>
+<tr><td>Closing balance</td><td>{{ .Digest.ClosingBalance }}</td></tr>
+{{ with .Digest.Largest }}<tr><td>Largest</td><td>{{ .Type }} of {{ .Amount }}</td></tr>{{ end }}
+</table>
+<p style="color: #888">You receive this email because the daily digest is enabled for your finman account.</p>
+</body>
+</html>
+`
+
+// DefaultTemplates are the templates used unless others are configured.
+var DefaultTemplates = map[model.TransactionEventType]Template{
+	model.TransactionCreated:  defaultTemplate("Your {{ .Transaction.Type }} of {{ .Transaction.Amount }} was recorded", "A new {{ .Transaction.Type }} was recorded."),
+	model.TransactionUpdated:  defaultTemplate("Your transaction was updated", "One of your transactions was updated."),
+	model.TransactionDeleted:  defaultTemplate("Your transaction was deleted", "One of your transactions was deleted."),
+	model.InsufficientBalance: defaultTemplate("Withdrawal of {{ .Transaction.Amount }} declined", "A withdrawal was declined because your balance is insufficient."),
+	model.TransactionDigest:   NewTemplate("Your transactions on {{ .Date }}", digestText, digestHTML),
+	model.LowBalance:          defaultTemplate("Your balance dropped below {{ .Event.Alert.Threshold }}", "Your balance is {{ .Event.Alert.Balance }}, below your alert threshold of {{ .Event.Alert.Threshold }}."),
+	model.LargeTransaction:    defaultTemplate("Large {{ .Transaction.Type }} of {{ .Transaction.Amount }}", "A {{ .Transaction.Type }} exceeds your alert amount of {{ .Event.Alert.Threshold }}."),
+}
@@ -0,0 +1,124 @@
+package event
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+const defaultSubscriberBuffer = 256
+
+// Hub fans transaction events out to the subscribers of this process.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan model.TransactionEvent]struct{}
+	bufferSize  int
+	done        chan struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[string]map[chan model.TransactionEvent]struct{}),
+		bufferSize:  defaultSubscriberBuffer,
+		done:        make(chan struct{}),
+	}
+}
+
+// Subscribe registers a subscriber for the events of a user until ctx is done.
+func (h *Hub) Subscribe(ctx context.Context, userId string) (<-chan model.TransactionEvent, error) {
+	events := make(chan model.TransactionEvent, h.bufferSize)
+
+	h.mu.Lock()
+	if h.isClosed() {
+		h.mu.Unlock()
+		close(events)
+		return events, nil
+	}
+	if h.subscribers[userId] == nil {
+		h.subscribers[userId] = make(map[chan model.TransactionEvent]struct{})
+	}
+	h.subscribers[userId][events] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.unsubscribe(userId, events)
+	}()
+
+	return events, nil
+}
+
+// Publish hands an event to every subscriber of its user without blocking. A
+// subscriber whose buffer is full is dropped, it can resume from its last event.
+func (h *Hub) Publish(event model.TransactionEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for events := range h.subscribers[event.UserId] {
+		select {
+		case events <- event:
+		default:
+			h.remove(event.UserId, events)
+		}
+	}
+}
+
+// HasSubscribers reports whether a user has any subscriber.
+func (h *Hub) HasSubscribers(userId string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subscribers[userId]) > 0
+}
+
+// Close closes the channels of all subscribers, and of those subscribing
+// later, so their streams end and can resume on another replica.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.isClosed() {
+		return
+	}
+	close(h.done)
+	for userId, subscribers := range h.subscribers {
+		for events := range subscribers {
+			h.remove(userId, events)
+		}
+	}
+}
+
+// Done is closed by Close.
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
+
+// isClosed reports whether Close was called, the caller must hold the lock.
+func (h *Hub) isClosed() bool {
+	select {
+	case <-h.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *Hub) unsubscribe(userId string, events chan model.TransactionEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(userId, events)
+}
+
+// remove closes and forgets a subscriber, the caller must hold the lock.
+func (h *Hub) remove(userId string, events chan model.TransactionEvent) {
+	if _, ok := h.subscribers[userId][events]; !ok {
+		return
+	}
+	delete(h.subscribers[userId], events)
+	if len(h.subscribers[userId]) == 0 {
+		delete(h.subscribers, userId)
+	}
+	close(events)
+}
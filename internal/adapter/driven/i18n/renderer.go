@@ -0,0 +1,136 @@
+package i18n
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+// Renderer renders notifications from templates stored as
+// <locale>/<event type>.tmpl, each defining a "subject" and a "message"
+// template. Locales fall back to their language and then to English.
+type Renderer struct {
+	templates map[string]*template.Template
+}
+
+// templateData is what templates are rendered with. Digest is only set for
+// daily digests, whose Date is the day they summarize.
+type templateData struct {
+	Event       model.TransactionEvent
+	Transaction model.Transaction
+	Digest      *model.DailyDigest
+	Date        string
+}
+
+// NewRenderer creates a renderer with the built-in templates.
+func NewRenderer() (*Renderer, error) {
+	return LoadRenderer(nil)
+}
+
+// LoadRenderer creates a renderer with the built-in templates, replaced or
+// extended by the templates in fsys.
+func LoadRenderer(fsys fs.FS) (*Renderer, error) {
+	r := &Renderer{templates: make(map[string]*template.Template)}
+
+	builtIn, err := fs.Sub(defaultTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(builtIn); err != nil {
+		return nil, err
+	}
+	if fsys != nil {
+		if err := r.load(fsys); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *Renderer) load(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*/*.tmpl")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		parsed, err := template.New(file).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return err
+		}
+		if parsed.Lookup("subject") == nil || parsed.Lookup("message") == nil {
+			return fmt.Errorf("template %s must define subject and message", file)
+		}
+
+		locale := strings.ToLower(path.Dir(file))
+		eventType := strings.TrimSuffix(path.Base(file), ".tmpl")
+		r.templates[key(locale, model.TransactionEventType(eventType))] = parsed
+	}
+	return nil
+}
+
+func (r *Renderer) RenderNotification(event model.TransactionEvent, locale, timeZone string) (string, string, error) {
+	location := time.UTC
+	if timeZone != "" {
+		if loaded, err := time.LoadLocation(timeZone); err == nil {
+			location = loaded
+		}
+	}
+	return r.render(event.Type, locale, templateData{
+		Event:       event,
+		Transaction: event.Transaction,
+		Date:        event.Transaction.Date.In(location).Format("2006-01-02 15:04 MST"),
+	})
+}
+
+func (r *Renderer) RenderDigest(digest model.DailyDigest, locale string) (string, string, error) {
+	return r.render(model.TransactionDigest, locale, templateData{
+		Event:  model.TransactionEvent{Type: model.TransactionDigest, UserId: digest.UserId},
+		Digest: &digest,
+		Date:   digest.Day,
+	})
+}
+
+func (r *Renderer) render(eventType model.TransactionEventType, locale string, data templateData) (string, string, error) {
+	template, err := r.lookup(eventType, locale)
+	if err != nil {
+		return "", "", err
+	}
+
+	var subject, message bytes.Buffer
+	if err := template.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := template.ExecuteTemplate(&message, "message", data); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(subject.String()), strings.TrimSpace(message.String()), nil
+}
+
+func (r *Renderer) lookup(eventType model.TransactionEventType, locale string) (*template.Template, error) {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	language, _, _ := strings.Cut(locale, "-")
+	for _, candidate := range []string{locale, language, model.DefaultLocale} {
+		if template, ok := r.templates[key(candidate, eventType)]; ok {
+			return template, nil
+		}
+	}
+	return nil, fmt.Errorf("no notification template for %s events", eventType)
+}
+
+func key(locale string, eventType model.TransactionEventType) string {
+	return locale + "/" + string(eventType)
+}
@@ -0,0 +1,87 @@
+package i18n_test
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/i18n"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var event = model.TransactionEvent{
+	Type: model.InsufficientBalance,
+	Transaction: model.Transaction{
+		Type:   "withdrawal",
+		Amount: 300,
+		Date:   time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+	},
+}
+
+func TestRenderNotification(t *testing.T) {
+	renderer, err := i18n.NewRenderer()
+	assert.NoError(t, err)
+
+	subject, message, err := renderer.RenderNotification(event, "en", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Withdrawal of 300 declined", subject)
+	assert.Equal(t, "A withdrawal of 300 on 2024-01-15 08:00 UTC was declined because your balance is insufficient.", message)
+
+	// Regional locales fall back to their language, dates use the time zone
+	subject, message, err = renderer.RenderNotification(event, "de_CH", "Europe/Zurich")
+	assert.NoError(t, err)
+	assert.Equal(t, "Auszahlung über 300 abgelehnt", subject)
+	assert.Contains(t, message, "2024-01-15 09:00 CET")
+
+	// Unknown locales fall back to English
+	subject, _, err = renderer.RenderNotification(event, "xx", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Withdrawal of 300 declined", subject)
+}
+
+func TestLoadRenderer(t *testing.T) {
+	renderer, err := i18n.LoadRenderer(fstest.MapFS{
+		"fr/insufficient_balance.tmpl": {Data: []byte(`{{ define "subject" }}Retrait de {{ .Transaction.Amount }} refusé{{ end }}{{ define "message" }}Solde insuffisant.{{ end }}`)},
+		"en/insufficient_balance.tmpl": {Data: []byte(`{{ define "subject" }}Declined{{ end }}{{ define "message" }}Not enough money.{{ end }}`)},
+	})
+	assert.NoError(t, err)
+
+	subject, message, err := renderer.RenderNotification(event, "fr-CA", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Retrait de 300 refusé", subject)
+	assert.Equal(t, "Solde insuffisant.", message)
+
+	subject, _, err = renderer.RenderNotification(event, "en", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Declined", subject)
+
+	// Built-in templates of other events remain
+	created := event
+	created.Type = model.TransactionCreated
+	_, _, err = renderer.RenderNotification(created, "fr", "")
+	assert.NoError(t, err)
+
+	_, err = i18n.LoadRenderer(fstest.MapFS{"en/created.tmpl": {Data: []byte(`{{ define "subject" }}Hi{{ end }}`)}})
+	assert.Error(t, err)
+}
+
+func TestRenderDigest(t *testing.T) {
+	renderer, err := i18n.NewRenderer()
+	assert.NoError(t, err)
+
+	digest := model.DailyDigest{
+		Day: "2024-01-15",
+		TransactionSummary: model.TransactionSummary{
+			Count:          1,
+			TotalIn:        0,
+			TotalOut:       300,
+			ClosingBalance: 700,
+			Largest:        &model.Transaction{Type: "withdrawal", Amount: 300},
+		},
+	}
+	subject, message, err := renderer.RenderDigest(digest, "de-DE")
+	assert.NoError(t, err)
+	assert.Equal(t, "Ihre Umsätze am 2024-01-15", subject)
+	assert.Equal(t, "1 Umsatz am 2024-01-15: 0 Eingänge, 300 Ausgänge. Schlusssaldo: 700. Größter Umsatz: Auszahlung über 300.", message)
+}
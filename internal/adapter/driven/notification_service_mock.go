@@ -2,7 +2,9 @@ package notification
 
 import (
 	"context"
-	"log"
+	"log/slog"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
 )
 
 // NotificationServiceMock is a mock implementation of NotificationService
@@ -14,7 +16,7 @@ func NewMockNotificationService() *NotificationServiceMock {
 }
 
 // SendTransactionNotification logs the notification details instead of sending an actual notification
-func (m *NotificationServiceMock) SendTransactionNotification(ctx context.Context, userId, message string) error {
-	log.Printf("Mock notification sent to user %s: %s\n", userId, message)
+func (m *NotificationServiceMock) SendTransactionNotification(ctx context.Context, notification model.Notification) error {
+	slog.InfoContext(ctx, "mock notification sent", "user_id", notification.Event.UserId, "event_id", notification.Event.Id)
 	return nil
 }
@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicAddress(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.215.14":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00:ec2::254":   false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::ffff:10.0.0.1": false,
+		"224.0.0.1":       false,
+	} {
+		assert.Equal(t, public, publicAddress(netip.MustParseAddr(address)), address)
+	}
+}
+
+func TestPublicClientRefusesPrivateAddressesAndRedirects(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/internal", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	client := newPublicClient(time.Second)
+	request, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, nil)
+	_, err := client.Do(request)
+	assert.True(t, errors.Is(err, ErrNonPublicAddress))
+	assert.Equal(t, 0, calls)
+
+	// Redirects are not followed, even to an address that would be allowed
+	client.Transport = http.DefaultTransport
+	request, _ = http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL+"/redirect", nil)
+	response, err := client.Do(request)
+	assert.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusFound, response.StatusCode)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,239 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+// Endpoint is a URL notifications are posted to, signed with Secret and, while
+// a rotation is in progress, PreviousSecret. Deliveries to endpoints with an Id
+// are reported to the DeliveryRecorder.
+type Endpoint struct {
+	Id             string `json:"id,omitempty"`
+	URL            string `json:"url"`
+	Secret         string `json:"secret"`
+	PreviousSecret string `json:"-"`
+}
+
+// EndpointResolver returns the endpoints that receive events of a type of a user.
+type EndpointResolver interface {
+	ResolveEndpoints(ctx context.Context, userId string, eventType model.TransactionEventType) ([]Endpoint, error)
+}
+
+// DeliveryRecorder stores the outcome of every delivery attempt.
+type DeliveryRecorder interface {
+	RecordDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+}
+
+// Resolvers combines the endpoints of several resolvers.
+type Resolvers []EndpointResolver
+
+func (r Resolvers) ResolveEndpoints(ctx context.Context, userId string, eventType model.TransactionEventType) ([]Endpoint, error) {
+	var endpoints []Endpoint
+	for _, resolver := range r {
+		resolved, err := resolver.ResolveEndpoints(ctx, userId, eventType)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, resolved...)
+	}
+	return endpoints, nil
+}
+
+// AllUsers is the StaticEndpoints key of endpoints receiving notifications of every user.
+const AllUsers = "*"
+
+// StaticEndpoints resolves endpoints from configuration, keyed by user id.
+// They receive events of every type.
+type StaticEndpoints map[string][]Endpoint
+
+func (e StaticEndpoints) ResolveEndpoints(ctx context.Context, userId string, eventType model.TransactionEventType) ([]Endpoint, error) {
+	endpoints := append([]Endpoint{}, e[userId]...)
+	return append(endpoints, e[AllUsers]...), nil
+}
+
+// Payload is the JSON body posted for a transaction event.
+type Payload struct {
+	Id          string                     `json:"id"`
+	Type        model.TransactionEventType `json:"type"`
+	UserId      string                     `json:"userId"`
+	OccurredAt  time.Time                  `json:"occurredAt"`
+	Transaction model.Transaction          `json:"transaction"`
+	// Digest is only set for daily_digest events.
+	Digest *model.DailyDigest `json:"digest,omitempty"`
+	// Message is the notification rendered in the language of the user.
+	Message string `json:"message,omitempty"`
+}
+
+// StatusError is returned when an endpoint answers with a non 2xx status.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook responded with status %d", e.StatusCode)
+}
+
+// NotificationService posts signed transaction events to webhook endpoints.
+// Every call makes a single request to each endpoint, concurrently, so a slow
+// endpoint delays a notification by one timeout at most. Failed deliveries are
+// retried with the notification by the outbox.
+type NotificationService struct {
+	resolver EndpointResolver
+	recorder DeliveryRecorder
+	client   *http.Client
+	now      func() time.Time
+}
+
+// Option configures a NotificationService.
+type Option func(*NotificationService)
+
+// WithHTTPClient replaces the HTTP client, its Timeout applies to every
+// attempt. Unlike the default client it may connect to any address.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *NotificationService) {
+		s.client = client
+	}
+}
+
+// WithDeliveryRecorder reports every attempt to recorder.
+func WithDeliveryRecorder(recorder DeliveryRecorder) Option {
+	return func(s *NotificationService) {
+		s.recorder = recorder
+	}
+}
+
+// WithTimeout limits how long a single attempt may take.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *NotificationService) {
+		s.client.Timeout = timeout
+	}
+}
+
+// WithClock replaces the clock used for the timestamp header.
+func WithClock(now func() time.Time) Option {
+	return func(s *NotificationService) {
+		s.now = now
+	}
+}
+
+func NewNotificationService(resolver EndpointResolver, options ...Option) *NotificationService {
+	s := &NotificationService{
+		resolver: resolver,
+		client:   newPublicClient(10 * time.Second),
+		now:      time.Now,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// SendTransactionNotification posts the event to every endpoint of the user. The
+// error joins the failures of all endpoints; since the caller retries the
+// whole notification, endpoints must tolerate duplicates.
+func (s *NotificationService) SendTransactionNotification(ctx context.Context, notification model.Notification) error {
+	event := notification.Event
+	endpoints, err := s.resolver.ResolveEndpoints(ctx, event.UserId, event.Type)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(Payload{
+		Id:          event.Id,
+		Type:        event.Type,
+		UserId:      event.UserId,
+		OccurredAt:  event.OccurredAt,
+		Transaction: event.Transaction,
+		Digest:      notification.Digest,
+		Message:     notification.Message,
+	})
+	if err != nil {
+		return err
+	}
+
+	attempt := max(notification.Attempt, 1)
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			startedAt := s.now()
+			statusCode, err := s.post(ctx, endpoint, event.Id, body)
+			s.record(ctx, endpoint, event, attempt, statusCode, startedAt, err)
+			if err != nil {
+				errs[i] = fmt.Errorf("webhook %s: %w", endpoint.URL, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (s *NotificationService) record(ctx context.Context, endpoint Endpoint, event model.TransactionEvent, attempt, statusCode int, startedAt time.Time, err error) {
+	if s.recorder == nil || endpoint.Id == "" {
+		return
+	}
+
+	delivery := model.WebhookDelivery{
+		SubscriptionId: endpoint.Id,
+		EventId:        event.Id,
+		EventType:      event.Type,
+		Attempt:        attempt,
+		StatusCode:     statusCode,
+		Latency:        s.now().Sub(startedAt),
+		AttemptedAt:    startedAt,
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if err := s.recorder.RecordDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		slog.ErrorContext(ctx, "failed to record webhook delivery", "subscription_id", endpoint.Id, "error", err)
+	}
+}
+
+// post sends a single request and returns the response status, zero when no response was received.
+func (s *NotificationService) post(ctx context.Context, endpoint Endpoint, eventId string, body []byte) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	// Every attempt is signed again so retries are not rejected as stale
+	timestamp := s.now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventIdHeader, eventId)
+	request.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	signature := Sign(endpoint.Secret, timestamp, body)
+	if endpoint.PreviousSecret != "" {
+		signature += "," + Sign(endpoint.PreviousSecret, timestamp, body)
+	}
+	request.Header.Set(SignatureHeader, signature)
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, &StatusError{StatusCode: response.StatusCode}
+	}
+	return response.StatusCode, nil
+}
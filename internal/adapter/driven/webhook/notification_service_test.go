@@ -0,0 +1,239 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/webhook"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const secret = "whsec_test"
+
+func newEvent() model.TransactionEvent {
+	userId := uuid.New().String()
+	return model.TransactionEvent{
+		Id:         uuid.New().String(),
+		Type:       model.TransactionCreated,
+		UserId:     userId,
+		OccurredAt: time.Now().UTC().Truncate(time.Second),
+		Transaction: model.Transaction{
+			Id:     uuid.New().String(),
+			UserId: userId,
+			Type:   "deposit",
+			Amount: 100,
+		},
+	}
+}
+
+func TestSendTransactionNotificationSignsPayload(t *testing.T) {
+	var mu sync.Mutex
+	var received []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	event := newEvent()
+	service := webhook.NewNotificationService(webhook.StaticEndpoints{event.UserId: {{URL: server.URL, Secret: secret}}}, webhook.WithHTTPClient(server.Client()))
+
+	err := service.SendTransactionNotification(context.Background(), model.Notification{Event: event, Message: "Deposit of 100 recorded"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(received))
+
+	request, body := received[0], bodies[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.Equal(t, event.Id, request.Header.Get(webhook.EventIdHeader))
+
+	signature, timestamp := request.Header.Get(webhook.SignatureHeader), request.Header.Get(webhook.TimestampHeader)
+	assert.NoError(t, webhook.Verify(secret, signature, timestamp, body, 5*time.Minute, time.Now()))
+	assert.ErrorIs(t, webhook.Verify("other", signature, timestamp, body, 5*time.Minute, time.Now()), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify(secret, signature, timestamp, append(body, ' '), 5*time.Minute, time.Now()), webhook.ErrInvalidSignature)
+	// Replays are rejected once the timestamp is outside the tolerance
+	assert.ErrorIs(t, webhook.Verify(secret, signature, timestamp, body, 5*time.Minute, time.Now().Add(time.Hour)), webhook.ErrTimestampExpired)
+
+	var payload webhook.Payload
+	assert.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, event.Id, payload.Id)
+	assert.Equal(t, model.TransactionCreated, payload.Type)
+	assert.Equal(t, event.UserId, payload.UserId)
+	assert.Equal(t, event.Transaction.Id, payload.Transaction.Id)
+	assert.Equal(t, int64(100), payload.Transaction.Amount)
+	assert.Equal(t, "Deposit of 100 recorded", payload.Message)
+
+	// Users without endpoints are skipped
+	err = service.SendTransactionNotification(context.Background(), model.Notification{Event: newEvent()})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(received))
+}
+
+func TestSendTransactionNotificationMakesASingleAttempt(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	event := newEvent()
+	service := webhook.NewNotificationService(webhook.StaticEndpoints{webhook.AllUsers: {{URL: server.URL, Secret: secret}}}, webhook.WithHTTPClient(server.Client()))
+
+	// Failures are left to the outbox to retry
+	err := service.SendTransactionNotification(context.Background(), model.Notification{Event: event, Attempt: 1})
+	var statusErr *webhook.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	err = service.SendTransactionNotification(context.Background(), model.Notification{Event: event, Attempt: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestSendTransactionNotificationTimesOut(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	service := webhook.NewNotificationService(webhook.StaticEndpoints{webhook.AllUsers: {{URL: server.URL, Secret: secret}}},
+		webhook.WithHTTPClient(server.Client()), webhook.WithTimeout(50*time.Millisecond))
+
+	err := service.SendTransactionNotification(context.Background(), model.Notification{Event: newEvent()})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestSendTransactionNotificationReportsEveryEndpoint(t *testing.T) {
+	var delivered atomic.Int32
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+	}))
+	defer ok.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer failing.Close()
+
+	event := newEvent()
+	service := webhook.NewNotificationService(webhook.StaticEndpoints{
+		event.UserId:     {{URL: failing.URL, Secret: secret}},
+		webhook.AllUsers: {{URL: ok.URL, Secret: secret}},
+	}, webhook.WithHTTPClient(&http.Client{}))
+
+	err := service.SendTransactionNotification(context.Background(), model.Notification{Event: event})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), failing.URL)
+	assert.Equal(t, int32(1), delivered.Load())
+}
+
+func TestSubscriptionsDeliverAndRecord(t *testing.T) {
+	var mu sync.Mutex
+	var signatures []string
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		signatures = append(signatures, r.Header.Get(webhook.SignatureHeader))
+		mu.Unlock()
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	event := newEvent()
+	repo := repository.NewInMemoryWebhookRepository()
+	expiresAt := time.Now().Add(time.Hour)
+	id, _ := repo.CreateSubscription(ctx, model.WebhookSubscription{
+		UserId:                  event.UserId,
+		Url:                     server.URL,
+		Secret:                  "new",
+		PreviousSecret:          "old",
+		PreviousSecretExpiresAt: &expiresAt,
+		EventTypes:              []model.TransactionEventType{model.TransactionCreated},
+		Status:                  model.WebhookActive,
+	})
+	_, _ = repo.CreateSubscription(ctx, model.WebhookSubscription{
+		UserId:     event.UserId,
+		Url:        server.URL,
+		Secret:     secret,
+		EventTypes: []model.TransactionEventType{model.TransactionDeleted},
+		Status:     model.WebhookActive,
+	})
+
+	subscriptions := webhook.NewSubscriptions(repo)
+	service := webhook.NewNotificationService(subscriptions, webhook.WithHTTPClient(server.Client()), webhook.WithDeliveryRecorder(subscriptions))
+
+	// The outbox attempts the notification again after the first failed
+	err := service.SendTransactionNotification(ctx, model.Notification{Event: event, Attempt: 1})
+	assert.Error(t, err)
+	err = service.SendTransactionNotification(ctx, model.Notification{Event: event, Attempt: 2})
+	assert.NoError(t, err)
+	// Only the subscription for created events was called, signed with both secrets
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Regexp(t, `^v1=[0-9a-f]{64},v1=[0-9a-f]{64}$`, signatures[0])
+
+	deliveries, err := repo.GetDeliveries(ctx, id, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(deliveries))
+	assert.Equal(t, 2, deliveries[0].Attempt)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+	assert.True(t, deliveries[0].Succeeded())
+	assert.Equal(t, event.Id, deliveries[0].EventId)
+	assert.Equal(t, 1, deliveries[1].Attempt)
+	assert.Equal(t, http.StatusInternalServerError, deliveries[1].StatusCode)
+	assert.False(t, deliveries[1].Succeeded())
+}
+
+func TestSendTransactionNotificationDeliversToEndpointsConcurrently(t *testing.T) {
+	fastCalled := make(chan struct{})
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(fastCalled)
+	}))
+	defer fast.Close()
+	var waited atomic.Bool
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-fastCalled:
+			waited.Store(true)
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	event := newEvent()
+	service := webhook.NewNotificationService(webhook.StaticEndpoints{
+		event.UserId:     {{URL: slow.URL, Secret: secret}},
+		webhook.AllUsers: {{URL: fast.URL, Secret: secret}},
+	}, webhook.WithHTTPClient(&http.Client{}))
+
+	// The slow endpoint does not hold back the delivery to the fast one
+	assert.NoError(t, service.SendTransactionNotification(context.Background(), model.Notification{Event: event}))
+	assert.True(t, waited.Load())
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carries one or more comma separated "v1=<hex>" signatures.
+	SignatureHeader = "X-Finman-Signature"
+	// TimestampHeader carries the unix time the request was signed at.
+	TimestampHeader = "X-Finman-Timestamp"
+	// EventIdHeader carries the event id, receivers should use it to drop duplicates.
+	EventIdHeader = "X-Finman-Event-Id"
+
+	signatureVersion = "v1="
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrTimestampExpired = errors.New("webhook timestamp is outside the tolerance")
+)
+
+// Sign computes the signature of body sent at timestamp. The timestamp is
+// part of the signed content so a captured request cannot be replayed later.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the headers of a received webhook against secret. Requests
+// signed more than tolerance away from now are rejected.
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) error {
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(sentAt, 0)); age > tolerance || age < -tolerance {
+		return ErrTimestampExpired
+	}
+
+	expected := Sign(secret, sentAt, body)
+	for _, candidate := range strings.Split(signature, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(candidate)), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
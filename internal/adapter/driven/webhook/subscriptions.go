@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+// Subscriptions resolves endpoints from the webhook subscriptions users
+// registered and records the deliveries to them.
+type Subscriptions struct {
+	repository repository.WebhookRepository
+	now        func() time.Time
+}
+
+func NewSubscriptions(repository repository.WebhookRepository) *Subscriptions {
+	return &Subscriptions{repository: repository, now: time.Now}
+}
+
+func (s *Subscriptions) ResolveEndpoints(ctx context.Context, userId string, eventType model.TransactionEventType) ([]Endpoint, error) {
+	subscriptions, err := s.repository.GetActiveSubscriptions(ctx, userId, eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []Endpoint
+	for _, subscription := range subscriptions {
+		endpoint := Endpoint{Id: subscription.Id, URL: subscription.Url, Secret: subscription.Secret}
+		if secrets := subscription.Secrets(s.now()); len(secrets) > 1 {
+			endpoint.PreviousSecret = secrets[1]
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+func (s *Subscriptions) RecordDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	return s.repository.RecordDelivery(ctx, delivery)
+}
@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	transactionv1 "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/proto/transaction/v1"
+	"github.com/nullexp/finman-transaction-service/internal/port/driver"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+type AlertSettingsService struct {
+	transactionv1.UnimplementedAlertSettingsServiceServer
+	service driver.AlertSettingsService
+}
+
+func NewAlertSettingsService(as driver.AlertSettingsService) *AlertSettingsService {
+	return &AlertSettingsService{service: as}
+}
+
+func CastAlertSettingsToProto(settings *model.AlertSettings) *transactionv1.AlertSettings {
+	protoSettings := &transactionv1.AlertSettings{
+		UserId:                 settings.UserId,
+		LowBalanceThreshold:    settings.LowBalanceThreshold,
+		LargeTransactionAmount: settings.LargeTransactionAmount,
+	}
+	if !settings.UpdatedAt.IsZero() {
+		protoSettings.UpdatedAt = settings.UpdatedAt.Format(time.RFC3339)
+	}
+	return protoSettings
+}
+
+func (as AlertSettingsService) GetAlertSettings(ctx context.Context, request *transactionv1.GetAlertSettingsRequest) (*transactionv1.GetAlertSettingsResponse, error) {
+	rs, err := as.service.GetAlertSettings(ctx, model.GetAlertSettingsRequest{UserId: ownUserId(ctx, request.UserId)})
+	if err != nil {
+		return nil, toStatus(ctx, "GetAlertSettings", err)
+	}
+
+	return &transactionv1.GetAlertSettingsResponse{Settings: CastAlertSettingsToProto(&rs.Settings)}, nil
+}
+
+func (as AlertSettingsService) UpdateAlertSettings(ctx context.Context, request *transactionv1.UpdateAlertSettingsRequest) (*transactionv1.UpdateAlertSettingsResponse, error) {
+	if request.Settings == nil {
+		return nil, invalidArgument("settings", "is required")
+	}
+
+	rs, err := as.service.UpdateAlertSettings(ctx, model.UpdateAlertSettingsRequest{Settings: model.AlertSettings{
+		UserId:                 ownUserId(ctx, request.Settings.UserId),
+		LowBalanceThreshold:    request.Settings.LowBalanceThreshold,
+		LargeTransactionAmount: request.Settings.LargeTransactionAmount,
+	}})
+	if err != nil {
+		return nil, toStatus(ctx, "UpdateAlertSettings", err)
+	}
+
+	return &transactionv1.UpdateAlertSettingsResponse{Settings: CastAlertSettingsToProto(&rs.Settings)}, nil
+}
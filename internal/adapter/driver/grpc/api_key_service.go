@@ -0,0 +1,109 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	transactionv1 "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/proto/transaction/v1"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driver"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+var apiKeyScopes = map[string]transactionv1.ApiKeyScope{
+	string(domainModel.ScopeTransactionsRead):  transactionv1.ApiKeyScope_API_KEY_SCOPE_TRANSACTIONS_READ,
+	string(domainModel.ScopeTransactionsWrite): transactionv1.ApiKeyScope_API_KEY_SCOPE_TRANSACTIONS_WRITE,
+	string(domainModel.ScopeAdmin):             transactionv1.ApiKeyScope_API_KEY_SCOPE_ADMIN,
+}
+
+type ApiKeyService struct {
+	transactionv1.UnimplementedApiKeyServiceServer
+	service driver.ApiKeyService
+}
+
+func NewApiKeyService(as driver.ApiKeyService) *ApiKeyService {
+	return &ApiKeyService{service: as}
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func CastApiKeyToProto(key *model.ApiKey) *transactionv1.ApiKey {
+	protoKey := &transactionv1.ApiKey{
+		Id:           key.Id,
+		Service:      key.Service,
+		CreatedAt:    key.CreatedAt.Format(time.RFC3339),
+		RevokedAt:    formatOptionalTime(key.RevokedAt),
+		LastUsedAt:   formatOptionalTime(key.LastUsedAt),
+		RequestCount: key.RequestCount,
+	}
+	for _, scope := range key.Scopes {
+		protoKey.Scopes = append(protoKey.Scopes, apiKeyScopes[scope])
+	}
+	return protoKey
+}
+
+func (as ApiKeyService) CreateApiKey(ctx context.Context, request *transactionv1.CreateApiKeyRequest) (*transactionv1.CreateApiKeyResponse, error) {
+	var scopes []string
+	for _, protoScope := range request.Scopes {
+		found := false
+		for scope, value := range apiKeyScopes {
+			if value == protoScope {
+				scopes = append(scopes, scope)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, invalidArgument("scopes", "unsupported scope "+protoScope.String())
+		}
+	}
+
+	rs, err := as.service.CreateApiKey(ctx, model.CreateApiKeyRequest{Service: request.Service, Scopes: scopes})
+	if err != nil {
+		return nil, toStatus(ctx, "CreateApiKey", err)
+	}
+
+	return &transactionv1.CreateApiKeyResponse{ApiKey: CastApiKeyToProto(&rs.ApiKey), Key: rs.Key}, nil
+}
+
+func (as ApiKeyService) ListApiKeys(ctx context.Context, request *transactionv1.ListApiKeysRequest) (*transactionv1.ListApiKeysResponse, error) {
+	rs, err := as.service.ListApiKeys(ctx, model.ListApiKeysRequest{Service: request.Service})
+	if err != nil {
+		return nil, toStatus(ctx, "ListApiKeys", err)
+	}
+
+	response := &transactionv1.ListApiKeysResponse{}
+	for _, key := range rs.ApiKeys {
+		response.ApiKeys = append(response.ApiKeys, CastApiKeyToProto(&key))
+	}
+	return response, nil
+}
+
+func (as ApiKeyService) RevokeApiKey(ctx context.Context, request *transactionv1.RevokeApiKeyRequest) (*transactionv1.RevokeApiKeyResponse, error) {
+	rs, err := as.service.RevokeApiKey(ctx, model.RevokeApiKeyRequest{Id: request.Id})
+	if err != nil {
+		return nil, toStatus(ctx, "RevokeApiKey", err)
+	}
+
+	return &transactionv1.RevokeApiKeyResponse{ApiKey: CastApiKeyToProto(rs)}, nil
+}
+
+// AuthenticateApiKey implements auth.ApiKeyAuthenticator.
+func (as ApiKeyService) AuthenticateApiKey(ctx context.Context, key string) (*auth.Principal, error) {
+	rs, err := as.service.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: key})
+	if err != nil {
+		return nil, toStatus(ctx, "AuthenticateApiKey", err)
+	}
+
+	principal := &auth.Principal{ApiKeyId: rs.Id, Service: rs.Service}
+	for _, scope := range rs.Scopes {
+		principal.Scopes = append(principal.Scopes, domainModel.ApiKeyScope(scope))
+	}
+	return principal, nil
+}
@@ -0,0 +1,16 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+)
+
+// ownUserId returns the user an "own" RPC acts for: the authenticated user,
+// or the user id of the request when the server runs without authentication.
+func ownUserId(ctx context.Context, requested string) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		return principal.UserId
+	}
+	return requested
+}
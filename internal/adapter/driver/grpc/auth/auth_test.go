@@ -0,0 +1,376 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	issuer   = "https://auth.example.com"
+	audience = "finman-transaction-service"
+	userId   = "0d9c5f6e-3b8a-4d0b-9a63-2f4d2f0b7c11"
+)
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return testKeys{rsa: rsaKey, ec: ecKey}
+}
+
+func (k testKeys) jwks(t *testing.T) []byte {
+	encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	data, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encode(k.rsa.N), "e": encode(big.NewInt(int64(k.rsa.E)))},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(k.ec.X), "y": encode(k.ec.Y)},
+		{"kty": "RSA", "kid": "encryption", "use": "enc", "n": encode(k.rsa.N), "e": "AQAB"},
+	}})
+	require.NoError(t, err)
+	return data
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.RegisteredClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   userId,
+		Issuer:    issuer,
+		Audience:  jwt.ClaimStrings{audience},
+		IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+}
+
+func newVerifier(t *testing.T, keys testKeys) *auth.Verifier {
+	keySet, err := auth.ParseJWKS(keys.jwks(t))
+	require.NoError(t, err)
+	assert.Len(t, keySet, 2)
+	verifier, err := auth.NewVerifier(keySet, auth.WithIssuer(issuer), auth.WithAudience(audience), auth.WithVerifierClock(func() time.Time { return now }))
+	require.NoError(t, err)
+	return verifier
+}
+
+func TestVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := newVerifier(t, keys)
+
+	for _, token := range []string{
+		sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, validClaims()),
+		sign(t, jwt.SigningMethodES256, "ec", keys.ec, validClaims()),
+	} {
+		principal, err := verifier.Verify(token)
+		assert.NoError(t, err)
+		assert.Equal(t, userId, principal.UserId)
+	}
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+	withoutExpiry := validClaims()
+	withoutExpiry.ExpiresAt = nil
+	wrongAudience := validClaims()
+	wrongAudience.Audience = jwt.ClaimStrings{"another-service"}
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "https://evil.example.com"
+	withoutAudience := validClaims()
+	withoutAudience.Audience = nil
+	withoutIssuer := validClaims()
+	withoutIssuer.Issuer = ""
+	withoutSubject := validClaims()
+	withoutSubject.Subject = ""
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	invalid := map[string]string{
+		"expired":          sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, expired),
+		"without expiry":   sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, withoutExpiry),
+		"wrong audience":   sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, wrongAudience),
+		"wrong issuer":     sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, wrongIssuer),
+		"without audience": sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, withoutAudience),
+		"without issuer":   sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, withoutIssuer),
+		"without subject":  sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, withoutSubject),
+		"unknown key":      sign(t, jwt.SigningMethodRS256, "other", otherKey, validClaims()),
+		"forged":           sign(t, jwt.SigningMethodRS256, "rsa", otherKey, validClaims()),
+		"key mismatch":     sign(t, jwt.SigningMethodES256, "rsa", keys.ec, validClaims()),
+		"HS256":            sign(t, jwt.SigningMethodHS256, "rsa", []byte("secret"), validClaims()),
+		"without key id":   sign(t, jwt.SigningMethodRS256, "", keys.rsa, validClaims()),
+		"malformed":        "not.a.token",
+	}
+	for name, token := range invalid {
+		_, err := verifier.Verify(token)
+		assert.ErrorIs(t, err, auth.ErrInvalidToken, name)
+	}
+}
+
+func TestVerifierRequiresIssuerAndAudience(t *testing.T) {
+	keySet, err := auth.ParseJWKS(newTestKeys(t).jwks(t))
+	require.NoError(t, err)
+
+	_, err = auth.NewVerifier(keySet, auth.WithIssuer(issuer))
+	assert.Error(t, err)
+	_, err = auth.NewVerifier(keySet, auth.WithAudience(audience))
+	assert.Error(t, err)
+}
+
+func TestLoadPublicKeys(t *testing.T) {
+	keys := newTestKeys(t)
+	data, err := x509.MarshalPKIXPublicKey(&keys.ec.PublicKey)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "2024-06.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: data}), 0o600))
+
+	keySet, err := auth.LoadPublicKeys(path)
+	require.NoError(t, err)
+	assert.Contains(t, keySet, "2024-06")
+
+	// A single key also verifies tokens without a key id.
+	verifier, err := auth.NewVerifier(keySet, auth.WithIssuer(issuer), auth.WithAudience(audience), auth.WithVerifierClock(func() time.Time { return now }))
+	require.NoError(t, err)
+	principal, err := verifier.Verify(sign(t, jwt.SigningMethodES256, "", keys.ec, validClaims()))
+	assert.NoError(t, err)
+	assert.Equal(t, userId, principal.UserId)
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestInterceptor(t *testing.T) {
+	keys := newTestKeys(t)
+	interceptor := auth.NewInterceptor(newVerifier(t, keys), auth.WithPublicMethods("/grpc.reflection.v1.ServerReflection/"))
+	token := sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, validClaims())
+	withToken := func(value string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", value))
+	}
+
+	var principal auth.Principal
+	var authenticated bool
+	unary := func(ctx context.Context, req interface{}) (interface{}, error) {
+		principal, authenticated = auth.FromContext(ctx)
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/transaction.v1.TransactionService/GetOwnTransactionById"}
+
+	_, err := interceptor.Unary()(withToken("Bearer "+token), nil, info, unary)
+	assert.NoError(t, err)
+	assert.True(t, authenticated)
+	assert.Equal(t, userId, principal.UserId)
+
+	_, err = interceptor.Unary()(context.Background(), nil, info, unary)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = interceptor.Unary()(withToken("Basic "+token), nil, info, unary)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = interceptor.Unary()(withToken("Bearer "+token+"x"), nil, info, unary)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authenticated = false
+	_, err = interceptor.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"}, unary)
+	assert.NoError(t, err)
+	assert.False(t, authenticated)
+
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/transaction.v1.TransactionService/SubscribeTransactions", IsServerStream: true}
+	err = interceptor.Stream()(nil, testStream{ctx: withToken("bearer " + token)}, streamInfo, func(srv interface{}, stream grpc.ServerStream) error {
+		principal, authenticated = auth.FromContext(stream.Context())
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, authenticated)
+	assert.Equal(t, userId, principal.UserId)
+
+	err = interceptor.Stream()(nil, testStream{ctx: context.Background()}, streamInfo, func(srv interface{}, stream grpc.ServerStream) error {
+		return nil
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+type ownedRequest struct {
+	userId string
+}
+
+func (r *ownedRequest) GetUserId() string {
+	return r.userId
+}
+
+type requestStream struct {
+	testStream
+	userId string
+}
+
+func (s requestStream) RecvMsg(m interface{}) error {
+	m.(*ownedRequest).userId = s.userId
+	return nil
+}
+
+func TestInterceptorPolicy(t *testing.T) {
+	keys := newTestKeys(t)
+	policy := auth.Policy{
+		"/test.Service/Delete": {Roles: []auth.Role{auth.RoleAdmin}},
+		"/test.Service/Watch":  {Roles: []auth.Role{auth.RoleSupport}, Self: true},
+	}
+	interceptor := auth.NewInterceptor(newVerifier(t, keys), auth.WithPolicy(policy))
+	withRoles := func(roles ...string) context.Context {
+		claims := tokenClaims{RegisteredClaims: validClaims(), Roles: roles}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "rsa"
+		signed, err := token.SignedString(keys.rsa)
+		require.NoError(t, err)
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+signed))
+	}
+
+	var principal auth.Principal
+	unary := func(ctx context.Context, req interface{}) (interface{}, error) {
+		principal, _ = auth.FromContext(ctx)
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Delete"}
+
+	_, err := interceptor.Unary()(withRoles("support", "admin"), nil, info, unary)
+	assert.NoError(t, err)
+	assert.Equal(t, []auth.Role{auth.RoleSupport, auth.RoleAdmin}, principal.Roles)
+
+	_, err = interceptor.Unary()(withRoles("support"), nil, info, unary)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = interceptor.Unary()(withRoles(), nil, info, unary)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = interceptor.Unary()(withRoles("admin"), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Unknown"}, unary)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Streams are authorized once the handler received the request.
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch", IsServerStream: true}
+	watch := func(srv interface{}, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&ownedRequest{})
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, validClaims())))
+	assert.NoError(t, interceptor.Stream()(nil, requestStream{testStream{ctx: ctx}, userId}, streamInfo, watch))
+	err = interceptor.Stream()(nil, requestStream{testStream{ctx: ctx}, "another user"}, streamInfo, watch)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.NoError(t, interceptor.Stream()(nil, requestStream{testStream{ctx: withRoles("support")}, "another user"}, streamInfo, watch))
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+func TestInterceptorClientCertificates(t *testing.T) {
+	keys := newTestKeys(t)
+	policy := auth.Policy{
+		"/test.Service/Export": {Roles: []auth.Role{auth.RoleAuditor}},
+		"/test.Service/Own":    {Roles: []auth.Role{auth.RoleUser}},
+	}
+	interceptor := auth.NewInterceptor(newVerifier(t, keys), auth.WithPolicy(policy), auth.WithCertificateRoles(map[string][]auth.Role{
+		"spiffe://finman/reporting": {auth.RoleAuditor},
+	}))
+	fromClient := func(ctx context.Context, identity string) context.Context {
+		uri, err := url.Parse(identity)
+		require.NoError(t, err)
+		state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{URIs: []*url.URL{uri}}}}}
+		return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+	}
+
+	var principal auth.Principal
+	unary := func(ctx context.Context, req interface{}) (interface{}, error) {
+		principal, _ = auth.FromContext(ctx)
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Export"}
+
+	_, err := interceptor.Unary()(fromClient(context.Background(), "spiffe://finman/reporting"), nil, info, unary)
+	assert.NoError(t, err)
+	assert.Equal(t, auth.Principal{Roles: []auth.Role{auth.RoleAuditor}, ClientIdentity: "spiffe://finman/reporting"}, principal)
+
+	_, err = interceptor.Unary()(fromClient(context.Background(), "spiffe://finman/unknown"), nil, info, unary)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// A token takes precedence, the certificate identity is kept.
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+sign(t, jwt.SigningMethodRS256, "rsa", keys.rsa, validClaims())))
+	_, err = interceptor.Unary()(fromClient(ctx, "spiffe://finman/reporting"), nil, info, unary)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = interceptor.Unary()(fromClient(ctx, "spiffe://finman/reporting"), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Own"}, unary)
+	assert.NoError(t, err)
+	assert.Equal(t, auth.Principal{UserId: userId, Roles: []auth.Role{auth.RoleUser}, ClientIdentity: "spiffe://finman/reporting"}, principal)
+}
+
+type staticApiKeys map[string]auth.Principal
+
+func (k staticApiKeys) AuthenticateApiKey(ctx context.Context, key string) (*auth.Principal, error) {
+	principal, ok := k[key]
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "API key is invalid or revoked")
+	}
+	return &principal, nil
+}
+
+func TestInterceptorApiKeys(t *testing.T) {
+	keys := newTestKeys(t)
+	policy := auth.Policy{"/test.Service/Read": {Scopes: []model.ApiKeyScope{model.ScopeTransactionsRead}}}
+	interceptor := auth.NewInterceptor(newVerifier(t, keys), auth.WithPolicy(policy), auth.WithApiKeys(staticApiKeys{
+		"fmk_reader": {Service: "reporting", Scopes: []model.ApiKeyScope{model.ScopeTransactionsRead}},
+		"fmk_writer": {Service: "payouts", Scopes: []model.ApiKeyScope{model.ScopeTransactionsWrite}},
+	}))
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(auth.ApiKeyHeader, key))
+	}
+
+	var principal auth.Principal
+	unary := func(ctx context.Context, req interface{}) (interface{}, error) {
+		principal, _ = auth.FromContext(ctx)
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Read"}
+
+	_, err := interceptor.Unary()(withKey("fmk_reader"), nil, info, unary)
+	assert.NoError(t, err)
+	assert.Equal(t, "reporting", principal.Service)
+
+	_, err = interceptor.Unary()(withKey("fmk_writer"), nil, info, unary)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = interceptor.Unary()(withKey("fmk_unknown"), nil, info, unary)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
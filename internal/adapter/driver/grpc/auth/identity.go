@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientIdentity returns the identity of the verified client certificate of
+// a mutual TLS connection: its first URI SAN, such as a SPIFFE id, else its
+// first DNS SAN, else its common name.
+func ClientIdentity(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	certificate := tlsInfo.State.VerifiedChains[0][0]
+	switch {
+	case len(certificate.URIs) > 0:
+		return certificate.URIs[0].String(), true
+	case len(certificate.DNSNames) > 0:
+		return certificate.DNSNames[0], true
+	case certificate.Subject.CommonName != "":
+		return certificate.Subject.CommonName, true
+	default:
+		return "", false
+	}
+}
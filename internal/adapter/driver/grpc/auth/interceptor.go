@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor authenticates every RPC with the bearer token of its
+// authorization metadata, falling back to an API key or the client
+// certificate when configured, and stores the principal in the context. With
+// a policy it also authorizes the principal for the method.
+type Interceptor struct {
+	verifier         *Verifier
+	policy           Policy
+	publicMethods    []string
+	certificateRoles map[string][]Role
+	apiKeys          ApiKeyAuthenticator
+}
+
+// ApiKeyAuthenticator returns the principal of an API key. Its errors are
+// returned to the caller, so they should be gRPC status errors.
+type ApiKeyAuthenticator interface {
+	AuthenticateApiKey(ctx context.Context, key string) (*Principal, error)
+}
+
+// ApiKeyHeader is the metadata key API keys are sent in.
+const ApiKeyHeader = "x-api-key"
+
+// InterceptorOption configures an Interceptor.
+type InterceptorOption func(*Interceptor)
+
+// WithPublicMethods lets methods through without a token. A prefix ending in
+// "/" matches every method of a service, e.g. "/grpc.health.v1.Health/".
+func WithPublicMethods(methods ...string) InterceptorOption {
+	return func(i *Interceptor) {
+		i.publicMethods = append(i.publicMethods, methods...)
+	}
+}
+
+// WithCertificateRoles authenticates callers without a token by the identity
+// of their client certificate, granting them the roles listed for it.
+func WithCertificateRoles(roles map[string][]Role) InterceptorOption {
+	return func(i *Interceptor) {
+		i.certificateRoles = roles
+	}
+}
+
+// WithApiKeys authenticates callers without a token by the key in their
+// x-api-key metadata.
+func WithApiKeys(authenticator ApiKeyAuthenticator) InterceptorOption {
+	return func(i *Interceptor) {
+		i.apiKeys = authenticator
+	}
+}
+
+// WithPolicy denies calls the policy does not allow with PermissionDenied.
+func WithPolicy(policy Policy) InterceptorOption {
+	return func(i *Interceptor) {
+		i.policy = policy
+	}
+}
+
+func NewInterceptor(verifier *Verifier, options ...InterceptorOption) *Interceptor {
+	i := &Interceptor{verifier: verifier}
+	for _, option := range options {
+		option(i)
+	}
+	return i
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if i.isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		principal, err := i.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := i.authorize(*principal, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(NewContext(ctx, *principal), req)
+	}
+}
+
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if i.isPublic(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		principal, err := i.authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+		wrapped := &serverStream{ServerStream: stream, ctx: NewContext(stream.Context(), *principal)}
+		if i.policy != nil && i.policy.NeedsRequest(info.FullMethod) {
+			// The request is only known once the handler received it.
+			wrapped.authorize = func(req interface{}) error {
+				return i.authorize(*principal, info.FullMethod, req)
+			}
+		} else if err := i.authorize(*principal, info.FullMethod, nil); err != nil {
+			return err
+		}
+		return handler(srv, wrapped)
+	}
+}
+
+func (i *Interceptor) authenticate(ctx context.Context) (*Principal, error) {
+	identity, _ := ClientIdentity(ctx)
+	token, err := bearerToken(ctx)
+	if err != nil {
+		if key := apiKey(ctx); key != "" && i.apiKeys != nil {
+			principal, err := i.apiKeys.AuthenticateApiKey(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			principal.ClientIdentity = identity
+			return principal, nil
+		}
+		if roles, ok := i.certificateRoles[identity]; ok && identity != "" {
+			return &Principal{Roles: roles, ClientIdentity: identity}, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	principal, err := i.verifier.Verify(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	principal.ClientIdentity = identity
+	return principal, nil
+}
+
+func (i *Interceptor) authorize(principal Principal, method string, req interface{}) error {
+	if i.policy == nil || i.policy.Allow(principal, method, req) {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "not allowed to call %s", method)
+}
+
+func (i *Interceptor) isPublic(method string) bool {
+	for _, public := range i.publicMethods {
+		if method == public || (strings.HasSuffix(public, "/") && strings.HasPrefix(method, public)) {
+			return true
+		}
+	}
+	return false
+}
+
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "bearer") && token != "" {
+			return strings.TrimSpace(token), nil
+		}
+	}
+	return "", ErrMissingToken
+}
+
+func apiKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(ApiKeyHeader); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+// serverStream replaces the context of a stream with the authenticated one
+// and authorizes the first message it receives when required.
+type serverStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	authorize func(req interface{}) error
+	once      sync.Once
+	err       error
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.authorize != nil {
+		s.once.Do(func() {
+			s.err = s.authorize(m)
+		})
+	}
+	return s.err
+}
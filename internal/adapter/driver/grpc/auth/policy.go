@@ -0,0 +1,69 @@
+package auth
+
+import "github.com/nullexp/finman-transaction-service/internal/domain/model"
+
+// Rule says who may call a method.
+type Rule struct {
+	// Roles may call the method without restriction.
+	Roles []Role
+	// Scopes let API keys with any of them call the method.
+	Scopes []model.ApiKeyScope
+	// Self lets principals with RoleUser call the method when every user id
+	// of the request is their own.
+	Self bool
+	// UserIds returns the user ids a request acts on, by default its user_id
+	// field.
+	UserIds func(req interface{}) []string
+}
+
+// Policy maps full method names, e.g. "/transaction.v1.TransactionService/GetTransactionById",
+// to their rule. Methods without a rule are denied.
+type Policy map[string]Rule
+
+// NeedsRequest reports whether deciding on method requires its request.
+func (p Policy) NeedsRequest(method string) bool {
+	return p[method].Self
+}
+
+// Allow reports whether principal may call method with req.
+func (p Policy) Allow(principal Principal, method string, req interface{}) bool {
+	rule, ok := p[method]
+	if !ok {
+		return false
+	}
+	for _, role := range rule.Roles {
+		if principal.HasRole(role) {
+			return true
+		}
+	}
+	for _, scope := range rule.Scopes {
+		if principal.HasScope(scope) {
+			return true
+		}
+	}
+	if !rule.Self || !principal.HasRole(RoleUser) || req == nil {
+		return false
+	}
+
+	userIds := rule.UserIds
+	if userIds == nil {
+		userIds = requestUserId
+	}
+	ids := userIds(req)
+	if len(ids) == 0 {
+		return false
+	}
+	for _, id := range ids {
+		if id != principal.UserId {
+			return false
+		}
+	}
+	return true
+}
+
+func requestUserId(req interface{}) []string {
+	if r, ok := req.(interface{ GetUserId() string }); ok && r.GetUserId() != "" {
+		return []string{r.GetUserId()}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+// Role grants a principal access to the methods of a Policy.
+type Role string
+
+const (
+	// RoleUser is an end user working with their own data.
+	RoleUser Role = "user"
+	// RoleSupport reads the data of any user to help them.
+	RoleSupport Role = "support"
+	// RoleAuditor reads everything, including exports of all transactions.
+	RoleAuditor Role = "auditor"
+	// RoleAdmin may also change and delete any transaction.
+	RoleAdmin Role = "admin"
+)
+
+// Principal is the authenticated caller of an RPC.
+type Principal struct {
+	// UserId is the subject of the token, empty for services authenticated by
+	// an API key or their client certificate.
+	UserId string
+	Roles  []Role
+	// ClientIdentity identifies the verified client certificate of a mutual
+	// TLS connection.
+	ClientIdentity string
+	// ApiKeyId identifies the API key the caller authenticated with, owned by
+	// Service.
+	ApiKeyId string
+	Service  string
+	Scopes   []model.ApiKeyScope
+}
+
+func (p Principal) HasRole(role Role) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Principal) HasScope(scope model.ApiKeyScope) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying principal.
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal stored by the interceptor, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Verifier checks the signature and claims of JWTs signed with RS256 or ES256.
+type Verifier struct {
+	keys     KeySet
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// VerifierOption configures a Verifier.
+type VerifierOption func(*Verifier)
+
+// WithIssuer requires the iss claim to equal issuer.
+func WithIssuer(issuer string) VerifierOption {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithAudience requires the aud claim to contain audience.
+func WithAudience(audience string) VerifierOption {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
+
+// WithLeeway tolerates clock skew when checking exp, nbf and iat.
+func WithLeeway(leeway time.Duration) VerifierOption {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
+
+func WithVerifierClock(now func() time.Time) VerifierOption {
+	return func(v *Verifier) {
+		v.now = now
+	}
+}
+
+// NewVerifier requires an issuer and an audience, so tokens issued for other
+// services are not accepted.
+func NewVerifier(keys KeySet, options ...VerifierOption) (*Verifier, error) {
+	v := &Verifier{keys: keys, now: time.Now}
+	for _, option := range options {
+		option(v)
+	}
+	if v.issuer == "" || v.audience == "" {
+		return nil, errors.New("the token issuer and audience are required")
+	}
+	return v, nil
+}
+
+// Verify returns the principal of a valid token. Tokens must expire, name
+// their subject and be issued by the issuer for the audience.
+func (v *Verifier) Verify(token string) (*Principal, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithLeeway(v.leeway),
+		jwt.WithTimeFunc(v.now),
+	}
+
+	var claims tokenClaims
+	if _, err := jwt.ParseWithClaims(token, &claims, v.key, options...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	principal := &Principal{UserId: claims.Subject}
+	for _, role := range claims.Roles {
+		principal.Roles = append(principal.Roles, Role(role))
+	}
+	if claims.Roles == nil {
+		principal.Roles = []Role{RoleUser}
+	}
+	return principal, nil
+}
+
+// tokenClaims are the registered claims and the roles of the principal. Tokens
+// without a roles claim belong to end users.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+// key looks the key up by the kid header. Tokens without kid are accepted
+// when there is only one key.
+func (v *Verifier) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return key, nil
+}
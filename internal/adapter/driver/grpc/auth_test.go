@@ -0,0 +1,17 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnUserId(t *testing.T) {
+	assert.Equal(t, "requested", ownUserId(context.Background(), "requested"))
+
+	ctx := auth.NewContext(context.Background(), auth.Principal{UserId: "authenticated"})
+	assert.Equal(t, "authenticated", ownUserId(ctx, "requested"))
+	assert.Equal(t, "authenticated", ownUserId(ctx, ""))
+}
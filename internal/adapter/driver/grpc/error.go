@@ -0,0 +1,173 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"unicode"
+
+	validator "github.com/go-playground/validator/v10"
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// ErrorDomain is the domain of the google.rpc.ErrorInfo attached to errors.
+const ErrorDomain = "transaction.finman"
+
+const (
+	reasonValidationFailed = "VALIDATION_FAILED"
+	reasonInternal         = "INTERNAL"
+)
+
+var errorCodes = map[domain.ErrorKind]codes.Code{
+	domain.KindInvalidArgument:    codes.InvalidArgument,
+	domain.KindNotFound:           codes.NotFound,
+	domain.KindFailedPrecondition: codes.FailedPrecondition,
+	domain.KindResourceExhausted:  codes.ResourceExhausted,
+	domain.KindAborted:            codes.Aborted,
+	domain.KindUnimplemented:      codes.Unimplemented,
+	domain.KindUnauthenticated:    codes.Unauthenticated,
+	domain.KindUnavailable:        codes.Unavailable,
+}
+
+// toStatus converts an error of the service layer into a gRPC status error.
+// Domain errors keep their message and reason, validation errors list the
+// violated fields, and anything else is logged and reported without details
+// since it may contain SQL or other internals.
+func toStatus(ctx context.Context, method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		code, ok := errorCodes[domainErr.Kind]
+		if !ok {
+			code = codes.Unknown
+		}
+		return withDetails(status.New(code, domainErr.Message), errorInfo(domainErr.Reason))
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		badRequest := &errdetails.BadRequest{}
+		for _, fieldErr := range validationErrors {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fieldPath(fieldErr.Namespace()),
+				Description: describe(fieldErr),
+			})
+		}
+		return withDetails(status.New(codes.InvalidArgument, "request is invalid"), errorInfo(reasonValidationFailed), badRequest)
+	}
+
+	slog.ErrorContext(ctx, "RPC failed", "method", method, "error", err)
+	return withDetails(status.New(codes.Internal, method+" failed"), errorInfo(reasonInternal))
+}
+
+// invalidArgument reports a field the handler could not convert.
+func invalidArgument(field, description string) error {
+	return withDetails(status.New(codes.InvalidArgument, field+" "+description), errorInfo(reasonValidationFailed), &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: description}},
+	})
+}
+
+func errorInfo(reason string) *errdetails.ErrorInfo {
+	return &errdetails.ErrorInfo{Reason: reason, Domain: ErrorDomain}
+}
+
+func withDetails(s *status.Status, details ...protoadapt.MessageV1) error {
+	detailed, err := s.WithDetails(details...)
+	if err != nil {
+		return s.Err()
+	}
+	return detailed.Err()
+}
+
+// fieldPath turns a validator namespace such as
+// "UpdateNotificationPreferencesRequest.Preferences.EventTypes[0]" into the
+// proto field path "preferences.event_types[0]".
+func fieldPath(namespace string) string {
+	segments := strings.Split(namespace, ".")
+	if len(segments) > 1 {
+		segments = segments[1:]
+	}
+	for i, segment := range segments {
+		segments[i] = snakeCase(segment)
+	}
+	return strings.Join(segments, ".")
+}
+
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			previousLower := i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]))
+			acronymEnd := i > 0 && unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousLower || acronymEnd {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// bound describes a min or max constraint, which limits the length of strings
+// and collections and the value of numbers.
+func bound(limit, param string, kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "must have " + limit + " " + param + " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "must have " + limit + " " + param + " items"
+	default:
+		return "must be " + limit + " " + param
+	}
+}
+
+func describe(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "uuid":
+		return "must be a UUID"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be at least " + param
+	case "lt":
+		return "must be less than " + param
+	case "lte":
+		return "must be at most " + param
+	case "min":
+		return bound("at least", param, fieldErr.Kind())
+	case "max":
+		return bound("at most", param, fieldErr.Kind())
+	case "url", "http_url":
+		return "must be a URL"
+	case "startswith":
+		return "must start with " + param
+	case "datetime":
+		return "must have the format " + param
+	case "timezone":
+		return "must be an IANA time zone"
+	case "bcp47_language_tag":
+		return "must be a BCP 47 language tag"
+	default:
+		return fmt.Sprintf("failed the %s check", fieldErr.Tag())
+	}
+}
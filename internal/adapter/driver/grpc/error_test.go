@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatusDomainError(t *testing.T) {
+	err := toStatus(context.Background(), "UpdateTransaction", fmt.Errorf("update: %w", domain.ErrInsufficientBalance))
+
+	s := status.Convert(err)
+	assert.Equal(t, codes.FailedPrecondition, s.Code())
+	assert.Equal(t, domain.ErrInsufficientBalance.Message, s.Message())
+	assert.Len(t, s.Details(), 1)
+	info := s.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, "INSUFFICIENT_BALANCE", info.Reason)
+	assert.Equal(t, ErrorDomain, info.Domain)
+
+	assert.Equal(t, codes.NotFound, status.Code(toStatus(context.Background(), "GetTransactionById", domain.ErrTransactionNotFound)))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(toStatus(context.Background(), "CreateWebhookSubscription", domain.ErrTooManyWebhooks)))
+}
+
+func TestToStatusValidationError(t *testing.T) {
+	request := model.UpdateNotificationPreferencesRequest{
+		Preferences: model.NotificationPreferences{
+			UserId:          "not-a-uuid",
+			EventTypes:      []string{"created", "unknown"},
+			QuietHoursStart: "25:00",
+		},
+	}
+	err := toStatus(context.Background(), "UpdateNotificationPreferences", request.Validate(context.Background()))
+
+	s := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, s.Code())
+	assert.Len(t, s.Details(), 2)
+	assert.Equal(t, "VALIDATION_FAILED", s.Details()[0].(*errdetails.ErrorInfo).Reason)
+
+	violations := map[string]string{}
+	for _, violation := range s.Details()[1].(*errdetails.BadRequest).FieldViolations {
+		violations[violation.Field] = violation.Description
+	}
+	assert.Equal(t, map[string]string{
+		"preferences.user_id":           "must be a UUID",
+		"preferences.event_types[1]":    "must be one of: created, updated, deleted, insufficient_balance, low_balance, large_transaction",
+		"preferences.quiet_hours_start": "must have the format 15:04",
+	}, violations)
+}
+
+func TestValidationErrorsDescribeLengths(t *testing.T) {
+	request := model.SearchTransactionsRequest{UserId: uuid.New().String(), Query: strings.Repeat("a", 257)}
+	err := toStatus(context.Background(), "SearchTransactions", request.Validate(context.Background()))
+
+	violations := status.Convert(err).Details()[1].(*errdetails.BadRequest).FieldViolations
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "query", violations[0].Field)
+	assert.Equal(t, "must have at most 256 characters", violations[0].Description)
+
+	apiKey := model.CreateApiKeyRequest{Service: "reporting", Scopes: []string{}}
+	err = toStatus(context.Background(), "CreateApiKey", apiKey.Validate(context.Background()))
+	violations = status.Convert(err).Details()[1].(*errdetails.BadRequest).FieldViolations
+	assert.Len(t, violations, 1)
+	assert.Equal(t, "scopes", violations[0].Field)
+	assert.Equal(t, "must have at least 1 items", violations[0].Description)
+}
+
+func TestToStatusHidesInternalErrors(t *testing.T) {
+	err := toStatus(context.Background(), "CreateTransaction", errors.New(`pq: relation "transactions" does not exist`))
+
+	s := status.Convert(err)
+	assert.Equal(t, codes.Internal, s.Code())
+	assert.Equal(t, "CreateTransaction failed", s.Message())
+	assert.NotContains(t, s.Message(), "pq:")
+}
+
+func TestToStatusKeepsStatusAndContextErrors(t *testing.T) {
+	assert.Equal(t, codes.InvalidArgument, status.Code(toStatus(context.Background(), "ListTransactions", invalidArgument("from_date", "must be an RFC3339 timestamp"))))
+	assert.Equal(t, codes.Canceled, status.Code(toStatus(context.Background(), "StreamTransactions", context.Canceled)))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(toStatus(context.Background(), "StreamTransactions", fmt.Errorf("query: %w", context.DeadlineExceeded))))
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "user_id", snakeCase("UserId"))
+	assert.Equal(t, "page_size", snakeCase("PageSize"))
+	assert.Equal(t, "callback_url", snakeCase("CallbackURL"))
+	assert.Equal(t, "event_types[0]", snakeCase("EventTypes[0]"))
+}
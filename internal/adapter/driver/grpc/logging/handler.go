@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the values of sensitive attributes.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the attribute keys lowercased and stripped of "_" and "-",
+// so "api_key" and "apiKey" both are "apikey". Keys have to match exactly, as
+// e.g. "page_token" and "api_key_id" are no secrets.
+var sensitiveKeys = map[string]bool{
+	"password":           true,
+	"passwd":             true,
+	"secret":             true,
+	"clientsecret":       true,
+	"previoussecret":     true,
+	"token":              true,
+	"accesstoken":        true,
+	"refreshtoken":       true,
+	"idtoken":            true,
+	"authorization":      true,
+	"proxyauthorization": true,
+	"apikey":             true,
+	"xapikey":            true,
+	"cookie":             true,
+	"setcookie":          true,
+	"dsn":                true,
+	"description":        true,
+	"email":              true,
+}
+
+// secretPatterns mask secrets and e-mail addresses embedded in messages and
+// string values, such as a DSN or connection URL in a database error.
+var secretPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)\b(password|passwd|secret|token|api[_-]?key)(\s*[=:]\s*)[^\s&,;]+`), "${1}${2}" + Redacted},
+	{regexp.MustCompile(`://([^:/@\s]+):[^@\s]+@`), "://${1}:" + Redacted + "@"},
+	{regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`), "Bearer " + Redacted},
+	{regexp.MustCompile(`\b(fmk|whsec)_[A-Za-z0-9_-]+`), "${1}_" + Redacted},
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`), Redacted},
+}
+
+// Handler adds the request id of the context to every record and masks
+// secrets and personal data before passing records on to the next handler.
+type Handler struct {
+	next slog.Handler
+}
+
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, RedactString(record.Message), record.PC)
+	if requestId, ok := RequestIdFromContext(ctx); ok {
+		redacted.AddAttrs(slog.String("request_id", requestId))
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redact(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redact(attr)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+// RedactString masks the secrets found by secretPatterns in s.
+func RedactString(s string) string {
+	for _, p := range secretPatterns {
+		s = p.pattern.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+func redact(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if sensitiveKey(attr.Key) && attr.Value.Kind() != slog.KindGroup {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, a := range group {
+			redacted[i] = redact(a)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindString:
+		return slog.String(attr.Key, RedactString(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, RedactString(err.Error()))
+		}
+	}
+	return attr
+}
+
+func sensitiveKey(key string) bool {
+	return sensitiveKeys[strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))]
+}
@@ -0,0 +1,170 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	grpcDriver "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serverErrors are logged at error level, other codes are the caller's fault.
+var serverErrors = map[codes.Code]bool{
+	codes.Unknown:     true,
+	codes.Internal:    true,
+	codes.DataLoss:    true,
+	codes.Unavailable: true,
+}
+
+// Interceptor assigns every RPC a request id, converts panics into Internal
+// errors and writes an access log entry when the RPC returns. Its Unary and
+// Stream interceptors belong first in the chain, while UnaryCaller and
+// StreamCaller go after authentication to record who made the call.
+type Interceptor struct {
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// InterceptorOption configures an Interceptor.
+type InterceptorOption func(*Interceptor)
+
+func WithInterceptorClock(now func() time.Time) InterceptorOption {
+	return func(i *Interceptor) {
+		i.now = now
+	}
+}
+
+func NewInterceptor(logger *slog.Logger, options ...InterceptorOption) *Interceptor {
+	i := &Interceptor{logger: logger, now: time.Now}
+	for _, option := range options {
+		option(i)
+	}
+	return i
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		ctx, call := i.begin(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIdHeader, call.requestId))
+		defer func() {
+			if r := recover(); r != nil {
+				err = i.recovered(ctx, info.FullMethod, r)
+			}
+			i.log(ctx, call, info.FullMethod, err)
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx, call := i.begin(stream.Context())
+		_ = stream.SetHeader(metadata.Pairs(RequestIdHeader, call.requestId))
+		defer func() {
+			if r := recover(); r != nil {
+				err = i.recovered(ctx, info.FullMethod, r)
+			}
+			i.log(ctx, call, info.FullMethod, err)
+		}()
+		return handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// UnaryCaller records the authenticated principal for the access log.
+func (i *Interceptor) UnaryCaller() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		recordCaller(ctx)
+		return handler(ctx, req)
+	}
+}
+
+// StreamCaller records the authenticated principal for the access log.
+func (i *Interceptor) StreamCaller() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		recordCaller(stream.Context())
+		return handler(srv, stream)
+	}
+}
+
+// call collects what the access log reports about an RPC. Interceptors further
+// down the chain only see derived contexts, so they fill it in through the
+// pointer stored in the context.
+type call struct {
+	requestId string
+	start     time.Time
+	principal *auth.Principal
+}
+
+type callKey struct{}
+
+func (i *Interceptor) begin(ctx context.Context) (context.Context, *call) {
+	c := &call{requestId: incomingRequestId(ctx), start: i.now()}
+	ctx = ContextWithRequestId(ctx, c.requestId)
+	return context.WithValue(ctx, callKey{}, c), c
+}
+
+func recordCaller(ctx context.Context) {
+	c, ok := ctx.Value(callKey{}).(*call)
+	if !ok {
+		return
+	}
+	if principal, ok := auth.FromContext(ctx); ok {
+		c.principal = &principal
+	}
+}
+
+func (i *Interceptor) recovered(ctx context.Context, method string, r interface{}) error {
+	i.logger.ErrorContext(ctx, "RPC panicked", "method", method, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+	s := status.New(codes.Internal, method+" failed")
+	detailed, err := s.WithDetails(&errdetails.ErrorInfo{Reason: "INTERNAL", Domain: grpcDriver.ErrorDomain})
+	if err != nil {
+		return s.Err()
+	}
+	return detailed.Err()
+}
+
+func (i *Interceptor) log(ctx context.Context, c *call, method string, err error) {
+	s := status.Convert(err)
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", s.Code().String()),
+		slog.Float64("latency_ms", float64(i.now().Sub(c.start).Microseconds())/1000),
+	}
+	if c.principal != nil {
+		if c.principal.UserId != "" {
+			attrs = append(attrs, slog.String("user_id", c.principal.UserId))
+		}
+		if c.principal.ApiKeyId != "" {
+			attrs = append(attrs, slog.String("api_key_id", c.principal.ApiKeyId), slog.String("service", c.principal.Service))
+		}
+		if c.principal.ClientIdentity != "" {
+			attrs = append(attrs, slog.String("client_identity", c.principal.ClientIdentity))
+		}
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		attrs = append(attrs, slog.String("error", s.Message()))
+		if serverErrors[s.Code()] {
+			level = slog.LevelError
+		}
+	}
+	i.logger.LogAttrs(ctx, level, "RPC finished", attrs...)
+}
+
+// serverStream carries the context with the request id to the handler.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,200 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const userId = "0d9c5f6e-3b8a-4d0b-9a63-2f4d2f0b7c11"
+
+// newLogger returns a logger writing JSON records through the Handler to the
+// returned buffer.
+func newLogger() (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(logging.NewHandler(slog.NewJSONHandler(&buf, nil))), &buf
+}
+
+func records(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		result = append(result, record)
+	}
+	return result
+}
+
+func TestHandlerRedactsSensitiveAttributes(t *testing.T) {
+	logger, buf := newLogger()
+
+	logger.With("api_key", "fmk_abc_def").WithGroup("transaction").Info("created",
+		"description", "rent for flat 3b",
+		"amount", 1200,
+		slog.Group("user", "email", "jane@example.com", "id", userId),
+		"Authorization", "Bearer eyJhbGciOi",
+		"page_token", "eyJjdXJzb3IiOnt9fQ",
+	)
+
+	record := records(t, buf)[0]
+	assert.Equal(t, logging.Redacted, record["api_key"])
+	transaction := record["transaction"].(map[string]interface{})
+	assert.Equal(t, logging.Redacted, transaction["description"])
+	assert.Equal(t, float64(1200), transaction["amount"])
+	assert.Equal(t, logging.Redacted, transaction["user"].(map[string]interface{})["email"])
+	assert.Equal(t, userId, transaction["user"].(map[string]interface{})["id"])
+	assert.Equal(t, logging.Redacted, transaction["Authorization"])
+	// Only exact key matches are redacted
+	assert.Equal(t, "eyJjdXJzb3IiOnt9fQ", transaction["page_token"])
+}
+
+func TestHandlerRedactsSecretsInValues(t *testing.T) {
+	logger, buf := newLogger()
+
+	logger.Error("failed to connect host=db password=hunter2 dbname=finman",
+		"error", errors.New(`dial postgres://finman:hunter2@db:5432/finman: refused`),
+		"header", "bearer abc.def.ghi",
+		"key", "sent fmk_0123abcd_c2VjcmV0 and whsec_c2VjcmV0",
+		"recipient", "notifying jane.doe+finman@mail.example.com failed",
+	)
+
+	output := buf.String()
+	assert.NotContains(t, output, "hunter2")
+	assert.NotContains(t, output, "abc.def.ghi")
+	assert.NotContains(t, output, "c2VjcmV0")
+	record := records(t, buf)[0]
+	assert.Equal(t, "failed to connect host=db password="+logging.Redacted+" dbname=finman", record["msg"])
+	assert.Equal(t, "dial postgres://finman:"+logging.Redacted+"@db:5432/finman: refused", record["error"])
+	assert.Equal(t, "notifying "+logging.Redacted+" failed", record["recipient"])
+}
+
+func TestHandlerAddsRequestId(t *testing.T) {
+	logger, buf := newLogger()
+
+	logger.InfoContext(logging.ContextWithRequestId(context.Background(), "req-1"), "with id")
+	logger.InfoContext(context.Background(), "without id")
+
+	result := records(t, buf)
+	assert.Equal(t, "req-1", result[0]["request_id"])
+	assert.NotContains(t, result[1], "request_id")
+}
+
+func TestInterceptorLogsCall(t *testing.T) {
+	logger, buf := newLogger()
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	interceptor := logging.NewInterceptor(logger, logging.WithInterceptorClock(func() time.Time { return now }))
+	info := &grpc.UnaryServerInfo{FullMethod: "/transaction.v1.TransactionService/GetTransactionById"}
+	var handlerRequestId string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerRequestId, _ = logging.RequestIdFromContext(ctx)
+		now = now.Add(1500 * time.Microsecond)
+		return nil, status.Error(codes.NotFound, "transaction not found")
+	}
+	// authenticates like the auth interceptor before recording the caller
+	authenticated := func(ctx context.Context, req interface{}) (interface{}, error) {
+		ctx = auth.NewContext(ctx, auth.Principal{UserId: userId})
+		return interceptor.UnaryCaller()(ctx, req, info, handler)
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(logging.RequestIdHeader, "req-42"))
+	_, err := interceptor.Unary()(ctx, nil, info, authenticated)
+	require.Error(t, err)
+	assert.Equal(t, "req-42", handlerRequestId)
+
+	record := records(t, buf)[0]
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "RPC finished", record["msg"])
+	assert.Equal(t, "req-42", record["request_id"])
+	assert.Equal(t, info.FullMethod, record["method"])
+	assert.Equal(t, "NotFound", record["code"])
+	assert.Equal(t, 1.5, record["latency_ms"])
+	assert.Equal(t, userId, record["user_id"])
+	assert.Equal(t, "transaction not found", record["error"])
+}
+
+func TestInterceptorRecoversPanics(t *testing.T) {
+	logger, buf := newLogger()
+	interceptor := logging.NewInterceptor(logger)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/transaction.v1.TransactionService/CreateTransaction"}
+	_, err := interceptor.Unary()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+
+	s := status.Convert(err)
+	assert.Equal(t, codes.Internal, s.Code())
+	require.Len(t, s.Details(), 1)
+	assert.Equal(t, "INTERNAL", s.Details()[0].(*errdetails.ErrorInfo).Reason)
+
+	result := records(t, buf)
+	require.Len(t, result, 2)
+	assert.Equal(t, "RPC panicked", result[0]["msg"])
+	assert.Equal(t, "boom", result[0]["panic"])
+	assert.NotEmpty(t, result[0]["stack"])
+	assert.Equal(t, "ERROR", result[1]["level"])
+	assert.Equal(t, "Internal", result[1]["code"])
+	assert.Equal(t, result[0]["request_id"], result[1]["request_id"])
+}
+
+func TestInterceptorReturnsRequestId(t *testing.T) {
+	logger, _ := newLogger()
+	interceptor := logging.NewInterceptor(logger)
+	server := grpc.NewServer(grpc.UnaryInterceptor(interceptor.Unary()))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+
+	tests := []struct {
+		name     string
+		incoming string
+		want     func(string)
+	}{
+		{"accepts the client's id", "req-7", func(id string) { assert.Equal(t, "req-7", id) }},
+		{"generates a missing id", "", func(id string) { assert.Len(t, id, 36) }},
+		{"replaces an invalid id", "has spaces", func(id string) { assert.Len(t, id, 36) }},
+		{"replaces a long id", strings.Repeat("x", 200), func(id string) { assert.Len(t, id, 36) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.incoming != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, logging.RequestIdHeader, tt.incoming)
+			}
+			var header metadata.MD
+			_, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+			require.NoError(t, err)
+			require.Len(t, header.Get(logging.RequestIdHeader), 1)
+			tt.want(header.Get(logging.RequestIdHeader)[0])
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIdHeader is the metadata key correlating a call with its logs. It is
+// accepted from the client and always returned in the response headers.
+const RequestIdHeader = "x-request-id"
+
+const maxRequestIdLength = 128
+
+type requestIdKey struct{}
+
+// ContextWithRequestId returns a copy of ctx carrying the request id, which
+// the Handler adds to every record logged with that context.
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// RequestIdFromContext returns the request id stored by the interceptor, if any.
+func RequestIdFromContext(ctx context.Context) (string, bool) {
+	requestId, ok := ctx.Value(requestIdKey{}).(string)
+	return requestId, ok
+}
+
+// incomingRequestId returns the request id sent by the client, or a new one
+// when it is missing or not a short printable token.
+func incomingRequestId(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIdHeader); len(values) > 0 && validRequestId(values[0]) {
+			return values[0]
+		}
+	}
+	return uuid.NewString()
+}
+
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+	for _, r := range requestId {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor counts RPCs by method and status code and observes their
+// latency. It belongs first in the chain so rejected calls are counted too.
+type Interceptor struct {
+	handled  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	now      func() time.Time
+}
+
+// InterceptorOption configures an Interceptor.
+type InterceptorOption func(*Interceptor)
+
+func WithInterceptorClock(now func() time.Time) InterceptorOption {
+	return func(i *Interceptor) {
+		i.now = now
+	}
+}
+
+// NewInterceptor registers the RPC metrics with registerer.
+func NewInterceptor(registerer prometheus.Registerer, options ...InterceptorOption) (*Interceptor, error) {
+	i := &Interceptor{
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "finman",
+			Name:      "grpc_server_handled_total",
+			Help:      "RPCs completed on the server, by service, method and status code.",
+		}, []string{"grpc_service", "grpc_method", "grpc_code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "finman",
+			Name:      "grpc_server_handling_seconds",
+			Help:      "Time until RPCs completed on the server, by service and method.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"grpc_service", "grpc_method"}),
+		now: time.Now,
+	}
+	for _, option := range options {
+		option(i)
+	}
+
+	for _, collector := range []prometheus.Collector{i.handled, i.duration} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return i, nil
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := i.now()
+		resp, err := handler(ctx, req)
+		i.observe(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := i.now()
+		err := handler(srv, stream)
+		i.observe(info.FullMethod, start, err)
+		return err
+	}
+}
+
+func (i *Interceptor) observe(fullMethod string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	i.handled.WithLabelValues(service, method, status.Code(err).String()).Inc()
+	i.duration.WithLabelValues(service, method).Observe(i.now().Sub(start).Seconds())
+}
+
+// splitMethod splits "/package.Service/Method" into the service and method name.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -0,0 +1,74 @@
+package metrics_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInterceptorCountsRpcs(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	interceptor, err := metrics.NewInterceptor(registry, metrics.WithInterceptorClock(func() time.Time { return now }))
+	require.NoError(t, err)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/transaction.v1.TransactionService/GetTransactionById"}
+	calls := []error{nil, nil, status.Error(codes.NotFound, "transaction not found")}
+	for _, result := range calls {
+		_, err := interceptor.Unary()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			now = now.Add(20 * time.Millisecond)
+			return nil, result
+		})
+		assert.Equal(t, result, err)
+	}
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/transaction.v1.TransactionService/StreamTransactions"}
+	err = interceptor.Stream()(nil, nil, streamInfo, func(srv interface{}, stream grpc.ServerStream) error {
+		return status.Error(codes.Internal, "failed")
+	})
+	assert.Error(t, err)
+
+	expected := `
+# HELP finman_grpc_server_handled_total RPCs completed on the server, by service, method and status code.
+# TYPE finman_grpc_server_handled_total counter
+finman_grpc_server_handled_total{grpc_code="Internal",grpc_method="StreamTransactions",grpc_service="transaction.v1.TransactionService"} 1
+finman_grpc_server_handled_total{grpc_code="NotFound",grpc_method="GetTransactionById",grpc_service="transaction.v1.TransactionService"} 1
+finman_grpc_server_handled_total{grpc_code="OK",grpc_method="GetTransactionById",grpc_service="transaction.v1.TransactionService"} 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "finman_grpc_server_handled_total"))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	found := false
+	for _, family := range families {
+		if family.GetName() != "finman_grpc_server_handling_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[0].GetValue() == "GetTransactionById" {
+				found = true
+				assert.Equal(t, uint64(3), metric.GetHistogram().GetSampleCount())
+				assert.InDelta(t, 0.06, metric.GetHistogram().GetSampleSum(), 1e-9)
+			}
+		}
+	}
+	assert.True(t, found, "no latency of GetTransactionById")
+}
+
+func TestNewInterceptorFailsOnDuplicateRegistration(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	_, err := metrics.NewInterceptor(registry)
+	require.NoError(t, err)
+
+	_, err = metrics.NewInterceptor(registry)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	transactionv1 "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/proto/transaction/v1"
+	"github.com/nullexp/finman-transaction-service/internal/port/driver"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+type NotificationPreferencesService struct {
+	transactionv1.UnimplementedNotificationPreferencesServiceServer
+	service driver.NotificationPreferencesService
+}
+
+func NewNotificationPreferencesService(ps driver.NotificationPreferencesService) *NotificationPreferencesService {
+	return &NotificationPreferencesService{service: ps}
+}
+
+var notificationChannels = map[string]transactionv1.NotificationChannel{
+	"log":     transactionv1.NotificationChannel_NOTIFICATION_CHANNEL_LOG,
+	"webhook": transactionv1.NotificationChannel_NOTIFICATION_CHANNEL_WEBHOOK,
+	"email":   transactionv1.NotificationChannel_NOTIFICATION_CHANNEL_EMAIL,
+}
+
+func CastNotificationPreferencesToProto(preferences *model.NotificationPreferences) *transactionv1.NotificationPreferences {
+	protoPreferences := &transactionv1.NotificationPreferences{
+		UserId:          preferences.UserId,
+		QuietHoursStart: preferences.QuietHoursStart,
+		QuietHoursEnd:   preferences.QuietHoursEnd,
+		TimeZone:        preferences.TimeZone,
+		MinAmount:       preferences.MinAmount,
+		Locale:          preferences.Locale,
+		DailyDigest:     preferences.DailyDigest,
+	}
+	if !preferences.UpdatedAt.IsZero() {
+		protoPreferences.UpdatedAt = preferences.UpdatedAt.Format(time.RFC3339)
+	}
+	for _, eventType := range preferences.EventTypes {
+		protoPreferences.EventTypes = append(protoPreferences.EventTypes, eventTypes[eventType])
+	}
+	for _, channel := range preferences.Channels {
+		protoPreferences.Channels = append(protoPreferences.Channels, notificationChannels[channel])
+	}
+	return protoPreferences
+}
+
+func CastProtoToNotificationPreferences(preferences *transactionv1.NotificationPreferences) (model.NotificationPreferences, error) {
+	if preferences == nil {
+		return model.NotificationPreferences{}, invalidArgument("preferences", "is required")
+	}
+
+	eventTypes, err := castProtoToEventTypes(preferences.EventTypes)
+	if err != nil {
+		return model.NotificationPreferences{}, err
+	}
+	result := model.NotificationPreferences{
+		UserId:          preferences.UserId,
+		EventTypes:      eventTypes,
+		QuietHoursStart: preferences.QuietHoursStart,
+		QuietHoursEnd:   preferences.QuietHoursEnd,
+		TimeZone:        preferences.TimeZone,
+		MinAmount:       preferences.MinAmount,
+		Locale:          preferences.Locale,
+		DailyDigest:     preferences.DailyDigest,
+	}
+	for _, protoChannel := range preferences.Channels {
+		found := false
+		for channel, value := range notificationChannels {
+			if value == protoChannel {
+				result.Channels = append(result.Channels, channel)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return model.NotificationPreferences{}, invalidArgument("preferences.channels", fmt.Sprintf("unsupported notification channel %v", protoChannel))
+		}
+	}
+	return result, nil
+}
+
+func (ps NotificationPreferencesService) GetNotificationPreferences(ctx context.Context, request *transactionv1.GetNotificationPreferencesRequest) (*transactionv1.GetNotificationPreferencesResponse, error) {
+	rs, err := ps.service.GetNotificationPreferences(ctx, model.GetNotificationPreferencesRequest{UserId: ownUserId(ctx, request.UserId)})
+	if err != nil {
+		return nil, toStatus(ctx, "GetNotificationPreferences", err)
+	}
+
+	return &transactionv1.GetNotificationPreferencesResponse{Preferences: CastNotificationPreferencesToProto(&rs.Preferences)}, nil
+}
+
+func (ps NotificationPreferencesService) UpdateNotificationPreferences(ctx context.Context, request *transactionv1.UpdateNotificationPreferencesRequest) (*transactionv1.UpdateNotificationPreferencesResponse, error) {
+	preferences, err := CastProtoToNotificationPreferences(request.Preferences)
+	if err != nil {
+		return nil, err
+	}
+	preferences.UserId = ownUserId(ctx, preferences.UserId)
+
+	rs, err := ps.service.UpdateNotificationPreferences(ctx, model.UpdateNotificationPreferencesRequest{Preferences: preferences})
+	if err != nil {
+		return nil, toStatus(ctx, "UpdateNotificationPreferences", err)
+	}
+
+	return &transactionv1.UpdateNotificationPreferencesResponse{Preferences: CastNotificationPreferencesToProto(&rs.Preferences)}, nil
+}
@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	transactionv1 "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/proto/transaction/v1"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+var (
+	readers = []auth.Role{auth.RoleSupport, auth.RoleAuditor, auth.RoleAdmin}
+	admins  = []auth.Role{auth.RoleAdmin}
+	users   = []auth.Role{auth.RoleUser}
+
+	readScopes  = []model.ApiKeyScope{model.ScopeTransactionsRead, model.ScopeAdmin}
+	writeScopes = []model.ApiKeyScope{model.ScopeTransactionsWrite, model.ScopeAdmin}
+	adminScopes = []model.ApiKeyScope{model.ScopeAdmin}
+)
+
+// Policy is who may call which RPC. Users call the "own" RPCs, which act on
+// the authenticated user, and the RPCs scoped to a user for themselves.
+// Support and auditors read any user's data, auditors also export everything,
+// and only admins write transactions of other users. Users only create
+// withdrawals, deposits would let them mint their own balance. API keys read
+// and write transactions as their scopes allow and never call the own RPCs.
+var Policy = auth.Policy{
+	transactionv1.TransactionService_CreateTransaction_FullMethodName:             {Roles: admins, Scopes: writeScopes, Self: true, UserIds: withdrawalUserIds},
+	transactionv1.TransactionService_GetTransactionById_FullMethodName:            {Roles: readers, Scopes: readScopes},
+	transactionv1.TransactionService_GetTransactionsByUserId_FullMethodName:       {Roles: readers, Scopes: readScopes, Self: true},
+	transactionv1.TransactionService_GetOwnTransactionById_FullMethodName:         {Roles: users},
+	transactionv1.TransactionService_GetAllTransactions_FullMethodName:            {Roles: []auth.Role{auth.RoleAuditor, auth.RoleAdmin}, Scopes: readScopes},
+	transactionv1.TransactionService_UpdateTransaction_FullMethodName:             {Roles: admins, Scopes: writeScopes},
+	transactionv1.TransactionService_DeleteTransaction_FullMethodName:             {Roles: admins, Scopes: writeScopes},
+	transactionv1.TransactionService_GetTransactionsWithPagination_FullMethodName: {Roles: readers, Scopes: readScopes},
+	transactionv1.TransactionService_SearchTransactions_FullMethodName:            {Roles: readers, Scopes: readScopes, Self: true},
+	transactionv1.TransactionService_ListTransactions_FullMethodName:              {Roles: readers, Scopes: readScopes, Self: true, UserIds: filterUserIds},
+	transactionv1.TransactionService_StreamTransactions_FullMethodName:            {Roles: []auth.Role{auth.RoleAuditor, auth.RoleAdmin}, Scopes: readScopes, Self: true, UserIds: filterUserIds},
+	transactionv1.TransactionService_SubscribeTransactions_FullMethodName:         {Roles: readers, Scopes: readScopes, Self: true},
+
+	transactionv1.WebhookService_CreateWebhookSubscription_FullMethodName: {Roles: users},
+	transactionv1.WebhookService_ListWebhookSubscriptions_FullMethodName:  {Roles: users},
+	transactionv1.WebhookService_RotateWebhookSecret_FullMethodName:       {Roles: users},
+	transactionv1.WebhookService_PauseWebhookSubscription_FullMethodName:  {Roles: users},
+	transactionv1.WebhookService_ResumeWebhookSubscription_FullMethodName: {Roles: users},
+	transactionv1.WebhookService_DeleteWebhookSubscription_FullMethodName: {Roles: users},
+	transactionv1.WebhookService_ListWebhookDeliveries_FullMethodName:     {Roles: users},
+
+	transactionv1.NotificationPreferencesService_GetNotificationPreferences_FullMethodName:    {Roles: users},
+	transactionv1.NotificationPreferencesService_UpdateNotificationPreferences_FullMethodName: {Roles: users},
+
+	transactionv1.AlertSettingsService_GetAlertSettings_FullMethodName:    {Roles: users},
+	transactionv1.AlertSettingsService_UpdateAlertSettings_FullMethodName: {Roles: users},
+
+	transactionv1.ApiKeyService_CreateApiKey_FullMethodName: {Roles: admins, Scopes: adminScopes},
+	transactionv1.ApiKeyService_ListApiKeys_FullMethodName:  {Roles: admins, Scopes: adminScopes},
+	transactionv1.ApiKeyService_RevokeApiKey_FullMethodName: {Roles: admins, Scopes: adminScopes},
+}
+
+// withdrawalUserIds lets users create withdrawals only, no user id denies
+// them other types.
+func withdrawalUserIds(req interface{}) []string {
+	withdrawal, ok := req.(*transactionv1.CreateTransactionRequest)
+	if !ok || withdrawal.GetType() != "withdrawal" {
+		return nil
+	}
+	return []string{withdrawal.GetUserId()}
+}
+
+func filterUserIds(req interface{}) []string {
+	filtered, ok := req.(interface {
+		GetFilter() *transactionv1.TransactionFilter
+	})
+	if !ok {
+		return nil
+	}
+	return filtered.GetFilter().GetUserIds()
+}
@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	transactionv1 "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/proto/transaction/v1"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	googlegrpc "google.golang.org/grpc"
+)
+
+const (
+	self  = "0d9c5f6e-3b8a-4d0b-9a63-2f4d2f0b7c11"
+	other = "6f1d0c1e-55a4-4e3a-8f5b-0a0c4c1b8e22"
+)
+
+func principal(roles ...auth.Role) auth.Principal {
+	return auth.Principal{UserId: self, Roles: roles}
+}
+
+func TestPolicyCoversEveryMethod(t *testing.T) {
+	for _, desc := range []googlegrpc.ServiceDesc{
+		transactionv1.TransactionService_ServiceDesc,
+		transactionv1.WebhookService_ServiceDesc,
+		transactionv1.NotificationPreferencesService_ServiceDesc,
+		transactionv1.AlertSettingsService_ServiceDesc,
+		transactionv1.ApiKeyService_ServiceDesc,
+	} {
+		var methods []string
+		for _, method := range desc.Methods {
+			methods = append(methods, method.MethodName)
+		}
+		for _, stream := range desc.Streams {
+			methods = append(methods, stream.StreamName)
+		}
+		for _, method := range methods {
+			assert.Contains(t, Policy, "/"+desc.ServiceName+"/"+method)
+		}
+	}
+}
+
+func TestPolicyBackOfficeMethods(t *testing.T) {
+	tests := []struct {
+		method  string
+		allowed []auth.Role
+	}{
+		{transactionv1.TransactionService_GetAllTransactions_FullMethodName, []auth.Role{auth.RoleAuditor, auth.RoleAdmin}},
+		{transactionv1.TransactionService_GetTransactionById_FullMethodName, []auth.Role{auth.RoleSupport, auth.RoleAuditor, auth.RoleAdmin}},
+		{transactionv1.TransactionService_UpdateTransaction_FullMethodName, []auth.Role{auth.RoleAdmin}},
+		{transactionv1.TransactionService_DeleteTransaction_FullMethodName, []auth.Role{auth.RoleAdmin}},
+	}
+	for _, test := range tests {
+		for _, role := range []auth.Role{auth.RoleUser, auth.RoleSupport, auth.RoleAuditor, auth.RoleAdmin} {
+			assert.Equal(t, contains(test.allowed, role), Policy.Allow(principal(role), test.method, nil), "%s as %s", test.method, role)
+		}
+	}
+}
+
+func TestPolicyUsersOwnData(t *testing.T) {
+	user := principal(auth.RoleUser)
+
+	assert.True(t, Policy.Allow(user, transactionv1.TransactionService_GetTransactionsByUserId_FullMethodName, &transactionv1.GetTransactionsByUserIdRequest{UserId: self}))
+	assert.False(t, Policy.Allow(user, transactionv1.TransactionService_GetTransactionsByUserId_FullMethodName, &transactionv1.GetTransactionsByUserIdRequest{UserId: other}))
+	assert.True(t, Policy.Allow(user, transactionv1.TransactionService_CreateTransaction_FullMethodName, &transactionv1.CreateTransactionRequest{UserId: self, Type: "withdrawal"}))
+	assert.False(t, Policy.Allow(user, transactionv1.TransactionService_CreateTransaction_FullMethodName, &transactionv1.CreateTransactionRequest{UserId: other, Type: "withdrawal"}))
+	// Users cannot deposit to themselves
+	assert.False(t, Policy.Allow(user, transactionv1.TransactionService_CreateTransaction_FullMethodName, &transactionv1.CreateTransactionRequest{UserId: self, Type: "deposit"}))
+	assert.True(t, Policy.Allow(user, transactionv1.TransactionService_SubscribeTransactions_FullMethodName, &transactionv1.SubscribeTransactionsRequest{UserId: self}))
+
+	list := transactionv1.TransactionService_ListTransactions_FullMethodName
+	assert.True(t, Policy.Allow(user, list, &transactionv1.ListTransactionsRequest{Filter: &transactionv1.TransactionFilter{UserIds: []string{self}}}))
+	assert.False(t, Policy.Allow(user, list, &transactionv1.ListTransactionsRequest{Filter: &transactionv1.TransactionFilter{UserIds: []string{self, other}}}))
+	// Without user ids the filter matches every user.
+	assert.False(t, Policy.Allow(user, list, &transactionv1.ListTransactionsRequest{}))
+	assert.True(t, Policy.Allow(principal(auth.RoleSupport), list, &transactionv1.ListTransactionsRequest{}))
+
+	assert.True(t, Policy.Allow(user, transactionv1.WebhookService_CreateWebhookSubscription_FullMethodName, &transactionv1.CreateWebhookSubscriptionRequest{UserId: other}))
+	assert.False(t, Policy.Allow(principal(auth.RoleSupport), transactionv1.WebhookService_CreateWebhookSubscription_FullMethodName, nil))
+	assert.False(t, Policy.Allow(principal(), transactionv1.TransactionService_GetOwnTransactionById_FullMethodName, nil))
+	assert.False(t, Policy.Allow(principal(auth.RoleAdmin), "/transaction.v1.TransactionService/Unknown", nil))
+}
+
+func TestPolicyApiKeys(t *testing.T) {
+	key := func(scopes ...model.ApiKeyScope) auth.Principal {
+		return auth.Principal{Service: "reporting", Scopes: scopes}
+	}
+	read := key(model.ScopeTransactionsRead)
+	write := key(model.ScopeTransactionsWrite)
+	admin := key(model.ScopeAdmin)
+
+	assert.True(t, Policy.Allow(read, transactionv1.TransactionService_StreamTransactions_FullMethodName, &transactionv1.StreamTransactionsRequest{}))
+	assert.True(t, Policy.Allow(read, transactionv1.TransactionService_GetTransactionById_FullMethodName, nil))
+	assert.False(t, Policy.Allow(read, transactionv1.TransactionService_UpdateTransaction_FullMethodName, nil))
+	assert.True(t, Policy.Allow(write, transactionv1.TransactionService_CreateTransaction_FullMethodName, &transactionv1.CreateTransactionRequest{UserId: other}))
+	assert.False(t, Policy.Allow(write, transactionv1.TransactionService_ListTransactions_FullMethodName, &transactionv1.ListTransactionsRequest{}))
+	assert.True(t, Policy.Allow(admin, transactionv1.TransactionService_DeleteTransaction_FullMethodName, nil))
+	assert.True(t, Policy.Allow(admin, transactionv1.ApiKeyService_CreateApiKey_FullMethodName, nil))
+	assert.False(t, Policy.Allow(write, transactionv1.ApiKeyService_CreateApiKey_FullMethodName, nil))
+	assert.False(t, Policy.Allow(admin, transactionv1.WebhookService_CreateWebhookSubscription_FullMethodName, nil))
+}
+
+func contains(roles []auth.Role, role auth.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: transaction/v1/alert.proto
+
+package transactionv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	AlertSettingsService_GetAlertSettings_FullMethodName    = "/transaction.v1.AlertSettingsService/GetAlertSettings"
+	AlertSettingsService_UpdateAlertSettings_FullMethodName = "/transaction.v1.AlertSettingsService/UpdateAlertSettings"
+)
+
+// AlertSettingsServiceClient is the client API for AlertSettingsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AlertSettingsService manages the balance and transaction alerts of users
+type AlertSettingsServiceClient interface {
+	GetAlertSettings(ctx context.Context, in *GetAlertSettingsRequest, opts ...grpc.CallOption) (*GetAlertSettingsResponse, error)
+	// Replaces all alert settings of the user
+	UpdateAlertSettings(ctx context.Context, in *UpdateAlertSettingsRequest, opts ...grpc.CallOption) (*UpdateAlertSettingsResponse, error)
+}
+
+type alertSettingsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAlertSettingsServiceClient(cc grpc.ClientConnInterface) AlertSettingsServiceClient {
+	return &alertSettingsServiceClient{cc}
+}
+
+func (c *alertSettingsServiceClient) GetAlertSettings(ctx context.Context, in *GetAlertSettingsRequest, opts ...grpc.CallOption) (*GetAlertSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAlertSettingsResponse)
+	err := c.cc.Invoke(ctx, AlertSettingsService_GetAlertSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *alertSettingsServiceClient) UpdateAlertSettings(ctx context.Context, in *UpdateAlertSettingsRequest, opts ...grpc.CallOption) (*UpdateAlertSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateAlertSettingsResponse)
+	err := c.cc.Invoke(ctx, AlertSettingsService_UpdateAlertSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AlertSettingsServiceServer is the server API for AlertSettingsService service.
+// All implementations must embed UnimplementedAlertSettingsServiceServer
+// for forward compatibility
+//
+// AlertSettingsService manages the balance and transaction alerts of users
+type AlertSettingsServiceServer interface {
+	GetAlertSettings(context.Context, *GetAlertSettingsRequest) (*GetAlertSettingsResponse, error)
+	// Replaces all alert settings of the user
+	UpdateAlertSettings(context.Context, *UpdateAlertSettingsRequest) (*UpdateAlertSettingsResponse, error)
+	mustEmbedUnimplementedAlertSettingsServiceServer()
+}
+
+// UnimplementedAlertSettingsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAlertSettingsServiceServer struct {
+}
+
+func (UnimplementedAlertSettingsServiceServer) GetAlertSettings(context.Context, *GetAlertSettingsRequest) (*GetAlertSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAlertSettings not implemented")
+}
+func (UnimplementedAlertSettingsServiceServer) UpdateAlertSettings(context.Context, *UpdateAlertSettingsRequest) (*UpdateAlertSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAlertSettings not implemented")
+}
+func (UnimplementedAlertSettingsServiceServer) mustEmbedUnimplementedAlertSettingsServiceServer() {}
+
+// UnsafeAlertSettingsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AlertSettingsServiceServer will
+// result in compilation errors.
+type UnsafeAlertSettingsServiceServer interface {
+	mustEmbedUnimplementedAlertSettingsServiceServer()
+}
+
+func RegisterAlertSettingsServiceServer(s grpc.ServiceRegistrar, srv AlertSettingsServiceServer) {
+	s.RegisterService(&AlertSettingsService_ServiceDesc, srv)
+}
+
+func _AlertSettingsService_GetAlertSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAlertSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlertSettingsServiceServer).GetAlertSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlertSettingsService_GetAlertSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlertSettingsServiceServer).GetAlertSettings(ctx, req.(*GetAlertSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AlertSettingsService_UpdateAlertSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAlertSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlertSettingsServiceServer).UpdateAlertSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AlertSettingsService_UpdateAlertSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlertSettingsServiceServer).UpdateAlertSettings(ctx, req.(*UpdateAlertSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AlertSettingsService_ServiceDesc is the grpc.ServiceDesc for AlertSettingsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AlertSettingsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "transaction.v1.AlertSettingsService",
+	HandlerType: (*AlertSettingsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAlertSettings",
+			Handler:    _AlertSettingsService_GetAlertSettings_Handler,
+		},
+		{
+			MethodName: "UpdateAlertSettings",
+			Handler:    _AlertSettingsService_UpdateAlertSettings_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "transaction/v1/alert.proto",
+}
@@ -27,6 +27,10 @@ const (
 	TransactionService_UpdateTransaction_FullMethodName             = "/transaction.v1.TransactionService/UpdateTransaction"
 	TransactionService_DeleteTransaction_FullMethodName             = "/transaction.v1.TransactionService/DeleteTransaction"
 	TransactionService_GetTransactionsWithPagination_FullMethodName = "/transaction.v1.TransactionService/GetTransactionsWithPagination"
+	TransactionService_SearchTransactions_FullMethodName            = "/transaction.v1.TransactionService/SearchTransactions"
+	TransactionService_ListTransactions_FullMethodName              = "/transaction.v1.TransactionService/ListTransactions"
+	TransactionService_StreamTransactions_FullMethodName            = "/transaction.v1.TransactionService/StreamTransactions"
+	TransactionService_SubscribeTransactions_FullMethodName         = "/transaction.v1.TransactionService/SubscribeTransactions"
 )
 
 // TransactionServiceClient is the client API for TransactionService service.
@@ -39,10 +43,16 @@ type TransactionServiceClient interface {
 	GetTransactionById(ctx context.Context, in *GetTransactionByIdRequest, opts ...grpc.CallOption) (*GetTransactionByIdResponse, error)
 	GetTransactionsByUserId(ctx context.Context, in *GetTransactionsByUserIdRequest, opts ...grpc.CallOption) (*GetTransactionsByUserIdResponse, error)
 	GetOwnTransactionById(ctx context.Context, in *GetOwnTransactionByIdRequest, opts ...grpc.CallOption) (*GetOwnTransactionByIdResponse, error)
+	// Deprecated: Do not use.
+	// Deprecated: returns every transaction in a single response and fails above a configured limit, 1000 by default, use StreamTransactions instead.
 	GetAllTransactions(ctx context.Context, in *GetAllTransactionsRequest, opts ...grpc.CallOption) (*GetAllTransactionsResponse, error)
 	UpdateTransaction(ctx context.Context, in *UpdateTransactionRequest, opts ...grpc.CallOption) (*UpdateTransactionResponse, error)
 	DeleteTransaction(ctx context.Context, in *DeleteTransactionRequest, opts ...grpc.CallOption) (*DeleteTransactionResponse, error)
 	GetTransactionsWithPagination(ctx context.Context, in *GetTransactionsWithPaginationRequest, opts ...grpc.CallOption) (*GetTransactionsWithPaginationResponse, error)
+	SearchTransactions(ctx context.Context, in *SearchTransactionsRequest, opts ...grpc.CallOption) (*SearchTransactionsResponse, error)
+	ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+	StreamTransactions(ctx context.Context, in *StreamTransactionsRequest, opts ...grpc.CallOption) (TransactionService_StreamTransactionsClient, error)
+	SubscribeTransactions(ctx context.Context, in *SubscribeTransactionsRequest, opts ...grpc.CallOption) (TransactionService_SubscribeTransactionsClient, error)
 }
 
 type transactionServiceClient struct {
@@ -93,6 +103,7 @@ func (c *transactionServiceClient) GetOwnTransactionById(ctx context.Context, in
 	return out, nil
 }
 
+// Deprecated: Do not use.
 func (c *transactionServiceClient) GetAllTransactions(ctx context.Context, in *GetAllTransactionsRequest, opts ...grpc.CallOption) (*GetAllTransactionsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetAllTransactionsResponse)
@@ -133,6 +144,92 @@ func (c *transactionServiceClient) GetTransactionsWithPagination(ctx context.Con
 	return out, nil
 }
 
+func (c *transactionServiceClient) SearchTransactions(ctx context.Context, in *SearchTransactionsRequest, opts ...grpc.CallOption) (*SearchTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchTransactionsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_SearchTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTransactionsResponse)
+	err := c.cc.Invoke(ctx, TransactionService_ListTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *transactionServiceClient) StreamTransactions(ctx context.Context, in *StreamTransactionsRequest, opts ...grpc.CallOption) (TransactionService_StreamTransactionsClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TransactionService_ServiceDesc.Streams[0], TransactionService_StreamTransactions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &transactionServiceStreamTransactionsClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TransactionService_StreamTransactionsClient interface {
+	Recv() (*StreamTransactionsResponse, error)
+	grpc.ClientStream
+}
+
+type transactionServiceStreamTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *transactionServiceStreamTransactionsClient) Recv() (*StreamTransactionsResponse, error) {
+	m := new(StreamTransactionsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *transactionServiceClient) SubscribeTransactions(ctx context.Context, in *SubscribeTransactionsRequest, opts ...grpc.CallOption) (TransactionService_SubscribeTransactionsClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TransactionService_ServiceDesc.Streams[1], TransactionService_SubscribeTransactions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &transactionServiceSubscribeTransactionsClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TransactionService_SubscribeTransactionsClient interface {
+	Recv() (*SubscribeTransactionsResponse, error)
+	grpc.ClientStream
+}
+
+type transactionServiceSubscribeTransactionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *transactionServiceSubscribeTransactionsClient) Recv() (*SubscribeTransactionsResponse, error) {
+	m := new(SubscribeTransactionsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // TransactionServiceServer is the server API for TransactionService service.
 // All implementations must embed UnimplementedTransactionServiceServer
 // for forward compatibility
@@ -143,10 +240,16 @@ type TransactionServiceServer interface {
 	GetTransactionById(context.Context, *GetTransactionByIdRequest) (*GetTransactionByIdResponse, error)
 	GetTransactionsByUserId(context.Context, *GetTransactionsByUserIdRequest) (*GetTransactionsByUserIdResponse, error)
 	GetOwnTransactionById(context.Context, *GetOwnTransactionByIdRequest) (*GetOwnTransactionByIdResponse, error)
+	// Deprecated: Do not use.
+	// Deprecated: returns every transaction in a single response and fails above a configured limit, 1000 by default, use StreamTransactions instead.
 	GetAllTransactions(context.Context, *GetAllTransactionsRequest) (*GetAllTransactionsResponse, error)
 	UpdateTransaction(context.Context, *UpdateTransactionRequest) (*UpdateTransactionResponse, error)
 	DeleteTransaction(context.Context, *DeleteTransactionRequest) (*DeleteTransactionResponse, error)
 	GetTransactionsWithPagination(context.Context, *GetTransactionsWithPaginationRequest) (*GetTransactionsWithPaginationResponse, error)
+	SearchTransactions(context.Context, *SearchTransactionsRequest) (*SearchTransactionsResponse, error)
+	ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	StreamTransactions(*StreamTransactionsRequest, TransactionService_StreamTransactionsServer) error
+	SubscribeTransactions(*SubscribeTransactionsRequest, TransactionService_SubscribeTransactionsServer) error
 	mustEmbedUnimplementedTransactionServiceServer()
 }
 
@@ -178,6 +281,18 @@ func (UnimplementedTransactionServiceServer) DeleteTransaction(context.Context,
 func (UnimplementedTransactionServiceServer) GetTransactionsWithPagination(context.Context, *GetTransactionsWithPaginationRequest) (*GetTransactionsWithPaginationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionsWithPagination not implemented")
 }
+func (UnimplementedTransactionServiceServer) SearchTransactions(context.Context, *SearchTransactionsRequest) (*SearchTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchTransactions not implemented")
+}
+func (UnimplementedTransactionServiceServer) ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTransactions not implemented")
+}
+func (UnimplementedTransactionServiceServer) StreamTransactions(*StreamTransactionsRequest, TransactionService_StreamTransactionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTransactions not implemented")
+}
+func (UnimplementedTransactionServiceServer) SubscribeTransactions(*SubscribeTransactionsRequest, TransactionService_SubscribeTransactionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeTransactions not implemented")
+}
 func (UnimplementedTransactionServiceServer) mustEmbedUnimplementedTransactionServiceServer() {}
 
 // UnsafeTransactionServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -335,6 +450,84 @@ func _TransactionService_GetTransactionsWithPagination_Handler(srv interface{},
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TransactionService_SearchTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).SearchTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_SearchTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).SearchTransactions(ctx, req.(*SearchTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_ListTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransactionServiceServer).ListTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransactionService_ListTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransactionServiceServer).ListTransactions(ctx, req.(*ListTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TransactionService_StreamTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransactionServiceServer).StreamTransactions(m, &transactionServiceStreamTransactionsServer{ServerStream: stream})
+}
+
+type TransactionService_StreamTransactionsServer interface {
+	Send(*StreamTransactionsResponse) error
+	grpc.ServerStream
+}
+
+type transactionServiceStreamTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *transactionServiceStreamTransactionsServer) Send(m *StreamTransactionsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TransactionService_SubscribeTransactions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeTransactionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransactionServiceServer).SubscribeTransactions(m, &transactionServiceSubscribeTransactionsServer{ServerStream: stream})
+}
+
+type TransactionService_SubscribeTransactionsServer interface {
+	Send(*SubscribeTransactionsResponse) error
+	grpc.ServerStream
+}
+
+type transactionServiceSubscribeTransactionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *transactionServiceSubscribeTransactionsServer) Send(m *SubscribeTransactionsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // TransactionService_ServiceDesc is the grpc.ServiceDesc for TransactionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -374,7 +567,26 @@ var TransactionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTransactionsWithPagination",
 			Handler:    _TransactionService_GetTransactionsWithPagination_Handler,
 		},
+		{
+			MethodName: "SearchTransactions",
+			Handler:    _TransactionService_SearchTransactions_Handler,
+		},
+		{
+			MethodName: "ListTransactions",
+			Handler:    _TransactionService_ListTransactions_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTransactions",
+			Handler:       _TransactionService_StreamTransactions_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeTransactions",
+			Handler:       _TransactionService_SubscribeTransactions_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "transaction/v1/transaction.proto",
 }
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	grpcDriver "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RetryAfterHeader carries the whole seconds until a rejected call may be retried.
+const RetryAfterHeader = "retry-after"
+
+// Limiter rejects calls exceeding the limit of their method with
+// ResourceExhausted. Every caller has its own bucket per method, keyed by
+// Subject by default. Methods without a limit use the default limit, if any.
+type Limiter struct {
+	store        Store
+	limits       map[string]model.RateLimit
+	defaultLimit *model.RateLimit
+	subject      func(ctx context.Context) string
+	now          func() time.Time
+}
+
+// LimiterOption configures a Limiter.
+type LimiterOption func(*Limiter)
+
+// WithLimit limits the full method, e.g. "/transaction.v1.TransactionService/CreateTransaction".
+func WithLimit(method string, limit model.RateLimit) LimiterOption {
+	return func(l *Limiter) {
+		l.limits[method] = limit
+	}
+}
+
+func WithDefaultLimit(limit model.RateLimit) LimiterOption {
+	return func(l *Limiter) {
+		l.defaultLimit = &limit
+	}
+}
+
+// WithSubject replaces how callers are told apart, e.g. with PeerSubject for a
+// limiter running before authentication.
+func WithSubject(subject func(ctx context.Context) string) LimiterOption {
+	return func(l *Limiter) {
+		l.subject = subject
+	}
+}
+
+func WithLimiterClock(now func() time.Time) LimiterOption {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+func NewLimiter(store Store, options ...LimiterOption) *Limiter {
+	l := &Limiter{store: store, limits: make(map[string]model.RateLimit), subject: Subject, now: time.Now}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+func (l *Limiter) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if retryAfter, limited := l.limited(ctx, info.FullMethod); limited {
+			_ = grpc.SetHeader(ctx, retryAfterHeader(retryAfter))
+			return nil, rateLimited(info.FullMethod, retryAfter)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (l *Limiter) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if retryAfter, limited := l.limited(stream.Context(), info.FullMethod); limited {
+			_ = stream.SetHeader(retryAfterHeader(retryAfter))
+			return rateLimited(info.FullMethod, retryAfter)
+		}
+		return handler(srv, stream)
+	}
+}
+
+// limited takes a token for the caller of method. Calls are let through when
+// the store fails, so an unavailable database does not reject every call.
+func (l *Limiter) limited(ctx context.Context, method string) (time.Duration, bool) {
+	limit, ok := l.limits[method]
+	if !ok {
+		if l.defaultLimit == nil {
+			return 0, false
+		}
+		limit = *l.defaultLimit
+	}
+
+	allowed, retryAfter, err := l.store.Take(ctx, l.subject(ctx)+" "+method, limit, l.now())
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to check rate limit", "method", method, "error", err)
+		return 0, false
+	}
+	return retryAfter, !allowed
+}
+
+// Subject identifies the caller: the authenticated user, API key or client
+// certificate, else the address of the peer.
+func Subject(ctx context.Context) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		switch {
+		case principal.UserId != "":
+			return "user:" + principal.UserId
+		case principal.ApiKeyId != "":
+			return "api_key:" + principal.ApiKeyId
+		case principal.ClientIdentity != "":
+			return "client:" + principal.ClientIdentity
+		}
+	}
+	return PeerSubject(ctx)
+}
+
+// PeerSubject identifies the caller by the address of the peer only.
+func PeerSubject(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "peer:" + host
+	}
+	return "unknown"
+}
+
+func retryAfterSeconds(retryAfter time.Duration) int64 {
+	return int64(math.Ceil(retryAfter.Seconds()))
+}
+
+func retryAfterHeader(retryAfter time.Duration) metadata.MD {
+	return metadata.Pairs(RetryAfterHeader, strconv.FormatInt(retryAfterSeconds(retryAfter), 10))
+}
+
+func rateLimited(method string, retryAfter time.Duration) error {
+	s := status.New(codes.ResourceExhausted, "rate limit exceeded, retry later")
+	detailed, err := s.WithDetails(
+		&errdetails.ErrorInfo{Reason: "RATE_LIMITED", Domain: grpcDriver.ErrorDomain, Metadata: map[string]string{"method": method}},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Duration(retryAfterSeconds(retryAfter)) * time.Second)},
+	)
+	if err != nil {
+		return s.Err()
+	}
+	return detailed.Err()
+}
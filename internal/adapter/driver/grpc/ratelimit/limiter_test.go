@@ -0,0 +1,154 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/ratelimit"
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const createTransaction = "/transaction.v1.TransactionService/CreateTransaction"
+
+func TestStores(t *testing.T) {
+	repo := repository.NewInMemoryRateLimitRepository()
+	stores := map[string]ratelimit.Store{
+		"memory":     ratelimit.NewMemoryStore(),
+		"repository": ratelimit.NewRepositoryStore(&db.PostgresTransactionMockFactory{}, repository.NewInMemoryRateLimitRepositoryFactory(repo)),
+	}
+	limit := model.RateLimit{Rate: 2, Burst: 3}
+
+	for name, store := range stores {
+		ctx := context.Background()
+		now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+		for i := 0; i < 3; i++ {
+			allowed, _, err := store.Take(ctx, "a", limit, now)
+			assert.NoError(t, err)
+			assert.True(t, allowed, name)
+		}
+		allowed, retryAfter, err := store.Take(ctx, "a", limit, now)
+		assert.NoError(t, err)
+		assert.False(t, allowed, name)
+		assert.Equal(t, 500*time.Millisecond, retryAfter, name)
+
+		// Other keys have their own bucket.
+		allowed, _, _ = store.Take(ctx, "b", limit, now)
+		assert.True(t, allowed, name)
+
+		// Tokens are refilled at the rate.
+		allowed, _, _ = store.Take(ctx, "a", limit, now.Add(500*time.Millisecond))
+		assert.True(t, allowed, name)
+		allowed, retryAfter, _ = store.Take(ctx, "a", limit, now.Add(750*time.Millisecond))
+		assert.False(t, allowed, name)
+		assert.Equal(t, 250*time.Millisecond, retryAfter, name)
+
+		// Buckets are dropped once full again.
+		allowed, _, _ = store.Take(ctx, "c", limit, now.Add(time.Hour))
+		assert.True(t, allowed, name)
+	}
+	assert.Equal(t, 1, stores["memory"].(*ratelimit.MemoryStore).Len())
+	assert.Equal(t, 1, repo.Len())
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(),
+		ratelimit.WithLimit(createTransaction, model.RateLimit{Rate: 0.5, Burst: 1}),
+		ratelimit.WithDefaultLimit(model.RateLimit{Rate: 100, Burst: 100}),
+		ratelimit.WithLimiterClock(func() time.Time { return now }),
+	)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	call := func(ctx context.Context, method string) error {
+		_, err := limiter.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+	user := func(id string) context.Context {
+		return auth.NewContext(context.Background(), auth.Principal{UserId: id})
+	}
+
+	assert.NoError(t, call(user("alice"), createTransaction))
+	err := call(user("alice"), createTransaction)
+	s := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, s.Code())
+	assert.Len(t, s.Details(), 2)
+	assert.Equal(t, "RATE_LIMITED", s.Details()[0].(*errdetails.ErrorInfo).Reason)
+	assert.Equal(t, 2*time.Second, s.Details()[1].(*errdetails.RetryInfo).RetryDelay.AsDuration())
+
+	// Limits are per caller and per method.
+	assert.NoError(t, call(user("bob"), createTransaction))
+	assert.NoError(t, call(user("alice"), "/transaction.v1.TransactionService/GetTransactionById"))
+
+	now = now.Add(2 * time.Second)
+	assert.NoError(t, call(user("alice"), createTransaction))
+
+	// Streams are limited when they start.
+	stream := func(srv interface{}, stream grpc.ServerStream) error { return nil }
+	info := &grpc.StreamServerInfo{FullMethod: createTransaction}
+	err = limiter.Stream()(nil, testStream{ctx: user("carol")}, info, stream)
+	assert.NoError(t, err)
+	err = limiter.Stream()(nil, testStream{ctx: user("carol")}, info, stream)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	unlimited := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.WithLimit(createTransaction, model.RateLimit{Rate: 1, Burst: 1}))
+	for i := 0; i < 3; i++ {
+		_, err := unlimited.Unary()(user("alice"), nil, &grpc.UnaryServerInfo{FullMethod: "/transaction.v1.TransactionService/ListTransactions"}, handler)
+		assert.NoError(t, err)
+	}
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s testStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s testStream) SetHeader(md metadata.MD) error {
+	return nil
+}
+
+func TestSubject(t *testing.T) {
+	fromPeer := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234}})
+
+	assert.Equal(t, "user:alice", ratelimit.Subject(auth.NewContext(fromPeer, auth.Principal{UserId: "alice", ClientIdentity: "spiffe://finman/gateway"})))
+	assert.Equal(t, "api_key:key-1", ratelimit.Subject(auth.NewContext(fromPeer, auth.Principal{ApiKeyId: "key-1", Service: "reporting"})))
+	assert.Equal(t, "client:spiffe://finman/reporting", ratelimit.Subject(auth.NewContext(fromPeer, auth.Principal{ClientIdentity: "spiffe://finman/reporting"})))
+	assert.Equal(t, "peer:10.0.0.7", ratelimit.Subject(fromPeer))
+	assert.Equal(t, "unknown", ratelimit.Subject(context.Background()))
+	assert.Equal(t, "peer:10.0.0.7", ratelimit.PeerSubject(auth.NewContext(fromPeer, auth.Principal{UserId: "alice"})))
+}
+
+func TestPeerLimiter(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.WithDefaultLimit(model.RateLimit{Rate: 1, Burst: 2}), ratelimit.WithSubject(ratelimit.PeerSubject))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	call := func(ip string) error {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 51234}})
+		_, err := limiter.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: createTransaction}, handler)
+		return err
+	}
+
+	// Unauthenticated calls of a peer share its bucket, other peers have their own
+	assert.NoError(t, call("10.0.0.7"))
+	assert.NoError(t, call("10.0.0.7"))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call("10.0.0.7")))
+	assert.NoError(t, call("10.0.0.8"))
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+const sweepInterval = time.Minute
+
+// Store keeps the token buckets of the rate limited keys.
+type Store interface {
+	// Take takes a token from the bucket of key. Without a token left it
+	// returns how long until one is.
+	Take(ctx context.Context, key string, limit model.RateLimit, now time.Time) (bool, time.Duration, error)
+}
+
+// MemoryStore limits every replica on its own.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	sweptAt time.Time
+}
+
+type memoryBucket struct {
+	bucket model.TokenBucket
+	fullAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*memoryBucket)}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit model.RateLimit, now time.Time) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.sweptAt) >= sweepInterval {
+		s.sweptAt = now
+		for k, b := range s.buckets {
+			if b.fullAt.Before(now) {
+				delete(s.buckets, k)
+			}
+		}
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{bucket: model.NewTokenBucket(key, limit, now)}
+		s.buckets[key] = b
+	}
+	allowed, retryAfter := b.bucket.Take(limit, now)
+	b.fullAt = b.bucket.FullAt(limit)
+	return allowed, retryAfter, nil
+}
+
+// Len returns the number of buckets that are not full.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.buckets)
+}
+
+// RepositoryStore shares the buckets between replicas through the database.
+// Each take locks the row of its bucket, so requests for the same key are
+// serialized across replicas.
+type RepositoryStore struct {
+	dbTransactionFactory       db.DbTransactionFactory
+	rateLimitRepositoryFactory repository.RateLimitRepositoryFactory
+
+	mu      sync.Mutex
+	sweptAt time.Time
+}
+
+func NewRepositoryStore(dtf db.DbTransactionFactory, rrf repository.RateLimitRepositoryFactory) *RepositoryStore {
+	return &RepositoryStore{dbTransactionFactory: dtf, rateLimitRepositoryFactory: rrf}
+}
+
+func (s *RepositoryStore) Take(ctx context.Context, key string, limit model.RateLimit, now time.Time) (bool, time.Duration, error) {
+	tx := s.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := s.rateLimitRepositoryFactory.New(handler)
+	initial := model.NewTokenBucket(key, limit, now)
+	bucket, err := repository.LockBucket(ctx, initial, initial.FullAt(limit))
+	if err != nil {
+		return false, 0, err
+	}
+	allowed, retryAfter := bucket.Take(limit, now)
+	if err := repository.SaveBucket(ctx, *bucket, bucket.FullAt(limit)); err != nil {
+		return false, 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, 0, err
+	}
+
+	s.sweep(ctx, now)
+	return allowed, retryAfter, nil
+}
+
+// sweep deletes full buckets at most once a minute per replica.
+func (s *RepositoryStore) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.sweptAt) < sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.sweptAt = now
+	s.mu.Unlock()
+
+	tx := s.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete full rate limit buckets", "error", err)
+		return
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+	if err := s.rateLimitRepositoryFactory.New(handler).DeleteFullBuckets(ctx, now); err != nil {
+		slog.ErrorContext(ctx, "failed to delete full rate limit buckets", "error", err)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		slog.ErrorContext(ctx, "failed to delete full rate limit buckets", "error", err)
+	}
+}
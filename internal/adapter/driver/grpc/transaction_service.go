@@ -7,7 +7,6 @@ import (
 	transactionv1 "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/proto/transaction/v1"
 	"github.com/nullexp/finman-transaction-service/internal/port/driver"
 	"github.com/nullexp/finman-transaction-service/internal/port/model"
-	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -47,6 +46,99 @@ func CastTransactionsToProtoArray(transactions []model.Transaction) []*transacti
 	return protoTransactions
 }
 
+func CastSearchResultsToProtoArray(results []model.TransactionSearchResult) []*transactionv1.SearchTransactionResult {
+	var protoResults []*transactionv1.SearchTransactionResult
+	for _, result := range results {
+		protoResults = append(protoResults, &transactionv1.SearchTransactionResult{
+			Transaction: CastTransactionToProto(&result.Transaction),
+			Rank:        result.Rank,
+		})
+	}
+
+	return protoResults
+}
+
+// parseOptionalTime parses an optional RFC3339 timestamp, an empty string means not set.
+func parseOptionalTime(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, invalidArgument(field, "must be an RFC3339 timestamp")
+	}
+	return &t, nil
+}
+
+var sortFields = map[transactionv1.TransactionSortField]string{
+	transactionv1.TransactionSortField_TRANSACTION_SORT_FIELD_DATE:       "date",
+	transactionv1.TransactionSortField_TRANSACTION_SORT_FIELD_AMOUNT:     "amount",
+	transactionv1.TransactionSortField_TRANSACTION_SORT_FIELD_TYPE:       "type",
+	transactionv1.TransactionSortField_TRANSACTION_SORT_FIELD_CREATED_AT: "created_at",
+	transactionv1.TransactionSortField_TRANSACTION_SORT_FIELD_UPDATED_AT: "updated_at",
+}
+
+var sortDirections = map[transactionv1.SortDirection]string{
+	transactionv1.SortDirection_SORT_DIRECTION_ASC:  "asc",
+	transactionv1.SortDirection_SORT_DIRECTION_DESC: "desc",
+}
+
+var eventTypes = map[string]transactionv1.TransactionEventType{
+	"created":              transactionv1.TransactionEventType_TRANSACTION_EVENT_TYPE_CREATED,
+	"updated":              transactionv1.TransactionEventType_TRANSACTION_EVENT_TYPE_UPDATED,
+	"deleted":              transactionv1.TransactionEventType_TRANSACTION_EVENT_TYPE_DELETED,
+	"insufficient_balance": transactionv1.TransactionEventType_TRANSACTION_EVENT_TYPE_INSUFFICIENT_BALANCE,
+	"daily_digest":         transactionv1.TransactionEventType_TRANSACTION_EVENT_TYPE_DAILY_DIGEST,
+	"low_balance":          transactionv1.TransactionEventType_TRANSACTION_EVENT_TYPE_LOW_BALANCE,
+	"large_transaction":    transactionv1.TransactionEventType_TRANSACTION_EVENT_TYPE_LARGE_TRANSACTION,
+}
+
+func CastTransactionEventToProto(event *model.TransactionEvent) *transactionv1.SubscribeTransactionsResponse {
+	return &transactionv1.SubscribeTransactionsResponse{
+		EventId:     event.Id,
+		Type:        eventTypes[event.Type],
+		Transaction: CastTransactionToProto(&event.Transaction),
+		OccurredAt:  event.OccurredAt.Format(time.RFC3339),
+		ResumeToken: event.ResumeToken,
+	}
+}
+
+func CastProtoToFilter(filter *transactionv1.TransactionFilter) (model.TransactionFilter, error) {
+	if filter == nil {
+		return model.TransactionFilter{}, nil
+	}
+
+	result := model.TransactionFilter{
+		UserIds:             filter.UserIds,
+		Types:               filter.Types,
+		MinAmount:           filter.MinAmount,
+		MaxAmount:           filter.MaxAmount,
+		DescriptionContains: filter.DescriptionContains,
+	}
+
+	times := []struct {
+		field  string
+		value  string
+		target **time.Time
+	}{
+		{"filter.from_date", filter.FromDate, &result.FromDate},
+		{"filter.to_date", filter.ToDate, &result.ToDate},
+		{"filter.created_from", filter.CreatedFrom, &result.CreatedFrom},
+		{"filter.created_to", filter.CreatedTo, &result.CreatedTo},
+		{"filter.updated_from", filter.UpdatedFrom, &result.UpdatedFrom},
+		{"filter.updated_to", filter.UpdatedTo, &result.UpdatedTo},
+	}
+	for _, t := range times {
+		parsed, err := parseOptionalTime(t.field, t.value)
+		if err != nil {
+			return model.TransactionFilter{}, err
+		}
+		*t.target = parsed
+	}
+
+	return result, nil
+}
+
 func (ts TransactionService) CreateTransaction(ctx context.Context, request *transactionv1.CreateTransactionRequest) (*transactionv1.CreateTransactionResponse, error) {
 	rs, err := ts.service.CreateTransaction(ctx, model.CreateTransactionRequest{
 		UserId:      request.UserId,
@@ -55,7 +147,7 @@ func (ts TransactionService) CreateTransaction(ctx context.Context, request *tra
 		Description: request.Description,
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "CreateTransaction failed : %v", err)
+		return nil, toStatus(ctx, "CreateTransaction", err)
 	}
 
 	return &transactionv1.CreateTransactionResponse{Id: rs.Id}, nil
@@ -64,25 +156,29 @@ func (ts TransactionService) CreateTransaction(ctx context.Context, request *tra
 func (ts TransactionService) GetTransactionById(ctx context.Context, request *transactionv1.GetTransactionByIdRequest) (*transactionv1.GetTransactionByIdResponse, error) {
 	rs, err := ts.service.GetTransactionById(ctx, model.GetTransactionByIdRequest{Id: request.Id})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "GetTransactionById failed : %v", err)
+		return nil, toStatus(ctx, "GetTransactionById", err)
 	}
 
 	return &transactionv1.GetTransactionByIdResponse{Transaction: CastTransactionToProto(&rs.Transaction)}, nil
 }
 
 func (ts TransactionService) GetTransactionsByUserId(ctx context.Context, request *transactionv1.GetTransactionsByUserIdRequest) (*transactionv1.GetTransactionsByUserIdResponse, error) {
-	rs, err := ts.service.GetTransactionsByUserId(ctx, model.GetTransactionsByUserIdRequest{UserId: request.UserId})
+	rs, err := ts.service.GetTransactionsByUserId(ctx, model.GetTransactionsByUserIdRequest{
+		UserId:    request.UserId,
+		PageSize:  int(request.PageSize),
+		PageToken: request.PageToken,
+	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "GetTransactionsByUserId failed : %v", err)
+		return nil, toStatus(ctx, "GetTransactionsByUserId", err)
 	}
 
-	return &transactionv1.GetTransactionsByUserIdResponse{Transactions: CastTransactionsToProtoArray(rs.Transactions)}, nil
+	return &transactionv1.GetTransactionsByUserIdResponse{Transactions: CastTransactionsToProtoArray(rs.Transactions), NextPageToken: rs.NextPageToken}, nil
 }
 
 func (ts TransactionService) GetOwnTransactionById(ctx context.Context, request *transactionv1.GetOwnTransactionByIdRequest) (*transactionv1.GetOwnTransactionByIdResponse, error) {
-	rs, err := ts.service.GetOwnTransactionById(ctx, model.GetOwnTransactionByIdRequest{UserId: request.UserId, Id: request.Id})
+	rs, err := ts.service.GetOwnTransactionById(ctx, model.GetOwnTransactionByIdRequest{UserId: ownUserId(ctx, request.UserId), Id: request.Id})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "GetOwnTransactionById failed : %v", err)
+		return nil, toStatus(ctx, "GetOwnTransactionById", err)
 	}
 
 	return &transactionv1.GetOwnTransactionByIdResponse{Transaction: CastTransactionToProto(&rs.Transaction)}, nil
@@ -91,7 +187,7 @@ func (ts TransactionService) GetOwnTransactionById(ctx context.Context, request
 func (ts TransactionService) GetAllTransactions(ctx context.Context, request *transactionv1.GetAllTransactionsRequest) (*transactionv1.GetAllTransactionsResponse, error) {
 	rs, err := ts.service.GetAllTransactions(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "GetAllTransactions failed : %v", err)
+		return nil, toStatus(ctx, "GetAllTransactions", err)
 	}
 
 	return &transactionv1.GetAllTransactionsResponse{Transactions: CastTransactionsToProtoArray(rs.Transactions)}, nil
@@ -106,7 +202,7 @@ func (ts TransactionService) UpdateTransaction(ctx context.Context, request *tra
 		Description: request.Description,
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "UpdateTransaction failed : %v", err)
+		return nil, toStatus(ctx, "UpdateTransaction", err)
 	}
 
 	return &transactionv1.UpdateTransactionResponse{}, nil
@@ -115,7 +211,7 @@ func (ts TransactionService) UpdateTransaction(ctx context.Context, request *tra
 func (ts TransactionService) DeleteTransaction(ctx context.Context, request *transactionv1.DeleteTransactionRequest) (*transactionv1.DeleteTransactionResponse, error) {
 	err := ts.service.DeleteTransaction(ctx, model.DeleteTransactionRequest{Id: request.Id})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "DeleteTransaction failed : %v", err)
+		return nil, toStatus(ctx, "DeleteTransaction", err)
 	}
 
 	return &transactionv1.DeleteTransactionResponse{}, nil
@@ -124,8 +220,103 @@ func (ts TransactionService) DeleteTransaction(ctx context.Context, request *tra
 func (ts TransactionService) GetTransactionsWithPagination(ctx context.Context, request *transactionv1.GetTransactionsWithPaginationRequest) (*transactionv1.GetTransactionsWithPaginationResponse, error) {
 	rs, err := ts.service.GetTransactionsWithPagination(ctx, model.GetTransactionsWithPaginationRequest{Offset: int(request.Offset), Limit: int(request.Limit)})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "GetTransactionsWithPagination failed : %v", err)
+		return nil, toStatus(ctx, "GetTransactionsWithPagination", err)
 	}
 
 	return &transactionv1.GetTransactionsWithPaginationResponse{Transactions: CastTransactionsToProtoArray(rs.Transactions)}, nil
 }
+
+func (ts TransactionService) SearchTransactions(ctx context.Context, request *transactionv1.SearchTransactionsRequest) (*transactionv1.SearchTransactionsResponse, error) {
+	fromDate, err := parseOptionalTime("from_date", request.FromDate)
+	if err != nil {
+		return nil, err
+	}
+	toDate, err := parseOptionalTime("to_date", request.ToDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := ts.service.SearchTransactions(ctx, model.SearchTransactionsRequest{
+		UserId:    request.UserId,
+		Query:     request.Query,
+		FromDate:  fromDate,
+		ToDate:    toDate,
+		MinAmount: request.MinAmount,
+		MaxAmount: request.MaxAmount,
+		PageSize:  int(request.PageSize),
+		PageToken: request.PageToken,
+	})
+	if err != nil {
+		return nil, toStatus(ctx, "SearchTransactions", err)
+	}
+
+	return &transactionv1.SearchTransactionsResponse{Results: CastSearchResultsToProtoArray(rs.Results), NextPageToken: rs.NextPageToken}, nil
+}
+
+func (ts TransactionService) ListTransactions(ctx context.Context, request *transactionv1.ListTransactionsRequest) (*transactionv1.ListTransactionsResponse, error) {
+	filter, err := CastProtoToFilter(request.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := ts.service.ListTransactions(ctx, model.ListTransactionsRequest{
+		Filter:            filter,
+		SortBy:            sortFields[request.SortField],
+		SortOrder:         sortDirections[request.SortDirection],
+		PageSize:          int(request.PageSize),
+		PageToken:         request.PageToken,
+		IncludeTotalCount: request.IncludeTotalCount,
+	})
+	if err != nil {
+		return nil, toStatus(ctx, "ListTransactions", err)
+	}
+
+	return &transactionv1.ListTransactionsResponse{
+		Transactions:  CastTransactionsToProtoArray(rs.Transactions),
+		TotalCount:    rs.TotalCount,
+		NextPageToken: rs.NextPageToken,
+	}, nil
+}
+
+func (ts TransactionService) StreamTransactions(request *transactionv1.StreamTransactionsRequest, stream transactionv1.TransactionService_StreamTransactionsServer) error {
+	filter, err := CastProtoToFilter(request.Filter)
+	if err != nil {
+		return err
+	}
+
+	// Send blocks while the client's flow control window is full, which in turn
+	// pauses reading from the database cursor.
+	err = ts.service.StreamTransactions(stream.Context(), model.StreamTransactionsRequest{
+		Filter:    filter,
+		SortBy:    sortFields[request.SortField],
+		SortOrder: sortDirections[request.SortDirection],
+		BatchSize: int(request.BatchSize),
+	}, func(transactions []model.Transaction) error {
+		return stream.Send(&transactionv1.StreamTransactionsResponse{Transactions: CastTransactionsToProtoArray(transactions)})
+	})
+	if err != nil {
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		return toStatus(stream.Context(), "StreamTransactions", err)
+	}
+
+	return nil
+}
+
+func (ts TransactionService) SubscribeTransactions(request *transactionv1.SubscribeTransactionsRequest, stream transactionv1.TransactionService_SubscribeTransactionsServer) error {
+	err := ts.service.SubscribeTransactions(stream.Context(), model.SubscribeTransactionsRequest{
+		UserId:      request.UserId,
+		ResumeToken: request.ResumeToken,
+	}, func(event model.TransactionEvent) error {
+		return stream.Send(CastTransactionEventToProto(&event))
+	})
+	if err != nil {
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		return toStatus(stream.Context(), "SubscribeTransactions", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// Mode selects the transport security of the server.
+type Mode string
+
+const (
+	// ModeInsecure serves plaintext, for local development only.
+	ModeInsecure Mode = "none"
+	// ModeTLS encrypts connections with the server certificate.
+	ModeTLS Mode = "tls"
+	// ModeMutualTLS also requires a client certificate signed by the client CA.
+	ModeMutualTLS Mode = "mtls"
+
+	defaultReloadInterval = 30 * time.Second
+)
+
+// Config names the PEM files of the server certificate, its key and, for
+// ModeMutualTLS, the CA bundle client certificates are verified with.
+type Config struct {
+	Mode         Mode
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// CertificateReloader serves the certificates of a Config and picks up rotated
+// files on the first handshake after they changed, so certificates can be
+// renewed without restarting. Files are checked at most once per interval and
+// a failed reload keeps the previous certificates.
+type CertificateReloader struct {
+	config   Config
+	interval time.Duration
+	now      func() time.Time
+
+	mu          sync.Mutex
+	checkedAt   time.Time
+	versions    []fileVersion
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+}
+
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// ReloaderOption configures a CertificateReloader.
+type ReloaderOption func(*CertificateReloader)
+
+func WithReloadInterval(interval time.Duration) ReloaderOption {
+	return func(r *CertificateReloader) {
+		r.interval = interval
+	}
+}
+
+func WithReloaderClock(now func() time.Time) ReloaderOption {
+	return func(r *CertificateReloader) {
+		r.now = now
+	}
+}
+
+// NewCertificateReloader loads the files of config, failing when they are
+// missing or invalid.
+func NewCertificateReloader(config Config, options ...ReloaderOption) (*CertificateReloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("a certificate and key file are required")
+	}
+	if config.Mode == ModeMutualTLS && config.ClientCAFile == "" {
+		return nil, errors.New("mutual TLS requires a client CA file")
+	}
+
+	r := &CertificateReloader{config: config, interval: defaultReloadInterval, now: time.Now}
+	for _, option := range options {
+		option(r)
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.checkedAt = r.now()
+	return r, nil
+}
+
+func (r *CertificateReloader) files() []string {
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.Mode == ModeMutualTLS {
+		files = append(files, r.config.ClientCAFile)
+	}
+	return files
+}
+
+func (r *CertificateReloader) stat() ([]fileVersion, error) {
+	var versions []fileVersion
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, fileVersion{modTime: info.ModTime(), size: info.Size()})
+	}
+	return versions, nil
+}
+
+func (r *CertificateReloader) load() error {
+	versions, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.config.Mode == ModeMutualTLS {
+		data, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates found in %s", r.config.ClientCAFile)
+		}
+	}
+
+	r.versions = versions
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	return nil
+}
+
+// reload loads the files again when they changed since the last check.
+func (r *CertificateReloader) reload() {
+	now := r.now()
+	if now.Sub(r.checkedAt) < r.interval {
+		return
+	}
+	r.checkedAt = now
+
+	versions, err := r.stat()
+	if err != nil {
+		slog.Error("failed to check TLS certificates", "error", err)
+		return
+	}
+	changed := false
+	for i, version := range versions {
+		if version != r.versions[i] {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := r.load(); err != nil {
+		slog.Error("failed to reload TLS certificates, keeping the previous ones", "error", err)
+		return
+	}
+	slog.Info("reloaded TLS certificates")
+}
+
+// TLSConfig returns the server configuration, built anew for every handshake
+// from the current certificates.
+func (r *CertificateReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.reload()
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.certificate},
+				NextProtos:   []string{"h2"},
+			}
+			if r.config.Mode == ModeMutualTLS {
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+				config.ClientCAs = r.clientCAs
+			}
+			return config, nil
+		},
+	}
+}
+
+// NewServerCredentials returns the transport credentials for config, nil for
+// ModeInsecure.
+func NewServerCredentials(config Config, options ...ReloaderOption) (credentials.TransportCredentials, error) {
+	switch config.Mode {
+	case ModeInsecure:
+		return nil, nil
+	case ModeTLS, ModeMutualTLS:
+		reloader, err := NewCertificateReloader(config, options...)
+		if err != nil {
+			return nil, err
+		}
+		return credentials.NewTLS(reloader.TLSConfig()), nil
+	default:
+		return nil, fmt.Errorf("unknown TLS mode %q", config.Mode)
+	}
+}
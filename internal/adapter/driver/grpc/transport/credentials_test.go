@@ -0,0 +1,229 @@
+package transport_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/auth"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type authority struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	pem         []byte
+}
+
+func newAuthority(t *testing.T, name string) authority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return authority{certificate: certificate, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM encoded certificate and key of a leaf certificate.
+func (a authority) issue(t *testing.T, template *x509.Certificate) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, template, a.certificate, &key.PublicKey, a.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})
+}
+
+func (a authority) server(t *testing.T, name string) ([]byte, []byte) {
+	return a.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		DNSNames:    []string{"localhost"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+}
+
+func (a authority) client(t *testing.T, identity string) tls.Certificate {
+	uri, err := url.Parse(identity)
+	require.NoError(t, err)
+	certPEM, keyPEM := a.issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client"},
+		URIs:        []*url.URL{uri},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	return certificate
+}
+
+type fixture struct {
+	config   transport.Config
+	serverCA authority
+	clientCA authority
+	now      atomic.Int64
+	addr     string
+	identity atomic.Value
+}
+
+func newFixture(t *testing.T, mode transport.Mode) *fixture {
+	dir := t.TempDir()
+	f := &fixture{
+		config: transport.Config{
+			Mode:         mode,
+			CertFile:     filepath.Join(dir, "server.pem"),
+			KeyFile:      filepath.Join(dir, "server-key.pem"),
+			ClientCAFile: filepath.Join(dir, "client-ca.pem"),
+		},
+		serverCA: newAuthority(t, "server CA"),
+		clientCA: newAuthority(t, "client CA"),
+	}
+	f.now.Store(time.Now().UnixNano())
+	f.writeServerCertificate(t, "server 1")
+	require.NoError(t, os.WriteFile(f.config.ClientCAFile, f.clientCA.pem, 0o600))
+
+	creds, err := transport.NewServerCredentials(f.config, transport.WithReloaderClock(func() time.Time {
+		return time.Unix(0, f.now.Load())
+	}))
+	require.NoError(t, err)
+
+	server := grpc.NewServer(grpc.Creds(creds), grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		identity, _ := auth.ClientIdentity(ctx)
+		f.identity.Store(identity)
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	f.addr = lis.Addr().String()
+	return f
+}
+
+func (f *fixture) writeServerCertificate(t *testing.T, name string) {
+	certPEM, keyPEM := f.serverCA.server(t, name)
+	require.NoError(t, os.WriteFile(f.config.CertFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(f.config.KeyFile, keyPEM, 0o600))
+}
+
+// check calls the server and returns the common name of its certificate.
+func (f *fixture) check(t *testing.T, certificates ...tls.Certificate) (string, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(f.serverCA.certificate)
+	var serverName string
+	creds := credentials.NewTLS(&tls.Config{
+		RootCAs:      roots,
+		Certificates: certificates,
+		ServerName:   "localhost",
+		VerifyConnection: func(state tls.ConnectionState) error {
+			serverName = state.PeerCertificates[0].Subject.CommonName
+			return nil
+		},
+	})
+	conn, err := grpc.NewClient(f.addr, grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return serverName, err
+}
+
+func TestMutualTLS(t *testing.T) {
+	f := newFixture(t, transport.ModeMutualTLS)
+
+	_, err := f.check(t, f.clientCA.client(t, "spiffe://finman/reporting"))
+	assert.NoError(t, err)
+	assert.Equal(t, "spiffe://finman/reporting", f.identity.Load())
+
+	_, err = f.check(t)
+	assert.Error(t, err, "client without certificate")
+
+	untrusted := newAuthority(t, "untrusted CA")
+	_, err = f.check(t, untrusted.client(t, "spiffe://finman/reporting"))
+	assert.Error(t, err, "client with untrusted certificate")
+}
+
+func TestTLS(t *testing.T) {
+	f := newFixture(t, transport.ModeTLS)
+
+	_, err := f.check(t)
+	assert.NoError(t, err)
+	assert.Equal(t, "", f.identity.Load())
+}
+
+func TestCertificateReload(t *testing.T) {
+	f := newFixture(t, transport.ModeMutualTLS)
+	client := f.clientCA.client(t, "spiffe://finman/reporting")
+
+	name, err := f.check(t, client)
+	assert.NoError(t, err)
+	assert.Equal(t, "server 1", name)
+
+	// Changes are picked up once the reload interval passed.
+	f.writeServerCertificate(t, "server 2")
+	name, err = f.check(t, client)
+	assert.NoError(t, err)
+	assert.Equal(t, "server 1", name)
+
+	f.now.Add(int64(time.Minute))
+	name, err = f.check(t, client)
+	assert.NoError(t, err)
+	assert.Equal(t, "server 2", name)
+
+	// Broken files keep the previous certificate.
+	require.NoError(t, os.WriteFile(f.config.KeyFile, []byte("broken"), 0o600))
+	f.now.Add(int64(time.Minute))
+	name, err = f.check(t, client)
+	assert.NoError(t, err)
+	assert.Equal(t, "server 2", name)
+}
+
+func TestNewServerCredentials(t *testing.T) {
+	creds, err := transport.NewServerCredentials(transport.Config{Mode: transport.ModeInsecure})
+	assert.NoError(t, err)
+	assert.Nil(t, creds)
+
+	_, err = transport.NewServerCredentials(transport.Config{Mode: "plain"})
+	assert.Error(t, err)
+	_, err = transport.NewServerCredentials(transport.Config{Mode: transport.ModeMutualTLS, CertFile: "server.pem", KeyFile: "server-key.pem"})
+	assert.Error(t, err)
+	_, err = transport.NewServerCredentials(transport.Config{Mode: transport.ModeTLS, CertFile: "missing.pem", KeyFile: "missing-key.pem"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	transactionv1 "github.com/nullexp/finman-transaction-service/internal/adapter/driver/grpc/proto/transaction/v1"
+	"github.com/nullexp/finman-transaction-service/internal/port/driver"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+type WebhookService struct {
+	transactionv1.UnimplementedWebhookServiceServer
+	service driver.WebhookService
+}
+
+func NewWebhookService(ws driver.WebhookService) *WebhookService {
+	return &WebhookService{service: ws}
+}
+
+func CastWebhookSubscriptionToProto(subscription *model.WebhookSubscription) *transactionv1.WebhookSubscription {
+	protoSubscription := &transactionv1.WebhookSubscription{
+		Id:        subscription.Id,
+		UserId:    subscription.UserId,
+		Url:       subscription.Url,
+		Paused:    subscription.Paused,
+		CreatedAt: subscription.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: subscription.UpdatedAt.Format(time.RFC3339),
+	}
+	for _, eventType := range subscription.EventTypes {
+		protoSubscription.EventTypes = append(protoSubscription.EventTypes, eventTypes[eventType])
+	}
+	return protoSubscription
+}
+
+func CastWebhookDeliveryToProto(delivery *model.WebhookDelivery) *transactionv1.WebhookDelivery {
+	return &transactionv1.WebhookDelivery{
+		Id:          delivery.Id,
+		EventId:     delivery.EventId,
+		EventType:   eventTypes[delivery.EventType],
+		Attempt:     int32(delivery.Attempt),
+		StatusCode:  int32(delivery.StatusCode),
+		LatencyMs:   delivery.LatencyMs,
+		Error:       delivery.Error,
+		Succeeded:   delivery.Succeeded,
+		AttemptedAt: delivery.AttemptedAt.Format(time.RFC3339),
+	}
+}
+
+func castProtoToEventTypes(protoEventTypes []transactionv1.TransactionEventType) ([]string, error) {
+	var result []string
+	for _, protoEventType := range protoEventTypes {
+		found := false
+		for eventType, value := range eventTypes {
+			if value == protoEventType {
+				result = append(result, eventType)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, invalidArgument("event_types", fmt.Sprintf("unsupported event type %v", protoEventType))
+		}
+	}
+	return result, nil
+}
+
+func (ws WebhookService) CreateWebhookSubscription(ctx context.Context, request *transactionv1.CreateWebhookSubscriptionRequest) (*transactionv1.CreateWebhookSubscriptionResponse, error) {
+	eventTypes, err := castProtoToEventTypes(request.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := ws.service.CreateWebhookSubscription(ctx, model.CreateWebhookSubscriptionRequest{UserId: ownUserId(ctx, request.UserId), Url: request.Url, EventTypes: eventTypes})
+	if err != nil {
+		return nil, toStatus(ctx, "CreateWebhookSubscription", err)
+	}
+
+	return &transactionv1.CreateWebhookSubscriptionResponse{Subscription: CastWebhookSubscriptionToProto(&rs.Subscription), Secret: rs.Secret}, nil
+}
+
+func (ws WebhookService) ListWebhookSubscriptions(ctx context.Context, request *transactionv1.ListWebhookSubscriptionsRequest) (*transactionv1.ListWebhookSubscriptionsResponse, error) {
+	rs, err := ws.service.ListWebhookSubscriptions(ctx, model.ListWebhookSubscriptionsRequest{UserId: ownUserId(ctx, request.UserId)})
+	if err != nil {
+		return nil, toStatus(ctx, "ListWebhookSubscriptions", err)
+	}
+
+	response := &transactionv1.ListWebhookSubscriptionsResponse{}
+	for _, subscription := range rs.Subscriptions {
+		response.Subscriptions = append(response.Subscriptions, CastWebhookSubscriptionToProto(&subscription))
+	}
+	return response, nil
+}
+
+func (ws WebhookService) RotateWebhookSecret(ctx context.Context, request *transactionv1.RotateWebhookSecretRequest) (*transactionv1.RotateWebhookSecretResponse, error) {
+	rs, err := ws.service.RotateWebhookSecret(ctx, model.WebhookSubscriptionRequest{Id: request.Id, UserId: ownUserId(ctx, request.UserId)})
+	if err != nil {
+		return nil, toStatus(ctx, "RotateWebhookSecret", err)
+	}
+
+	return &transactionv1.RotateWebhookSecretResponse{
+		Subscription:            CastWebhookSubscriptionToProto(&rs.Subscription),
+		Secret:                  rs.Secret,
+		PreviousSecretExpiresAt: rs.PreviousSecretExpiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+func (ws WebhookService) PauseWebhookSubscription(ctx context.Context, request *transactionv1.PauseWebhookSubscriptionRequest) (*transactionv1.PauseWebhookSubscriptionResponse, error) {
+	rs, err := ws.service.PauseWebhookSubscription(ctx, model.WebhookSubscriptionRequest{Id: request.Id, UserId: ownUserId(ctx, request.UserId)})
+	if err != nil {
+		return nil, toStatus(ctx, "PauseWebhookSubscription", err)
+	}
+
+	return &transactionv1.PauseWebhookSubscriptionResponse{Subscription: CastWebhookSubscriptionToProto(rs)}, nil
+}
+
+func (ws WebhookService) ResumeWebhookSubscription(ctx context.Context, request *transactionv1.ResumeWebhookSubscriptionRequest) (*transactionv1.ResumeWebhookSubscriptionResponse, error) {
+	rs, err := ws.service.ResumeWebhookSubscription(ctx, model.WebhookSubscriptionRequest{Id: request.Id, UserId: ownUserId(ctx, request.UserId)})
+	if err != nil {
+		return nil, toStatus(ctx, "ResumeWebhookSubscription", err)
+	}
+
+	return &transactionv1.ResumeWebhookSubscriptionResponse{Subscription: CastWebhookSubscriptionToProto(rs)}, nil
+}
+
+func (ws WebhookService) DeleteWebhookSubscription(ctx context.Context, request *transactionv1.DeleteWebhookSubscriptionRequest) (*transactionv1.DeleteWebhookSubscriptionResponse, error) {
+	err := ws.service.DeleteWebhookSubscription(ctx, model.WebhookSubscriptionRequest{Id: request.Id, UserId: ownUserId(ctx, request.UserId)})
+	if err != nil {
+		return nil, toStatus(ctx, "DeleteWebhookSubscription", err)
+	}
+
+	return &transactionv1.DeleteWebhookSubscriptionResponse{}, nil
+}
+
+func (ws WebhookService) ListWebhookDeliveries(ctx context.Context, request *transactionv1.ListWebhookDeliveriesRequest) (*transactionv1.ListWebhookDeliveriesResponse, error) {
+	rs, err := ws.service.ListWebhookDeliveries(ctx, model.ListWebhookDeliveriesRequest{
+		SubscriptionId: request.SubscriptionId,
+		UserId:         ownUserId(ctx, request.UserId),
+		PageSize:       int(request.PageSize),
+		PageToken:      request.PageToken,
+	})
+	if err != nil {
+		return nil, toStatus(ctx, "ListWebhookDeliveries", err)
+	}
+
+	response := &transactionv1.ListWebhookDeliveriesResponse{NextPageToken: rs.NextPageToken}
+	for _, delivery := range rs.Deliveries {
+		response.Deliveries = append(response.Deliveries, CastWebhookDeliveryToProto(&delivery))
+	}
+	return response, nil
+}
@@ -0,0 +1,213 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Check reports why a dependency is unusable, nil while it is healthy.
+type Check func(ctx context.Context) error
+
+// Checker runs its checks periodically and reports the service ready while all
+// of them pass. The result is served through the grpc.health.v1 service and
+// the /readyz handler. Until the first run and after Shutdown the service is
+// not ready.
+type Checker struct {
+	server   *health.Server
+	services []string
+	checks   map[string]Check
+	interval time.Duration
+	timeout  time.Duration
+
+	mu           sync.RWMutex
+	results      map[string]error
+	checked      bool
+	shuttingDown bool
+}
+
+// CheckerOption configures a Checker.
+type CheckerOption func(*Checker)
+
+// WithCheck adds a named check, e.g. "database".
+func WithCheck(name string, check Check) CheckerOption {
+	return func(c *Checker) {
+		c.checks[name] = check
+	}
+}
+
+// WithServices reports the readiness for the named gRPC services as well as
+// for the whole server, the empty service name.
+func WithServices(services ...string) CheckerOption {
+	return func(c *Checker) {
+		c.services = append(c.services, services...)
+	}
+}
+
+// WithInterval sets how often the checks run, every 10 seconds by default.
+func WithInterval(interval time.Duration) CheckerOption {
+	return func(c *Checker) {
+		c.interval = interval
+	}
+}
+
+// WithCheckTimeout bounds every check, 5 seconds by default.
+func WithCheckTimeout(timeout time.Duration) CheckerOption {
+	return func(c *Checker) {
+		c.timeout = timeout
+	}
+}
+
+func NewChecker(options ...CheckerOption) *Checker {
+	c := &Checker{
+		server:   health.NewServer(),
+		services: []string{""},
+		checks:   make(map[string]Check),
+		interval: 10 * time.Second,
+		timeout:  5 * time.Second,
+		results:  make(map[string]error),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	c.setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	return c
+}
+
+// Server returns the grpc.health.v1 service to register on the gRPC server.
+func (c *Checker) Server() healthpb.HealthServer {
+	return c.server
+}
+
+// Run checks every interval until ctx is done.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.CheckNow(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckNow runs all checks concurrently and updates the serving status.
+func (c *Checker) CheckNow(ctx context.Context) {
+	results := make(map[string]error, len(c.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+			err := check(ctx)
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.shuttingDown {
+		return
+	}
+	c.results = results
+	c.checked = true
+	if c.readyLocked() {
+		c.setServingStatus(healthpb.HealthCheckResponse_SERVING)
+	} else {
+		c.setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// Shutdown reports the service as not ready from now on, so load balancers
+// stop sending new calls while the ones in flight drain.
+func (c *Checker) Shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shuttingDown = true
+	c.server.Shutdown()
+}
+
+// Ready tells whether the service should receive traffic.
+func (c *Checker) Ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.readyLocked()
+}
+
+func (c *Checker) readyLocked() bool {
+	if !c.checked || c.shuttingDown {
+		return false
+	}
+	for _, err := range c.results {
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Checker) setServingStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+}
+
+// LivenessHandler answers 200 as long as the process can serve HTTP at all.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	})
+}
+
+type readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// ReadinessHandler answers 200 while the service is ready and 503 otherwise,
+// with the result of every check in the JSON body.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.RLock()
+		body := readiness{Status: "ready", Checks: make(map[string]string, len(c.results))}
+		for name := range c.checks {
+			err, checked := c.results[name]
+			switch {
+			case !checked:
+				body.Checks[name] = "pending"
+			case err != nil:
+				body.Checks[name] = err.Error()
+			default:
+				body.Checks[name] = "ok"
+			}
+		}
+		ready := c.readyLocked()
+		if c.shuttingDown {
+			body.Status = "shutting down"
+		} else if !ready {
+			body.Status = "not ready"
+		}
+		c.mu.RUnlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
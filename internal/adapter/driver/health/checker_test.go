@@ -0,0 +1,96 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func servingStatus(t *testing.T, checker *health.Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	response, err := checker.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return response.Status
+}
+
+func readiness(t *testing.T, checker *health.Checker) (int, map[string]interface{}) {
+	recorder := httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	return recorder.Code, body
+}
+
+func TestCheckerIsNotServingBeforeTheFirstCheck(t *testing.T) {
+	checker := health.NewChecker(health.WithServices("finman.Transactions"), health.WithCheck("database", func(context.Context) error { return nil }))
+
+	assert.False(t, checker.Ready())
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, "finman.Transactions"))
+	code, body := readiness(t, checker)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "pending", body["checks"].(map[string]interface{})["database"])
+}
+
+func TestCheckerFollowsTheChecks(t *testing.T) {
+	var databaseErr error
+	checker := health.NewChecker(
+		health.WithServices("finman.Transactions"),
+		health.WithCheck("database", func(context.Context) error { return databaseErr }),
+		health.WithCheck("nats", func(context.Context) error { return nil }),
+	)
+
+	checker.CheckNow(context.Background())
+	assert.True(t, checker.Ready())
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, "finman.Transactions"))
+	code, body := readiness(t, checker)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", body["status"])
+
+	databaseErr = errors.New("connection refused")
+	checker.CheckNow(context.Background())
+	assert.False(t, checker.Ready())
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, "finman.Transactions"))
+	code, body = readiness(t, checker)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not ready", body["status"])
+	checks := body["checks"].(map[string]interface{})
+	assert.Equal(t, "connection refused", checks["database"])
+	assert.Equal(t, "ok", checks["nats"])
+}
+
+func TestCheckerTimesOutChecks(t *testing.T) {
+	checker := health.NewChecker(health.WithCheckTimeout(0), health.WithCheck("smtp", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	checker.CheckNow(context.Background())
+	assert.False(t, checker.Ready())
+}
+
+func TestCheckerIsNotServingAfterShutdown(t *testing.T) {
+	checker := health.NewChecker(health.WithCheck("database", func(context.Context) error { return nil }))
+	checker.CheckNow(context.Background())
+	require.True(t, checker.Ready())
+
+	checker.Shutdown()
+	checker.CheckNow(context.Background())
+	assert.False(t, checker.Ready())
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, ""))
+	code, body := readiness(t, checker)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "shutting down", body["status"])
+
+	recorder := httptest.NewRecorder()
+	checker.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
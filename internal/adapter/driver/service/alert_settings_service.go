@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+func ToModelAlertSettings(s domainModel.AlertSettings) model.AlertSettings {
+	return model.AlertSettings{
+		UserId:                 s.UserId,
+		LowBalanceThreshold:    s.LowBalanceThreshold,
+		LargeTransactionAmount: s.LargeTransactionAmount,
+		UpdatedAt:              s.UpdatedAt,
+	}
+}
+
+func ToDomainAlertSettings(s model.AlertSettings) domainModel.AlertSettings {
+	return domainModel.AlertSettings{
+		UserId:                 s.UserId,
+		LowBalanceThreshold:    s.LowBalanceThreshold,
+		LargeTransactionAmount: s.LargeTransactionAmount,
+	}
+}
+
+type alertSettingsService struct {
+	alertSettingsRepositoryFactory repository.AlertSettingsRepositoryFactory
+	dbTransactionFactory           db.DbTransactionFactory
+}
+
+func NewAlertSettingsService(arf repository.AlertSettingsRepositoryFactory, dtf db.DbTransactionFactory) *alertSettingsService {
+	return &alertSettingsService{alertSettingsRepositoryFactory: arf, dbTransactionFactory: dtf}
+}
+
+func (as *alertSettingsService) GetAlertSettings(ctx context.Context, request model.GetAlertSettingsRequest) (*model.GetAlertSettingsResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	tx := as.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	settings, err := as.alertSettingsRepositoryFactory.New(handler).GetAlertSettings(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	if settings == nil {
+		settings = &domainModel.AlertSettings{UserId: request.UserId}
+	}
+	return &model.GetAlertSettingsResponse{Settings: ToModelAlertSettings(*settings)}, nil
+}
+
+func (as *alertSettingsService) UpdateAlertSettings(ctx context.Context, request model.UpdateAlertSettingsRequest) (*model.UpdateAlertSettingsResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	tx := as.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := as.alertSettingsRepositoryFactory.New(handler)
+
+	if err := repository.SaveAlertSettings(ctx, ToDomainAlertSettings(request.Settings)); err != nil {
+		return nil, err
+	}
+	saved, err := repository.GetAlertSettings(ctx, request.Settings.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &model.UpdateAlertSettingsResponse{Settings: ToModelAlertSettings(*saved)}, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+// Alerter raises the alerts users configured within the database transaction
+// writing to their transactions, so alerts commit or roll back with the write.
+// Alerts are enqueued as notifications, so they reach the user through the
+// NotificationDispatcher like any other notification.
+type Alerter struct {
+	transactionRepositoryFactory   repository.TransactionRepositoryFactory
+	alertSettingsRepositoryFactory repository.AlertSettingsRepositoryFactory
+	outboxRepositoryFactory        repository.OutboxRepositoryFactory
+	cooldown                       time.Duration
+	now                            func() time.Time
+}
+
+// AlerterOption configures an Alerter.
+type AlerterOption func(*Alerter)
+
+// WithAlertCooldown sets how long after a low balance alert no other is raised,
+// however often the balance crosses the threshold.
+func WithAlertCooldown(cooldown time.Duration) AlerterOption {
+	return func(a *Alerter) {
+		a.cooldown = cooldown
+	}
+}
+
+// WithAlertClock replaces the clock the cooldown is measured with.
+func WithAlertClock(now func() time.Time) AlerterOption {
+	return func(a *Alerter) {
+		a.now = now
+	}
+}
+
+func NewAlerter(trf repository.TransactionRepositoryFactory, arf repository.AlertSettingsRepositoryFactory, orf repository.OutboxRepositoryFactory, options ...AlerterOption) *Alerter {
+	a := &Alerter{
+		transactionRepositoryFactory:   trf,
+		alertSettingsRepositoryFactory: arf,
+		outboxRepositoryFactory:        orf,
+		cooldown:                       24 * time.Hour,
+		now:                            time.Now,
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// Check raises the alerts caused by event within the database transaction of
+// handler that wrote it. For updates, previous is the transaction before the
+// change so large transactions are only alerted once.
+func (a *Alerter) Check(ctx context.Context, handler db.DbHandler, event domainModel.TransactionEvent, previous *domainModel.Transaction) error {
+	settingsRepository := a.alertSettingsRepositoryFactory.New(handler)
+	settings, err := settingsRepository.LockAlertSettings(ctx, event.UserId)
+	if err != nil || settings == nil {
+		return err
+	}
+
+	balance, err := a.transactionRepositoryFactory.New(handler).GetBalanceByUserId(ctx, event.UserId)
+	if err != nil {
+		return err
+	}
+
+	var alerts []domainModel.TransactionEvent
+	switch event.Type {
+	case domainModel.TransactionCreated, domainModel.TransactionUpdated:
+		if settings.IsLarge(event.Transaction) && (previous == nil || !settings.IsLarge(*previous)) {
+			alerts = append(alerts, a.alert(domainModel.LargeTransaction, event, balance, settings.LargeTransactionAmount))
+		}
+	}
+	if settings.CheckLowBalance(balance, a.now(), a.cooldown) {
+		alerts = append(alerts, a.alert(domainModel.LowBalance, event, balance, settings.LowBalanceThreshold))
+	}
+	if err := settingsRepository.SaveAlertState(ctx, event.UserId, settings.State); err != nil {
+		return err
+	}
+
+	outbox := a.outboxRepositoryFactory.New(handler)
+	for _, alert := range alerts {
+		payload, err := json.Marshal(alert)
+		if err != nil {
+			return err
+		}
+		_, err = outbox.Enqueue(ctx, domainModel.OutboxMessage{
+			Kind:         domainModel.OutboxKindNotification,
+			UserId:       alert.UserId,
+			TraceContext: injectTraceContext(ctx),
+			Payload:      payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Alerter) alert(eventType domainModel.TransactionEventType, cause domainModel.TransactionEvent, balance, threshold int64) domainModel.TransactionEvent {
+	return domainModel.TransactionEvent{
+		Id:          uuid.New().String(),
+		Type:        eventType,
+		UserId:      cause.UserId,
+		Transaction: cause.Transaction,
+		OccurredAt:  a.now(),
+		Alert:       &domainModel.Alert{Balance: balance, Threshold: threshold},
+	}
+}
@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+	portDb "github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	portRepository "github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertSettings(t *testing.T) {
+	settingsService := service.NewAlertSettingsService(repository.NewInMemoryAlertSettingsRepositoryFactory(repository.NewInMemoryAlertSettingsRepository()), &db.PostgresTransactionMockFactory{})
+	ctx := context.Background()
+	userId := uuid.New().String()
+
+	response, err := settingsService.GetAlertSettings(ctx, model.GetAlertSettingsRequest{UserId: userId})
+	assert.NoError(t, err)
+	assert.Equal(t, model.AlertSettings{UserId: userId}, response.Settings)
+
+	updated, err := settingsService.UpdateAlertSettings(ctx, model.UpdateAlertSettingsRequest{Settings: model.AlertSettings{UserId: userId, LowBalanceThreshold: 100, LargeTransactionAmount: 5000}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), updated.Settings.LowBalanceThreshold)
+	assert.Equal(t, int64(5000), updated.Settings.LargeTransactionAmount)
+	assert.False(t, updated.Settings.UpdatedAt.IsZero())
+
+	_, err = settingsService.UpdateAlertSettings(ctx, model.UpdateAlertSettingsRequest{Settings: model.AlertSettings{UserId: userId, LowBalanceThreshold: -1}})
+	assert.Error(t, err)
+	_, err = settingsService.UpdateAlertSettings(ctx, model.UpdateAlertSettingsRequest{Settings: model.AlertSettings{UserId: "not-a-uuid"}})
+	assert.Error(t, err)
+}
+
+func TestAlerts(t *testing.T) {
+	ctx := context.Background()
+	txFactory := &db.PostgresTransactionMockFactory{}
+	transactionFactory := repository.NewInMemoryTransactionRepositoryFactory(repository.NewInMemoryTransactionRepository())
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	settings := repository.NewInMemoryAlertSettingsRepository()
+
+	now := time.Now()
+	alerter := service.NewAlerter(transactionFactory, repository.NewInMemoryAlertSettingsRepositoryFactory(settings), outboxFactory,
+		service.WithAlertCooldown(24*time.Hour), service.WithAlertClock(func() time.Time { return now }))
+	transactionService := service.NewTransactionService(transactionFactory, txFactory, outboxFactory, service.WithAlerter(alerter))
+
+	userId := uuid.New().String()
+	otherUserId := uuid.New().String()
+	assert.NoError(t, settings.SaveAlertSettings(ctx, domainModel.AlertSettings{UserId: userId, LowBalanceThreshold: 100, LargeTransactionAmount: 3000}))
+
+	alerts := func() []domainModel.TransactionEvent {
+		var alerts []domainModel.TransactionEvent
+		for _, message := range outbox.Messages() {
+			var event domainModel.TransactionEvent
+			assert.NoError(t, json.Unmarshal(message.Payload, &event))
+			if event.Alert != nil {
+				alerts = append(alerts, event)
+			}
+		}
+		return alerts
+	}
+	create := func(userId, transactionType string, amount int64) string {
+		response, err := transactionService.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: userId, Type: transactionType, Amount: amount})
+		assert.NoError(t, err)
+		return response.Id
+	}
+
+	// Large transactions alert once, updates keeping them large do not
+	large := create(userId, "deposit", 3500)
+	assert.NoError(t, transactionService.UpdateTransaction(ctx, model.UpdateTransactionRequest{Id: large, UserId: userId, Type: "deposit", Amount: 4000}))
+	assert.Equal(t, 1, len(alerts()))
+	assert.Equal(t, domainModel.LargeTransaction, alerts()[0].Type)
+	assert.Equal(t, int64(3000), alerts()[0].Alert.Threshold)
+	assert.Equal(t, large, alerts()[0].Transaction.Id)
+
+	// Dropping below the threshold alerts
+	create(userId, "withdrawal", 2000)
+	create(userId, "withdrawal", 1950)
+	assert.Equal(t, 2, len(alerts()))
+	lowBalance := alerts()[1]
+	assert.Equal(t, domainModel.LowBalance, lowBalance.Type)
+	assert.Equal(t, int64(50), lowBalance.Alert.Balance)
+	assert.Equal(t, int64(100), lowBalance.Alert.Threshold)
+
+	// Staying below or hovering around the threshold within the cooldown does not
+	create(userId, "withdrawal", 10)
+	deposit := create(userId, "deposit", 100)
+	create(userId, "withdrawal", 100)
+	assert.Equal(t, 2, len(alerts()))
+
+	// Once the cooldown passed, crossing the threshold again alerts
+	now = now.Add(25 * time.Hour)
+	create(userId, "withdrawal", 10)
+	assert.Equal(t, 2, len(alerts()))
+	create(userId, "deposit", 200)
+	assert.NoError(t, transactionService.DeleteTransaction(ctx, model.DeleteTransactionRequest{Id: deposit}))
+	assert.Equal(t, 2, len(alerts()))
+	assert.NoError(t, transactionService.UpdateTransaction(ctx, model.UpdateTransactionRequest{Id: large, UserId: userId, Type: "deposit", Amount: 3950}))
+	assert.Equal(t, 3, len(alerts()))
+	assert.Equal(t, domainModel.LowBalance, alerts()[2].Type)
+	assert.Equal(t, int64(80), alerts()[2].Alert.Balance)
+
+	// Users without settings get no alerts
+	create(otherUserId, "deposit", 100000)
+	assert.Equal(t, 3, len(alerts()))
+}
+
+// unavailableAlertSettings fails to lock the alert settings of every user.
+type unavailableAlertSettings struct {
+	portRepository.AlertSettingsRepository
+}
+
+func (r unavailableAlertSettings) New(handler portDb.DbHandler) portRepository.AlertSettingsRepository {
+	return r
+}
+
+func (r unavailableAlertSettings) LockAlertSettings(ctx context.Context, userId string) (*domainModel.AlertSettings, error) {
+	return nil, errors.New("lock timeout")
+}
+
+func TestAlertsAreCheckedWithinTheWrite(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	transactionFactory := repository.NewInMemoryTransactionRepositoryFactory(repository.NewInMemoryTransactionRepository())
+	alerter := service.NewAlerter(transactionFactory, unavailableAlertSettings{}, outboxFactory)
+	transactionService := service.NewTransactionService(transactionFactory, &db.PostgresTransactionMockFactory{}, outboxFactory, service.WithAlerter(alerter))
+
+	// The write does not commit without its alerts
+	_, err := transactionService.CreateTransaction(context.Background(), model.CreateTransactionRequest{UserId: uuid.New().String(), Type: "deposit", Amount: 100})
+	assert.ErrorContains(t, err, "lock timeout")
+}
+
+func TestAlertNotifications(t *testing.T) {
+	notifications := &recordingNotificationService{}
+	dispatcher, preferences := newTestDispatcher(t, repository.NewInMemoryOutboxRepository(), map[string]driven.NotificationService{"test": notifications})
+	ctx := context.Background()
+
+	userId := uuid.New().String()
+	assert.NoError(t, preferences.SavePreferences(ctx, domainModel.NotificationPreferences{UserId: userId, MinAmount: 1000, Locale: "de"}))
+
+	// Alerts are sent regardless of the minimum amount
+	alert := domainModel.TransactionEvent{
+		Id:          uuid.New().String(),
+		Type:        domainModel.LowBalance,
+		UserId:      userId,
+		Transaction: domainModel.Transaction{Type: "withdrawal", Amount: 20, Date: time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)},
+		Alert:       &domainModel.Alert{Balance: 30, Threshold: 50},
+	}
+	assert.NoError(t, dispatcher.Handle(ctx, notificationMessage(t, alert)))
+	assert.Equal(t, 1, len(notifications.notifications))
+	assert.Equal(t, "Ihr Guthaben liegt unter 50", notifications.notifications[0].Subject)
+	assert.Contains(t, notifications.notifications[0].Message, "beträgt Ihr Guthaben 30")
+}
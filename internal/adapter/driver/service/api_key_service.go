@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+// apiKeyPrefix starts every key, followed by the key id and the secret
+// separated by underscores, so a key is looked up by id and only its secret
+// has to be hashed.
+const apiKeyPrefix = "fmk_"
+
+func ToModelApiKey(k domainModel.ApiKey) model.ApiKey {
+	scopes := make([]string, len(k.Scopes))
+	for i, scope := range k.Scopes {
+		scopes[i] = string(scope)
+	}
+	return model.ApiKey{
+		Id:           k.Id,
+		Service:      k.Service,
+		Scopes:       scopes,
+		CreatedAt:    k.CreatedAt,
+		RevokedAt:    k.RevokedAt,
+		LastUsedAt:   k.LastUsedAt,
+		RequestCount: k.RequestCount,
+	}
+}
+
+// maxCachedApiKeys bounds the cache of looked up keys, which also holds the
+// ids of unknown keys.
+const maxCachedApiKeys = 10000
+
+// apiKeyService authenticates keys with a read that is cached for cacheTTL,
+// so keys revoked by another replica stay valid that long. Uses are counted in
+// memory and recorded by RecordUsage in batches.
+type apiKeyService struct {
+	apiKeyRepositoryFactory repository.ApiKeyRepositoryFactory
+	dbTransactionFactory    db.DbTransactionFactory
+	cacheTTL                time.Duration
+	usageInterval           time.Duration
+	now                     func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedApiKey
+	usage map[string]apiKeyUsage
+	// evictions counts forget calls, so a lookup racing with a revoke does
+	// not cache the key it read before the revoke committed
+	evictions uint64
+}
+
+// cachedApiKey is a looked up key, nil when there is no key with its id.
+type cachedApiKey struct {
+	key       *domainModel.ApiKey
+	expiresAt time.Time
+}
+
+type apiKeyUsage struct {
+	requests   int64
+	lastUsedAt time.Time
+}
+
+// ApiKeyServiceOption configures the API key service.
+type ApiKeyServiceOption func(*apiKeyService)
+
+// WithApiKeyCacheTTL sets how long looked up keys are cached, 30 seconds by default.
+func WithApiKeyCacheTTL(ttl time.Duration) ApiKeyServiceOption {
+	return func(as *apiKeyService) {
+		as.cacheTTL = ttl
+	}
+}
+
+// WithApiKeyUsageInterval sets how often RecordUsage records the uses of keys,
+// 10 seconds by default.
+func WithApiKeyUsageInterval(interval time.Duration) ApiKeyServiceOption {
+	return func(as *apiKeyService) {
+		as.usageInterval = interval
+	}
+}
+
+// WithApiKeyClock replaces the clock cached keys expire with.
+func WithApiKeyClock(now func() time.Time) ApiKeyServiceOption {
+	return func(as *apiKeyService) {
+		as.now = now
+	}
+}
+
+func NewApiKeyService(arf repository.ApiKeyRepositoryFactory, dtf db.DbTransactionFactory, options ...ApiKeyServiceOption) *apiKeyService {
+	as := &apiKeyService{
+		apiKeyRepositoryFactory: arf,
+		dbTransactionFactory:    dtf,
+		cacheTTL:                30 * time.Second,
+		usageInterval:           10 * time.Second,
+		now:                     time.Now,
+		cache:                   make(map[string]cachedApiKey),
+		usage:                   make(map[string]apiKeyUsage),
+	}
+	for _, option := range options {
+		option(as)
+	}
+	return as
+}
+
+func hashApiKeySecret(secret string) []byte {
+	hash := sha256.Sum256([]byte(secret))
+	return hash[:]
+}
+
+// parseApiKey splits a key into its id and secret.
+func parseApiKey(key string) (string, string, bool) {
+	rest, found := strings.CutPrefix(key, apiKeyPrefix)
+	if !found {
+		return "", "", false
+	}
+	id, secret, found := strings.Cut(rest, "_")
+	if !found || secret == "" {
+		return "", "", false
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return "", "", false
+	}
+	return parsed.String(), secret, true
+}
+
+func (as *apiKeyService) CreateApiKey(ctx context.Context, request model.CreateApiKeyRequest) (*model.CreateApiKeyResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	id := uuid.New()
+	encodedSecret := base64.RawURLEncoding.EncodeToString(secret)
+	key := domainModel.ApiKey{
+		Id:         id.String(),
+		Service:    request.Service,
+		SecretHash: hashApiKeySecret(encodedSecret),
+	}
+	seen := make(map[string]bool)
+	for _, scope := range request.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			key.Scopes = append(key.Scopes, domainModel.ApiKeyScope(scope))
+		}
+	}
+
+	tx := as.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := as.apiKeyRepositoryFactory.New(handler)
+	if err := repository.CreateApiKey(ctx, key); err != nil {
+		return nil, err
+	}
+	created, err := repository.GetApiKeyById(ctx, key.Id)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, domain.ErrApiKeyNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	// The id is written without dashes so the key is a single word to shells and editors.
+	return &model.CreateApiKeyResponse{
+		ApiKey: ToModelApiKey(*created),
+		Key:    apiKeyPrefix + strings.ReplaceAll(id.String(), "-", "") + "_" + encodedSecret,
+	}, nil
+}
+
+func (as *apiKeyService) ListApiKeys(ctx context.Context, request model.ListApiKeysRequest) (*model.ListApiKeysResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	tx := as.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	keys, err := as.apiKeyRepositoryFactory.New(handler).ListApiKeys(ctx, request.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	response := &model.ListApiKeysResponse{}
+	for _, key := range keys {
+		response.ApiKeys = append(response.ApiKeys, ToModelApiKey(key))
+	}
+	return response, nil
+}
+
+func (as *apiKeyService) RevokeApiKey(ctx context.Context, request model.RevokeApiKeyRequest) (*model.ApiKey, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	tx := as.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := as.apiKeyRepositoryFactory.New(handler)
+	if err := repository.RevokeApiKey(ctx, request.Id, time.Now()); err != nil {
+		return nil, err
+	}
+	revoked, err := repository.GetApiKeyById(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if revoked == nil {
+		return nil, domain.ErrApiKeyNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	// Evicted once committed, a lookup before would cache the active key again
+	as.forget(request.Id)
+
+	key := ToModelApiKey(*revoked)
+	return &key, nil
+}
+
+func (as *apiKeyService) AuthenticateApiKey(ctx context.Context, request model.AuthenticateApiKeyRequest) (*model.ApiKey, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, domain.ErrInvalidApiKey
+	}
+	id, secret, ok := parseApiKey(request.Key)
+	if !ok {
+		return nil, domain.ErrInvalidApiKey
+	}
+
+	key, err := as.lookup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.RevokedAt != nil || subtle.ConstantTimeCompare(key.SecretHash, hashApiKeySecret(secret)) != 1 {
+		return nil, domain.ErrInvalidApiKey
+	}
+
+	used := ToModelApiKey(*key)
+	usage := as.use(key.Id)
+	used.LastUsedAt = &usage.lastUsedAt
+	used.RequestCount += usage.requests
+	return &used, nil
+}
+
+// lookup returns the key with id from the cache, or reads and caches it.
+func (as *apiKeyService) lookup(ctx context.Context, id string) (*domainModel.ApiKey, error) {
+	now := as.now()
+	as.mu.Lock()
+	cached, ok := as.cache[id]
+	evictions := as.evictions
+	as.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.key, nil
+	}
+
+	tx := as.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	key, err := as.apiKeyRepositoryFactory.New(handler).GetApiKeyById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	if as.evictions != evictions {
+		return key, nil
+	}
+	if len(as.cache) >= maxCachedApiKeys {
+		for cachedId, cached := range as.cache {
+			if !now.Before(cached.expiresAt) {
+				delete(as.cache, cachedId)
+			}
+		}
+		if len(as.cache) >= maxCachedApiKeys {
+			clear(as.cache)
+		}
+	}
+	as.cache[id] = cachedApiKey{key: key, expiresAt: now.Add(as.cacheTTL)}
+	return key, nil
+}
+
+func (as *apiKeyService) forget(id string) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	delete(as.cache, id)
+	as.evictions++
+}
+
+// use counts a request with the key with id and returns its uses not recorded yet.
+func (as *apiKeyService) use(id string) apiKeyUsage {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	usage := as.usage[id]
+	usage.requests++
+	usage.lastUsedAt = as.now()
+	as.usage[id] = usage
+	return usage
+}
+
+// RecordUsage records the uses of keys every usage interval until ctx is done,
+// and once more before returning.
+func (as *apiKeyService) RecordUsage(ctx context.Context) error {
+	ticker := time.NewTicker(as.usageInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			return as.FlushUsage(flushCtx)
+		case <-ticker.C:
+			if err := as.FlushUsage(ctx); err != nil {
+				slog.ErrorContext(ctx, "failed to record API key usage", "error", err)
+			}
+		}
+	}
+}
+
+// FlushUsage records the uses of keys counted since the last flush in one
+// database transaction. They are kept for the next flush when it fails.
+func (as *apiKeyService) FlushUsage(ctx context.Context) error {
+	as.mu.Lock()
+	usage := as.usage
+	as.usage = make(map[string]apiKeyUsage)
+	as.mu.Unlock()
+	if len(usage) == 0 {
+		return nil
+	}
+
+	err := as.recordUsage(ctx, usage)
+	if err != nil {
+		as.mu.Lock()
+		for id, pending := range usage {
+			current := as.usage[id]
+			current.requests += pending.requests
+			if pending.lastUsedAt.After(current.lastUsedAt) {
+				current.lastUsedAt = pending.lastUsedAt
+			}
+			as.usage[id] = current
+		}
+		as.mu.Unlock()
+	}
+	return err
+}
+
+func (as *apiKeyService) recordUsage(ctx context.Context, usage map[string]apiKeyUsage) error {
+	tx := as.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := as.apiKeyRepositoryFactory.New(handler)
+	for id, used := range usage {
+		if err := repository.RecordApiKeyUsage(ctx, id, used.requests, used.lastUsedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	portDb "github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	portRepository "github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKeys(t *testing.T) {
+	repo := repository.NewInMemoryApiKeyRepository()
+	apiKeyService := service.NewApiKeyService(repository.NewInMemoryApiKeyRepositoryFactory(repo), &db.PostgresTransactionMockFactory{})
+	ctx := context.Background()
+
+	_, err := apiKeyService.CreateApiKey(ctx, model.CreateApiKeyRequest{Service: "reporting", Scopes: []string{"transactions:delete"}})
+	assert.Error(t, err)
+
+	created, err := apiKeyService.CreateApiKey(ctx, model.CreateApiKeyRequest{Service: "reporting", Scopes: []string{"transactions:read", "transactions:read"}})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, "fmk_"))
+	assert.Equal(t, []string{"transactions:read"}, created.ApiKey.Scopes)
+	_, err = apiKeyService.CreateApiKey(ctx, model.CreateApiKeyRequest{Service: "payouts", Scopes: []string{"transactions:write"}})
+	assert.NoError(t, err)
+
+	stored, err := repo.GetApiKeyById(ctx, created.ApiKey.Id)
+	assert.NoError(t, err)
+	assert.Len(t, stored.SecretHash, 32)
+	assert.NotContains(t, created.Key, string(stored.SecretHash))
+
+	// Every authenticated request is counted.
+	for i := 0; i < 2; i++ {
+		used, err := apiKeyService.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: created.Key})
+		assert.NoError(t, err)
+		assert.Equal(t, "reporting", used.Service)
+		assert.Equal(t, int64(i+1), used.RequestCount)
+		assert.NotNil(t, used.LastUsedAt)
+	}
+
+	for _, key := range []string{
+		"",
+		"fmk_not-a-key",
+		created.Key + "x",
+		"fmk_" + strings.ReplaceAll(uuid.New().String(), "-", "") + created.Key[strings.LastIndex(created.Key, "_"):],
+		strings.TrimPrefix(created.Key, "fmk_"),
+	} {
+		_, err := apiKeyService.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: key})
+		assert.ErrorIs(t, err, domain.ErrInvalidApiKey, key)
+	}
+
+	// Uses are recorded in batches
+	assert.NoError(t, apiKeyService.FlushUsage(ctx))
+	listed, err := apiKeyService.ListApiKeys(ctx, model.ListApiKeysRequest{Service: "reporting"})
+	assert.NoError(t, err)
+	assert.Len(t, listed.ApiKeys, 1)
+	assert.Equal(t, int64(2), listed.ApiKeys[0].RequestCount)
+	assert.NotNil(t, listed.ApiKeys[0].LastUsedAt)
+	all, err := apiKeyService.ListApiKeys(ctx, model.ListApiKeysRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, all.ApiKeys, 2)
+
+	revoked, err := apiKeyService.RevokeApiKey(ctx, model.RevokeApiKeyRequest{Id: created.ApiKey.Id})
+	assert.NoError(t, err)
+	assert.NotNil(t, revoked.RevokedAt)
+	_, err = apiKeyService.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: created.Key})
+	assert.ErrorIs(t, err, domain.ErrInvalidApiKey)
+
+	_, err = apiKeyService.RevokeApiKey(ctx, model.RevokeApiKeyRequest{Id: uuid.New().String()})
+	assert.ErrorIs(t, err, domain.ErrApiKeyNotFound)
+}
+
+// countingApiKeyRepository counts the keys looked up.
+type countingApiKeyRepository struct {
+	*repository.InMemoryApiKeyRepository
+	lookups int
+}
+
+func (r *countingApiKeyRepository) New(handler portDb.DbHandler) portRepository.ApiKeyRepository {
+	return r
+}
+
+func (r *countingApiKeyRepository) GetApiKeyById(ctx context.Context, id string) (*domainModel.ApiKey, error) {
+	r.lookups++
+	return r.InMemoryApiKeyRepository.GetApiKeyById(ctx, id)
+}
+
+func TestApiKeyAuthenticationIsCached(t *testing.T) {
+	repo := &countingApiKeyRepository{InMemoryApiKeyRepository: repository.NewInMemoryApiKeyRepository()}
+	now := time.Now()
+	options := []service.ApiKeyServiceOption{service.WithApiKeyCacheTTL(time.Minute), service.WithApiKeyClock(func() time.Time { return now })}
+	apiKeyService := service.NewApiKeyService(repo, &db.PostgresTransactionMockFactory{}, options...)
+	otherReplica := service.NewApiKeyService(repo, &db.PostgresTransactionMockFactory{}, options...)
+	ctx := context.Background()
+
+	created, err := apiKeyService.CreateApiKey(ctx, model.CreateApiKeyRequest{Service: "reporting", Scopes: []string{"transactions:read"}})
+	assert.NoError(t, err)
+	lookups := repo.lookups
+
+	for i := 0; i < 3; i++ {
+		_, err := apiKeyService.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: created.Key})
+		assert.NoError(t, err)
+		_, err = otherReplica.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: created.Key})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, lookups+2, repo.lookups)
+
+	// Unknown keys are cached as well
+	unknown := "fmk_" + strings.ReplaceAll(uuid.New().String(), "-", "") + "_secret"
+	for i := 0; i < 3; i++ {
+		_, err := apiKeyService.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: unknown})
+		assert.ErrorIs(t, err, domain.ErrInvalidApiKey)
+	}
+	assert.Equal(t, lookups+3, repo.lookups)
+
+	// Revoked keys are rejected at once by the replica revoking them, by others once the cache expired
+	_, err = apiKeyService.RevokeApiKey(ctx, model.RevokeApiKeyRequest{Id: created.ApiKey.Id})
+	assert.NoError(t, err)
+	_, err = apiKeyService.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: created.Key})
+	assert.ErrorIs(t, err, domain.ErrInvalidApiKey)
+	_, err = otherReplica.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: created.Key})
+	assert.NoError(t, err)
+	now = now.Add(time.Minute)
+	_, err = otherReplica.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: created.Key})
+	assert.ErrorIs(t, err, domain.ErrInvalidApiKey)
+
+	// The uses counted by both replicas add up
+	assert.NoError(t, apiKeyService.FlushUsage(ctx))
+	assert.NoError(t, otherReplica.FlushUsage(ctx))
+	stored, err := repo.InMemoryApiKeyRepository.GetApiKeyById(ctx, created.ApiKey.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), stored.RequestCount)
+}
+
+// racingApiKeyRepository runs beforeRevoke while the revoke is not committed yet.
+type racingApiKeyRepository struct {
+	*repository.InMemoryApiKeyRepository
+	beforeRevoke func()
+}
+
+func (r *racingApiKeyRepository) New(handler portDb.DbHandler) portRepository.ApiKeyRepository {
+	return r
+}
+
+func (r *racingApiKeyRepository) RevokeApiKey(ctx context.Context, id string, revokedAt time.Time) error {
+	if r.beforeRevoke != nil {
+		r.beforeRevoke()
+	}
+	return r.InMemoryApiKeyRepository.RevokeApiKey(ctx, id, revokedAt)
+}
+
+func TestRevokedApiKeyIsNotCachedByConcurrentAuthentication(t *testing.T) {
+	repo := &racingApiKeyRepository{InMemoryApiKeyRepository: repository.NewInMemoryApiKeyRepository()}
+	apiKeyService := service.NewApiKeyService(repo, &db.PostgresTransactionMockFactory{}, service.WithApiKeyCacheTTL(time.Minute))
+	ctx := context.Background()
+
+	created, err := apiKeyService.CreateApiKey(ctx, model.CreateApiKeyRequest{Service: "reporting", Scopes: []string{"transactions:read"}})
+	assert.NoError(t, err)
+
+	// A request authenticated while the revoke is in flight still sees the active key
+	repo.beforeRevoke = func() {
+		_, err := apiKeyService.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: created.Key})
+		assert.NoError(t, err)
+	}
+	_, err = apiKeyService.RevokeApiKey(ctx, model.RevokeApiKeyRequest{Id: created.ApiKey.Id})
+	assert.NoError(t, err)
+
+	_, err = apiKeyService.AuthenticateApiKey(ctx, model.AuthenticateApiKeyRequest{Key: created.Key})
+	assert.ErrorIs(t, err, domain.ErrInvalidApiKey)
+}
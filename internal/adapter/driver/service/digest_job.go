@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+const digestSubscriberPageSize = 100
+
+// DigestJob produces the daily digests of users who opted in. Once the day of
+// a user ended in their time zone, the summary of that day is enqueued in the
+// outbox in the same database transaction that records it as produced, so
+// every day is sent once no matter how often the job runs. Days without
+// transactions are recorded but not sent, and days missed while the job was
+// not running are not caught up.
+type DigestJob struct {
+	dbTransactionFactory         db.DbTransactionFactory
+	preferencesRepositoryFactory repository.NotificationPreferencesRepositoryFactory
+	transactionRepositoryFactory repository.TransactionRepositoryFactory
+	digestRunRepositoryFactory   repository.DigestRunRepositoryFactory
+	outboxRepositoryFactory      repository.OutboxRepositoryFactory
+	interval                     time.Duration
+	now                          func() time.Time
+}
+
+// DigestJobOption configures a DigestJob.
+type DigestJobOption func(*DigestJob)
+
+// WithDigestInterval sets how often the job looks for finished days.
+func WithDigestInterval(interval time.Duration) DigestJobOption {
+	return func(j *DigestJob) {
+		j.interval = interval
+	}
+}
+
+// WithDigestClock replaces the clock deciding which days ended.
+func WithDigestClock(now func() time.Time) DigestJobOption {
+	return func(j *DigestJob) {
+		j.now = now
+	}
+}
+
+func NewDigestJob(dtf db.DbTransactionFactory, prf repository.NotificationPreferencesRepositoryFactory, trf repository.TransactionRepositoryFactory, drf repository.DigestRunRepositoryFactory, orf repository.OutboxRepositoryFactory, options ...DigestJobOption) *DigestJob {
+	j := &DigestJob{
+		dbTransactionFactory:         dtf,
+		preferencesRepositoryFactory: prf,
+		transactionRepositoryFactory: trf,
+		digestRunRepositoryFactory:   drf,
+		outboxRepositoryFactory:      orf,
+		interval:                     15 * time.Minute,
+		now:                          time.Now,
+	}
+	for _, option := range options {
+		option(j)
+	}
+	return j
+}
+
+// Run produces digests until ctx is done.
+func (j *DigestJob) Run(ctx context.Context) error {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.RunOnce(ctx); err != nil {
+			slog.ErrorContext(ctx, "digest job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce enqueues the digest of the previous day of every subscriber who did
+// not get it yet and returns how many were enqueued. A failing subscriber does
+// not hold back the others, its digest is retried on the next run.
+func (j *DigestJob) RunOnce(ctx context.Context) (int, error) {
+	now := j.now()
+	enqueued := 0
+	after := ""
+	var errs []error
+	for {
+		subscribers, err := j.listSubscribers(ctx, after)
+		if err != nil {
+			return enqueued, errors.Join(append(errs, err)...)
+		}
+		for _, preferences := range subscribers {
+			if err := ctx.Err(); err != nil {
+				return enqueued, errors.Join(append(errs, err)...)
+			}
+			sent, err := j.produce(ctx, preferences, now)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to produce digest", "user_id", preferences.UserId, "error", err)
+				errs = append(errs, fmt.Errorf("user %s: %w", preferences.UserId, err))
+				continue
+			}
+			if sent {
+				enqueued++
+			}
+		}
+		if len(subscribers) < digestSubscriberPageSize {
+			return enqueued, errors.Join(errs...)
+		}
+		after = subscribers[len(subscribers)-1].UserId
+	}
+}
+
+func (j *DigestJob) listSubscribers(ctx context.Context, after string) ([]domainModel.NotificationPreferences, error) {
+	tx := j.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	subscribers, err := j.preferencesRepositoryFactory.New(handler).ListDigestSubscribers(ctx, after, digestSubscriberPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return subscribers, nil
+}
+
+// produce records the digest of the day before now for a user and enqueues it,
+// it returns false when the day was already recorded or had no transactions.
+func (j *DigestJob) produce(ctx context.Context, preferences domainModel.NotificationPreferences, now time.Time) (bool, error) {
+	from, to := domainModel.PreviousDay(now, preferences.Location())
+	day := from.Format(domainModel.DigestDayLayout)
+
+	tx := j.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	recorded, err := j.digestRunRepositoryFactory.New(handler).RecordDigestRun(ctx, preferences.UserId, day)
+	if err != nil || !recorded {
+		return false, err
+	}
+
+	summary, err := j.transactionRepositoryFactory.New(handler).SummarizeTransactions(ctx, preferences.UserId, from, to)
+	if err != nil {
+		return false, err
+	}
+	if summary.Count > 0 {
+		payload, err := json.Marshal(domainModel.DailyDigest{
+			UserId:             preferences.UserId,
+			Day:                day,
+			TimeZone:           preferences.Location().String(),
+			TransactionSummary: *summary,
+		})
+		if err != nil {
+			return false, err
+		}
+		_, err = j.outboxRepositoryFactory.New(handler).Enqueue(ctx, domainModel.OutboxMessage{
+			Kind:    domainModel.OutboxKindDigest,
+			UserId:  preferences.UserId,
+			Payload: payload,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return summary.Count > 0, nil
+}
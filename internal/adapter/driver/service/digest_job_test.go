@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+	portDb "github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	portRepository "github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestJob(t *testing.T) {
+	ctx := context.Background()
+	txFactory := &db.PostgresTransactionMockFactory{}
+	transactions := repository.NewInMemoryTransactionRepository()
+	transactionFactory := repository.NewInMemoryTransactionRepositoryFactory(transactions)
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	runs := repository.NewInMemoryDigestRunRepository()
+
+	notifications := &recordingNotificationService{}
+	dispatcher, preferences := newTestDispatcher(t, outbox, map[string]driven.NotificationService{"test": notifications})
+	preferencesFactory := repository.NewInMemoryNotificationPreferencesRepositoryFactory(preferences)
+
+	location, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	now := time.Date(2024, 6, 2, 0, 30, 0, 0, location)
+	newJob := func() *service.DigestJob {
+		return service.NewDigestJob(txFactory, preferencesFactory, transactionFactory, repository.NewInMemoryDigestRunRepositoryFactory(runs), outboxFactory,
+			service.WithDigestClock(func() time.Time { return now }))
+	}
+
+	userId := uuid.New().String()
+	quietUserId := uuid.New().String()
+	otherUserId := uuid.New().String()
+	assert.NoError(t, preferences.SavePreferences(ctx, domainModel.NotificationPreferences{UserId: userId, TimeZone: "Europe/Berlin", DailyDigest: true}))
+	assert.NoError(t, preferences.SavePreferences(ctx, domainModel.NotificationPreferences{UserId: quietUserId, DailyDigest: true}))
+	assert.NoError(t, preferences.SavePreferences(ctx, domainModel.NotificationPreferences{UserId: otherUserId}))
+
+	for _, transaction := range []domainModel.Transaction{
+		{UserId: userId, Type: "deposit", Amount: 1000, Date: time.Date(2024, 5, 30, 12, 0, 0, 0, time.UTC)},
+		// 01:30 on the day in Berlin, still the previous day in UTC
+		{UserId: userId, Type: "deposit", Amount: 70, Date: time.Date(2024, 5, 31, 23, 30, 0, 0, time.UTC)},
+		{UserId: userId, Type: "deposit", Amount: 300, Date: time.Date(2024, 6, 1, 8, 0, 0, 0, location)},
+		{UserId: userId, Type: "withdrawal", Amount: 500, Date: time.Date(2024, 6, 1, 23, 30, 0, 0, location), Description: "rent"},
+		{UserId: userId, Type: "deposit", Amount: 50, Date: time.Date(2024, 6, 2, 0, 10, 0, 0, location)},
+		{UserId: otherUserId, Type: "deposit", Amount: 10, Date: time.Date(2024, 6, 1, 12, 0, 0, 0, location)},
+	} {
+		_, err := transactions.CreateTransaction(ctx, transaction)
+		assert.NoError(t, err)
+	}
+
+	enqueued, err := newJob().RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, enqueued)
+
+	// Restarts and repeated runs do not produce the day again
+	enqueued, err = newJob().RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, enqueued)
+	messages := outbox.Messages()
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, domainModel.OutboxKindDigest, messages[0].Kind)
+
+	relay := service.NewOutboxRelay(txFactory, outboxFactory, service.WithClock(func() time.Time { return time.Now().Add(time.Minute) }))
+	relay.Handle(domainModel.OutboxKindDigest, dispatcher.HandleDigest)
+	_, err = relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(notifications.notifications))
+	notification := notifications.notifications[0]
+	assert.Equal(t, domainModel.TransactionDigest, notification.Event.Type)
+	assert.Equal(t, userId, notification.Event.UserId)
+	assert.NotEmpty(t, notification.Event.Id)
+	digest := notification.Digest
+	assert.Equal(t, "2024-06-01", digest.Day)
+	assert.Equal(t, 3, digest.Count)
+	assert.Equal(t, int64(370), digest.TotalIn)
+	assert.Equal(t, int64(500), digest.TotalOut)
+	assert.Equal(t, int64(870), digest.ClosingBalance)
+	assert.Equal(t, int64(500), digest.Largest.Amount)
+	assert.Equal(t, "Your transactions on 2024-06-01", notification.Subject)
+	assert.Equal(t, "3 transactions on 2024-06-01: 370 in, 500 out. Closing balance: 870. Largest: withdrawal of 500 (rent).", notification.Message)
+
+	// The next day is produced once it ended
+	now = now.AddDate(0, 0, 1)
+	enqueued, err = newJob().RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, enqueued)
+}
+
+// failingDigestRuns fails to record the digests of one user.
+type failingDigestRuns struct {
+	portRepository.DigestRunRepository
+	userId string
+}
+
+func (r failingDigestRuns) New(handler portDb.DbHandler) portRepository.DigestRunRepository {
+	return r
+}
+
+func (r failingDigestRuns) RecordDigestRun(ctx context.Context, userId, day string) (bool, error) {
+	if userId == r.userId {
+		return false, errors.New("deadlock detected")
+	}
+	return r.DigestRunRepository.RecordDigestRun(ctx, userId, day)
+}
+
+func TestDigestJobContinuesPastFailingSubscribers(t *testing.T) {
+	ctx := context.Background()
+	transactions := repository.NewInMemoryTransactionRepository()
+	outbox := repository.NewInMemoryOutboxRepository()
+	preferences := repository.NewInMemoryNotificationPreferencesRepository()
+
+	userIds := []string{uuid.New().String(), uuid.New().String()}
+	sort.Strings(userIds)
+	for _, userId := range userIds {
+		assert.NoError(t, preferences.SavePreferences(ctx, domainModel.NotificationPreferences{UserId: userId, DailyDigest: true}))
+		_, err := transactions.CreateTransaction(ctx, domainModel.Transaction{UserId: userId, Type: "deposit", Amount: 10, Date: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)})
+		assert.NoError(t, err)
+	}
+
+	// The first subscriber fails, the second still gets the digest
+	runs := failingDigestRuns{DigestRunRepository: repository.NewInMemoryDigestRunRepository(), userId: userIds[0]}
+	job := service.NewDigestJob(&db.PostgresTransactionMockFactory{}, repository.NewInMemoryNotificationPreferencesRepositoryFactory(preferences),
+		repository.NewInMemoryTransactionRepositoryFactory(transactions), runs, repository.NewInMemoryOutboxRepositoryFactory(outbox),
+		service.WithDigestClock(func() time.Time { return time.Date(2024, 6, 2, 1, 0, 0, 0, time.UTC) }))
+	enqueued, err := job.RunOnce(ctx)
+	assert.ErrorContains(t, err, userIds[0])
+	assert.Equal(t, 1, enqueued)
+	messages := outbox.Messages()
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, userIds[1], messages[0].UserId)
+}
+
+func TestDailyDigestReplacesTransactionNotifications(t *testing.T) {
+	notifications := &recordingNotificationService{}
+	dispatcher, preferences := newTestDispatcher(t, repository.NewInMemoryOutboxRepository(), map[string]driven.NotificationService{"test": notifications})
+	ctx := context.Background()
+
+	userId := uuid.New().String()
+	assert.NoError(t, preferences.SavePreferences(ctx, domainModel.NotificationPreferences{UserId: userId, DailyDigest: true}))
+
+	created := domainModel.TransactionEvent{Id: uuid.New().String(), Type: domainModel.TransactionCreated, UserId: userId, Transaction: domainModel.Transaction{Amount: 10}}
+	assert.NoError(t, dispatcher.Handle(ctx, notificationMessage(t, created)))
+	assert.Equal(t, 0, len(notifications.notifications))
+
+	declined := created
+	declined.Type = domainModel.InsufficientBalance
+	assert.NoError(t, dispatcher.Handle(ctx, notificationMessage(t, declined)))
+	assert.Equal(t, 1, len(notifications.notifications))
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+)
+
+// NewEventPublishingHandler returns the OutboxHandler publishing the events of
+// OutboxKindEvent messages.
+func NewEventPublishingHandler(publisher driven.EventPublisher) OutboxHandler {
+	return func(ctx context.Context, message domainModel.OutboxMessage) error {
+		var event domainModel.TransactionEvent
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return err
+		}
+		return publisher.PublishTransactionEvent(ctx, event)
+	}
+}
@@ -0,0 +1,187 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingEventPublisher records published events and fails while err is set.
+type recordingEventPublisher struct {
+	mu     sync.Mutex
+	err    error
+	events []domainModel.TransactionEvent
+}
+
+func (p *recordingEventPublisher) PublishTransactionEvent(ctx context.Context, event domainModel.TransactionEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestTransactionEventsPublishedInOrderPerUser(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	txFactory := &db.PostgresTransactionMockFactory{}
+	events := repository.NewInMemoryTransactionEventRepository(nil)
+	transactionService := service.NewTransactionService(
+		repository.NewInMemoryTransactionRepositoryFactory(repository.NewInMemoryTransactionRepository()),
+		txFactory,
+		outboxFactory,
+		service.WithTransactionEvents(repository.NewInMemoryTransactionEventRepositoryFactory(events), nil),
+		service.WithEventPublishing(),
+	)
+
+	ctx := context.Background()
+	userId := uuid.New().String()
+	otherUserId := uuid.New().String()
+	created, err := transactionService.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: userId, Type: "deposit", Amount: 100})
+	assert.NoError(t, err)
+	assert.NoError(t, transactionService.UpdateTransaction(ctx, model.UpdateTransactionRequest{Id: created.Id, UserId: userId, Type: "deposit", Amount: 150}))
+	assert.NoError(t, transactionService.DeleteTransaction(ctx, model.DeleteTransactionRequest{Id: created.Id}))
+	_, err = transactionService.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: otherUserId, Type: "deposit", Amount: 10})
+	assert.NoError(t, err)
+
+	publisher := &recordingEventPublisher{err: errors.New("nats unavailable")}
+	now := time.Now().Add(time.Minute)
+	relay := service.NewOutboxRelay(txFactory, outboxFactory, service.WithClock(func() time.Time { return now }), service.WithRetries(5, time.Second, time.Minute))
+	relay.Handle(domainModel.OutboxKindEvent, service.NewEventPublishingHandler(publisher))
+	relay.Handle(domainModel.OutboxKindNotification, func(ctx context.Context, message domainModel.OutboxMessage) error { return nil })
+
+	// Only the oldest event of each user is attempted, later ones wait for it
+	_, err = relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	var attempts []int
+	for _, message := range outbox.Messages() {
+		if message.Kind == domainModel.OutboxKindEvent && message.UserId == userId {
+			assert.Equal(t, domainModel.OutboxPending, message.Status)
+			assert.Equal(t, userId, message.OrderingKey)
+			attempts = append(attempts, message.Attempts)
+		}
+	}
+	assert.Equal(t, []int{1, 0, 0}, attempts)
+
+	publisher.err = nil
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Hour)
+		_, err = relay.ProcessBatch(ctx)
+		assert.NoError(t, err)
+	}
+
+	var published []domainModel.TransactionEventType
+	ids := make(map[string]bool)
+	for _, event := range publisher.events {
+		ids[event.Id] = true
+		if event.UserId == userId {
+			published = append(published, event.Type)
+		}
+	}
+	assert.Equal(t, []domainModel.TransactionEventType{domainModel.TransactionCreated, domainModel.TransactionUpdated, domainModel.TransactionDeleted}, published)
+	assert.Equal(t, 4, len(publisher.events))
+	assert.Equal(t, 4, len(ids))
+	assert.Equal(t, created.Id, publisher.events[0].Transaction.Id)
+}
+
+func TestDeadEventHoldsBackLaterEventsOfItsUser(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	userId := uuid.New().String()
+	otherUserId := uuid.New().String()
+
+	ctx := context.Background()
+	for _, user := range []string{userId, userId, otherUserId} {
+		_, err := outbox.Enqueue(ctx, domainModel.OutboxMessage{Kind: domainModel.OutboxKindEvent, UserId: user, OrderingKey: user, Payload: []byte(`{}`)})
+		assert.NoError(t, err)
+	}
+
+	var delivered []int64
+	now := time.Now().Add(time.Minute)
+	relay := service.NewOutboxRelay(&db.PostgresTransactionMockFactory{}, outboxFactory, service.WithClock(func() time.Time { return now }), service.WithRetries(1, time.Second, time.Minute))
+	relay.Handle(domainModel.OutboxKindEvent, func(ctx context.Context, message domainModel.OutboxMessage) error {
+		if message.Id == 1 {
+			return errors.New("rejected")
+		}
+		delivered = append(delivered, message.Id)
+		return nil
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := relay.ProcessBatch(ctx)
+		assert.NoError(t, err)
+		now = now.Add(time.Hour)
+	}
+
+	// The second event of the user is not published past the gap, events of others are
+	messages := outbox.Messages()
+	assert.Equal(t, domainModel.OutboxDead, messages[0].Status)
+	assert.Equal(t, domainModel.OutboxPending, messages[1].Status)
+	assert.Equal(t, 0, messages[1].Attempts)
+	assert.Equal(t, []int64{3}, delivered)
+}
+
+func TestRequeueReleasesTheOrderingKeyOfADeadEvent(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	userId := uuid.New().String()
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err := outbox.Enqueue(ctx, domainModel.OutboxMessage{Kind: domainModel.OutboxKindEvent, UserId: userId, OrderingKey: userId, Payload: []byte(`{}`)})
+		assert.NoError(t, err)
+	}
+
+	var delivered []int64
+	rejected := true
+	now := time.Now().Add(time.Minute)
+	relay := service.NewOutboxRelay(&db.PostgresTransactionMockFactory{}, outboxFactory, service.WithClock(func() time.Time { return now }), service.WithRetries(1, time.Second, time.Minute))
+	relay.Handle(domainModel.OutboxKindEvent, func(ctx context.Context, message domainModel.OutboxMessage) error {
+		if rejected && message.Id == 1 {
+			return errors.New("rejected")
+		}
+		delivered = append(delivered, message.Id)
+		return nil
+	})
+
+	_, err := relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	blocked, err := outbox.CountBlockedOrderingKeys(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, blocked)
+	// Run refreshes the gauge before it relays
+	stopped, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.NoError(t, relay.Run(stopped))
+	assert.Equal(t, float64(1), metricValue(t, "finman_outbox_blocked_ordering_keys", nil))
+
+	// Only dead messages can be requeued
+	assert.ErrorIs(t, relay.Requeue(ctx, 2), domain.ErrDeadOutboxMessageNotFound)
+
+	// Once the cause is fixed the dead event is requeued and delivered first
+	rejected = false
+	assert.NoError(t, relay.Requeue(ctx, 1))
+	blocked, err = outbox.CountBlockedOrderingKeys(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, blocked)
+	for i := 0; i < 2; i++ {
+		_, err := relay.ProcessBatch(ctx)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []int64{1, 2}, delivered)
+	assert.ErrorIs(t, relay.Requeue(ctx, 1), domain.ErrDeadOutboxMessageNotFound)
+}
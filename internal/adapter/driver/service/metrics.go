@@ -0,0 +1,39 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	transactionsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "finman",
+		Name:      "transactions_created_total",
+		Help:      "Transactions created, by type.",
+	}, []string{"type"})
+
+	insufficientBalanceRejections = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "finman",
+		Name:      "insufficient_balance_rejections_total",
+		Help:      "Withdrawals rejected for exceeding the balance of the user.",
+	})
+
+	notificationDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "finman",
+		Name:      "notification_deliveries_total",
+		Help:      "Notifications sent, by channel and outcome: success or failure.",
+	}, []string{"channel", "outcome"})
+
+	blockedOrderingKeys = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "finman",
+		Name:      "outbox_blocked_ordering_keys",
+		Help:      "Ordering keys whose later outbox messages are held back by a dead message until it is requeued.",
+	})
+)
+
+func outcome(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metricValue returns the value of the counter or gauge name with labels in
+// the default registry, zero when it was not set yet.
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue metrics
+				}
+			}
+			if gauge := metric.GetGauge(); gauge != nil {
+				return gauge.GetValue()
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestCreateTransactionMetrics(t *testing.T) {
+	txService := service.NewTransactionService(repository.NewInMemoryTransactionRepositoryFactory(repository.NewInMemoryTransactionRepository()), &db.PostgresTransactionMockFactory{}, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
+	deposits := metricValue(t, "finman_transactions_created_total", map[string]string{"type": "deposit"})
+	withdrawals := metricValue(t, "finman_transactions_created_total", map[string]string{"type": "withdrawal"})
+	rejections := metricValue(t, "finman_insufficient_balance_rejections_total", nil)
+
+	ctx := context.Background()
+	userId := uuid.New().String()
+	_, err := txService.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: userId, Type: "deposit", Amount: 100})
+	require.NoError(t, err)
+	_, err = txService.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: userId, Type: "withdrawal", Amount: 40})
+	require.NoError(t, err)
+	_, err = txService.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: userId, Type: "withdrawal", Amount: 100})
+	require.ErrorIs(t, err, domain.ErrInsufficientBalance)
+
+	assert.Equal(t, deposits+1, metricValue(t, "finman_transactions_created_total", map[string]string{"type": "deposit"}))
+	assert.Equal(t, withdrawals+1, metricValue(t, "finman_transactions_created_total", map[string]string{"type": "withdrawal"}))
+	assert.Equal(t, rejections+1, metricValue(t, "finman_insufficient_balance_rejections_total", nil))
+}
+
+func TestNotificationDeliveryMetrics(t *testing.T) {
+	failing := &recordingNotificationService{err: errors.New("unreachable")}
+	dispatcher, _ := newTestDispatcher(t, repository.NewInMemoryOutboxRepository(), map[string]driven.NotificationService{"log": &recordingNotificationService{}, "webhook": failing})
+	successes := metricValue(t, "finman_notification_deliveries_total", map[string]string{"channel": "log", "outcome": "success"})
+	failures := metricValue(t, "finman_notification_deliveries_total", map[string]string{"channel": "webhook", "outcome": "failure"})
+
+	event := domainModel.TransactionEvent{Id: uuid.New().String(), Type: domainModel.TransactionCreated, UserId: uuid.New().String(), Transaction: domainModel.Transaction{Type: "deposit", Amount: 100}}
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	err = dispatcher.Handle(context.Background(), domainModel.OutboxMessage{Kind: domainModel.OutboxKindNotification, Payload: payload})
+	assert.Error(t, err)
+
+	assert.Equal(t, successes+1, metricValue(t, "finman_notification_deliveries_total", map[string]string{"channel": "log", "outcome": "success"}))
+	assert.Equal(t, failures+1, metricValue(t, "finman_notification_deliveries_total", map[string]string{"channel": "webhook", "outcome": "failure"}))
+}
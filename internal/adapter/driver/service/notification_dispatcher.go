@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+)
+
+// NotificationDispatcher handles notification outbox messages. It applies the
+// preferences of the user, renders the notification in the user's locale and
+// sends it through every channel the user chose. Channels a message was
+// delivered through are recorded, so a retry only resends through those that
+// failed.
+type NotificationDispatcher struct {
+	dbTransactionFactory         db.DbTransactionFactory
+	preferencesRepositoryFactory repository.NotificationPreferencesRepositoryFactory
+	outboxRepositoryFactory      repository.OutboxRepositoryFactory
+	renderer                     driven.NotificationRenderer
+	channels                     map[string]driven.NotificationService
+	now                          func() time.Time
+}
+
+// NotificationDispatcherOption configures a NotificationDispatcher.
+type NotificationDispatcherOption func(*NotificationDispatcher)
+
+// WithDispatchClock replaces the clock quiet hours are checked against.
+func WithDispatchClock(now func() time.Time) NotificationDispatcherOption {
+	return func(d *NotificationDispatcher) {
+		d.now = now
+	}
+}
+
+// NewNotificationDispatcher creates a dispatcher sending through channels, keyed by channel name.
+func NewNotificationDispatcher(dtf db.DbTransactionFactory, prf repository.NotificationPreferencesRepositoryFactory, orf repository.OutboxRepositoryFactory, renderer driven.NotificationRenderer, channels map[string]driven.NotificationService, options ...NotificationDispatcherOption) *NotificationDispatcher {
+	d := &NotificationDispatcher{
+		dbTransactionFactory:         dtf,
+		preferencesRepositoryFactory: prf,
+		outboxRepositoryFactory:      orf,
+		renderer:                     renderer,
+		channels:                     channels,
+		now:                          time.Now,
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Handle is the OutboxHandler of notification messages.
+func (d *NotificationDispatcher) Handle(ctx context.Context, message domainModel.OutboxMessage) error {
+	var event domainModel.TransactionEvent
+	if err := json.Unmarshal(message.Payload, &event); err != nil {
+		return err
+	}
+
+	preferences, err := d.getPreferences(ctx, event.UserId)
+	if err != nil {
+		return err
+	}
+	if !preferences.Wants(event) {
+		return nil
+	}
+	if until, quiet := preferences.QuietUntil(d.now()); quiet {
+		return &DeferredError{Until: until, Reason: "quiet hours"}
+	}
+
+	subject, text, err := d.renderer.RenderNotification(event, preferences.Locale, preferences.TimeZone)
+	if err != nil {
+		return err
+	}
+	return d.send(ctx, message, preferences, domainModel.Notification{Event: event, Locale: preferences.Locale, Subject: subject, Message: text})
+}
+
+// HandleDigest is the OutboxHandler of digest messages. Digests are sent to
+// users who turned the daily digest off since it was produced as well.
+func (d *NotificationDispatcher) HandleDigest(ctx context.Context, message domainModel.OutboxMessage) error {
+	var digest domainModel.DailyDigest
+	if err := json.Unmarshal(message.Payload, &digest); err != nil {
+		return err
+	}
+
+	preferences, err := d.getPreferences(ctx, digest.UserId)
+	if err != nil {
+		return err
+	}
+	if until, quiet := preferences.QuietUntil(d.now()); quiet {
+		return &DeferredError{Until: until, Reason: "quiet hours"}
+	}
+
+	subject, text, err := d.renderer.RenderDigest(digest, preferences.Locale)
+	if err != nil {
+		return err
+	}
+	return d.send(ctx, message, preferences, domainModel.Notification{
+		Event: domainModel.TransactionEvent{
+			// Stable across retries so receivers can deduplicate digests
+			Id:         uuid.NewSHA1(uuid.NameSpaceURL, []byte("finman:digest:"+digest.UserId+":"+digest.Day)).String(),
+			Type:       domainModel.TransactionDigest,
+			UserId:     digest.UserId,
+			OccurredAt: message.CreatedAt,
+		},
+		Digest:  &digest,
+		Locale:  preferences.Locale,
+		Subject: subject,
+		Message: text,
+	})
+}
+
+// send delivers notification through every channel the user chose that
+// message was not delivered through yet.
+func (d *NotificationDispatcher) send(ctx context.Context, message domainModel.OutboxMessage, preferences domainModel.NotificationPreferences, notification domainModel.Notification) error {
+	delivered, err := d.getDeliveredChannels(ctx, message.Id)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(d.channels))
+	for name := range d.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	notification.Attempt = message.Attempts
+
+	var errs []error
+	for _, name := range names {
+		if !preferences.UsesChannel(name) || slices.Contains(delivered, name) {
+			continue
+		}
+		err := d.channels[name].SendTransactionNotification(ctx, notification)
+		notificationDeliveries.WithLabelValues(name, outcome(err)).Inc()
+		if err == nil {
+			err = d.markChannelDelivered(ctx, message.Id, name)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *NotificationDispatcher) getDeliveredChannels(ctx context.Context, messageId int64) ([]string, error) {
+	tx := d.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	channels, err := d.outboxRepositoryFactory.New(handler).GetDeliveredChannels(ctx, messageId)
+	if err != nil {
+		return nil, err
+	}
+	return channels, tx.Commit(ctx)
+}
+
+func (d *NotificationDispatcher) markChannelDelivered(ctx context.Context, messageId int64, channel string) error {
+	tx := d.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	if err := d.outboxRepositoryFactory.New(handler).MarkChannelDelivered(ctx, messageId, channel, d.now()); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (d *NotificationDispatcher) getPreferences(ctx context.Context, userId string) (domainModel.NotificationPreferences, error) {
+	tx := d.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return domainModel.NotificationPreferences{}, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	preferences, err := d.preferencesRepositoryFactory.New(handler).GetPreferences(ctx, userId)
+	if err != nil {
+		return domainModel.NotificationPreferences{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domainModel.NotificationPreferences{}, err
+	}
+
+	if preferences == nil {
+		return domainModel.DefaultNotificationPreferences(userId), nil
+	}
+	return *preferences, nil
+}
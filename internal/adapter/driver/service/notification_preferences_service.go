@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+func ToModelNotificationPreferences(p domainModel.NotificationPreferences) model.NotificationPreferences {
+	preferences := model.NotificationPreferences{
+		UserId:      p.UserId,
+		Channels:    p.Channels,
+		TimeZone:    p.TimeZone,
+		MinAmount:   p.MinAmount,
+		Locale:      p.Locale,
+		DailyDigest: p.DailyDigest,
+		UpdatedAt:   p.UpdatedAt,
+	}
+	for _, eventType := range p.EventTypes {
+		preferences.EventTypes = append(preferences.EventTypes, string(eventType))
+	}
+	if p.QuietHoursStart != p.QuietHoursEnd {
+		preferences.QuietHoursStart = formatMinuteOfDay(p.QuietHoursStart)
+		preferences.QuietHoursEnd = formatMinuteOfDay(p.QuietHoursEnd)
+	}
+	return preferences
+}
+
+func ToDomainNotificationPreferences(p model.NotificationPreferences) (domainModel.NotificationPreferences, error) {
+	preferences := domainModel.NotificationPreferences{
+		UserId:      p.UserId,
+		Channels:    p.Channels,
+		TimeZone:    p.TimeZone,
+		MinAmount:   p.MinAmount,
+		Locale:      p.Locale,
+		DailyDigest: p.DailyDigest,
+	}
+	if preferences.Locale == "" {
+		preferences.Locale = domainModel.DefaultLocale
+	}
+	for _, eventType := range p.EventTypes {
+		preferences.EventTypes = append(preferences.EventTypes, domainModel.TransactionEventType(eventType))
+	}
+
+	if (p.QuietHoursStart == "") != (p.QuietHoursEnd == "") {
+		return domainModel.NotificationPreferences{}, domain.ErrInvalidQuietHours
+	}
+	if p.QuietHoursStart != "" {
+		start, err := parseMinuteOfDay(p.QuietHoursStart)
+		if err != nil {
+			return domainModel.NotificationPreferences{}, domain.ErrInvalidQuietHours
+		}
+		end, err := parseMinuteOfDay(p.QuietHoursEnd)
+		if err != nil {
+			return domainModel.NotificationPreferences{}, domain.ErrInvalidQuietHours
+		}
+		preferences.QuietHoursStart, preferences.QuietHoursEnd = start, end
+	}
+	return preferences, nil
+}
+
+func parseMinuteOfDay(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+func formatMinuteOfDay(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+type notificationPreferencesService struct {
+	preferencesRepositoryFactory repository.NotificationPreferencesRepositoryFactory
+	dbTransactionFactory         db.DbTransactionFactory
+}
+
+func NewNotificationPreferencesService(prf repository.NotificationPreferencesRepositoryFactory, dtf db.DbTransactionFactory) *notificationPreferencesService {
+	return &notificationPreferencesService{preferencesRepositoryFactory: prf, dbTransactionFactory: dtf}
+}
+
+func (ps *notificationPreferencesService) GetNotificationPreferences(ctx context.Context, request model.GetNotificationPreferencesRequest) (*model.GetNotificationPreferencesResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	tx := ps.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	preferences, err := ps.preferencesRepositoryFactory.New(handler).GetPreferences(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	if preferences == nil {
+		defaults := domainModel.DefaultNotificationPreferences(request.UserId)
+		preferences = &defaults
+	}
+	return &model.GetNotificationPreferencesResponse{Preferences: ToModelNotificationPreferences(*preferences)}, nil
+}
+
+func (ps *notificationPreferencesService) UpdateNotificationPreferences(ctx context.Context, request model.UpdateNotificationPreferencesRequest) (*model.UpdateNotificationPreferencesResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+	preferences, err := ToDomainNotificationPreferences(request.Preferences)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := ps.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := ps.preferencesRepositoryFactory.New(handler)
+
+	if err := repository.SavePreferences(ctx, preferences); err != nil {
+		return nil, err
+	}
+	saved, err := repository.GetPreferences(ctx, preferences.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &model.UpdateNotificationPreferencesResponse{Preferences: ToModelNotificationPreferences(*saved)}, nil
+}
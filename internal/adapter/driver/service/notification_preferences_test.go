@@ -0,0 +1,203 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationPreferences(t *testing.T) {
+	repo := repository.NewInMemoryNotificationPreferencesRepository()
+	preferencesService := service.NewNotificationPreferencesService(repository.NewInMemoryNotificationPreferencesRepositoryFactory(repo), &db.PostgresTransactionMockFactory{})
+	ctx := context.Background()
+	userId := uuid.New().String()
+
+	// Defaults
+	response, err := preferencesService.GetNotificationPreferences(ctx, model.GetNotificationPreferencesRequest{UserId: userId})
+	assert.NoError(t, err)
+	assert.Equal(t, userId, response.Preferences.UserId)
+	assert.Empty(t, response.Preferences.EventTypes)
+	assert.Empty(t, response.Preferences.QuietHoursStart)
+	assert.Equal(t, "en", response.Preferences.Locale)
+
+	preferences := model.NotificationPreferences{
+		UserId:          userId,
+		EventTypes:      []string{"created", "insufficient_balance"},
+		Channels:        []string{"email"},
+		QuietHoursStart: "22:00",
+		QuietHoursEnd:   "07:30",
+		TimeZone:        "Europe/Berlin",
+		MinAmount:       500,
+		Locale:          "de-AT",
+		DailyDigest:     true,
+	}
+	updated, err := preferencesService.UpdateNotificationPreferences(ctx, model.UpdateNotificationPreferencesRequest{Preferences: preferences})
+	assert.NoError(t, err)
+	assert.Equal(t, "22:00", updated.Preferences.QuietHoursStart)
+	assert.Equal(t, "07:30", updated.Preferences.QuietHoursEnd)
+
+	response, err = preferencesService.GetNotificationPreferences(ctx, model.GetNotificationPreferencesRequest{UserId: userId})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"created", "insufficient_balance"}, response.Preferences.EventTypes)
+	assert.Equal(t, []string{"email"}, response.Preferences.Channels)
+	assert.Equal(t, "Europe/Berlin", response.Preferences.TimeZone)
+	assert.Equal(t, int64(500), response.Preferences.MinAmount)
+	assert.Equal(t, "de-AT", response.Preferences.Locale)
+	assert.True(t, response.Preferences.DailyDigest)
+	stored, _ := repo.GetPreferences(ctx, userId)
+	assert.Equal(t, 22*60, stored.QuietHoursStart)
+	assert.Equal(t, 7*60+30, stored.QuietHoursEnd)
+
+	// Validation
+	invalid := []model.NotificationPreferences{
+		{UserId: userId, QuietHoursStart: "22:00"},
+		{UserId: userId, QuietHoursStart: "25:00", QuietHoursEnd: "07:00"},
+		{UserId: userId, TimeZone: "Mars/Olympus"},
+		{UserId: userId, Channels: []string{"pigeon"}},
+		{UserId: userId, EventTypes: []string{"archived"}},
+		{UserId: userId, MinAmount: -1},
+		{UserId: "not-a-uuid"},
+	}
+	for _, preferences := range invalid {
+		_, err := preferencesService.UpdateNotificationPreferences(ctx, model.UpdateNotificationPreferencesRequest{Preferences: preferences})
+		assert.Error(t, err, "%+v", preferences)
+	}
+	_, err = preferencesService.UpdateNotificationPreferences(ctx, model.UpdateNotificationPreferencesRequest{Preferences: invalid[0]})
+	assert.ErrorIs(t, err, domain.ErrInvalidQuietHours)
+}
+
+var lastMessageId atomic.Int64
+
+func notificationMessage(t *testing.T, event domainModel.TransactionEvent) domainModel.OutboxMessage {
+	payload, err := json.Marshal(event)
+	assert.NoError(t, err)
+	return domainModel.OutboxMessage{Id: lastMessageId.Add(1), Kind: domainModel.OutboxKindNotification, UserId: event.UserId, Payload: payload}
+}
+
+func TestNotificationDispatcherAppliesPreferences(t *testing.T) {
+	email := &recordingNotificationService{}
+	webhook := &recordingNotificationService{}
+	dispatcher, preferences := newTestDispatcher(t, repository.NewInMemoryOutboxRepository(), map[string]driven.NotificationService{"email": email, "webhook": webhook})
+	ctx := context.Background()
+
+	userId := uuid.New().String()
+	event := domainModel.TransactionEvent{
+		Id:     uuid.New().String(),
+		Type:   domainModel.TransactionCreated,
+		UserId: userId,
+		Transaction: domainModel.Transaction{
+			Id:     uuid.New().String(),
+			UserId: userId,
+			Type:   "deposit",
+			Amount: 1000,
+			Date:   time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	// Without preferences every channel gets an English message
+	assert.NoError(t, dispatcher.Handle(ctx, notificationMessage(t, event)))
+	assert.Equal(t, 1, len(email.notifications))
+	assert.Equal(t, 1, len(webhook.notifications))
+	assert.Equal(t, "Deposit of 1000 recorded", email.notifications[0].Subject)
+	assert.Contains(t, email.notifications[0].Message, "Transaction created with ID: "+event.Transaction.Id)
+
+	assert.NoError(t, preferences.SavePreferences(ctx, domainModel.NotificationPreferences{
+		UserId:     userId,
+		EventTypes: []domainModel.TransactionEventType{domainModel.TransactionCreated},
+		Channels:   []string{"email"},
+		MinAmount:  500,
+		TimeZone:   "Europe/Berlin",
+		Locale:     "de-AT",
+	}))
+
+	// Localized, in the time zone of the user, through the chosen channels only
+	assert.NoError(t, dispatcher.Handle(ctx, notificationMessage(t, event)))
+	assert.Equal(t, 2, len(email.notifications))
+	assert.Equal(t, 1, len(webhook.notifications))
+	assert.Equal(t, "de-AT", email.notifications[1].Locale)
+	assert.Equal(t, "Einzahlung über 1000 verbucht", email.notifications[1].Subject)
+	assert.Contains(t, email.notifications[1].Message, "2024-06-01 14:00 CEST")
+
+	// Below the minimum amount
+	small := event
+	small.Transaction.Amount = 100
+	assert.NoError(t, dispatcher.Handle(ctx, notificationMessage(t, small)))
+	// Not a chosen event type
+	deleted := event
+	deleted.Type = domainModel.TransactionDeleted
+	assert.NoError(t, dispatcher.Handle(ctx, notificationMessage(t, deleted)))
+	assert.Equal(t, 2, len(email.notifications))
+}
+
+func TestNotificationDispatcherDefersDuringQuietHours(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+
+	location, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	now := time.Date(2024, 6, 1, 23, 15, 0, 0, location)
+	clock := func() time.Time { return now }
+
+	notifications := &recordingNotificationService{}
+	dispatcher, preferences := newTestDispatcher(t, outbox, map[string]driven.NotificationService{"test": notifications}, service.WithDispatchClock(clock))
+	relay := service.NewOutboxRelay(&db.PostgresTransactionMockFactory{}, outboxFactory, service.WithClock(clock))
+	relay.Handle(domainModel.OutboxKindNotification, dispatcher.Handle)
+
+	ctx := context.Background()
+	userId := uuid.New().String()
+	assert.NoError(t, preferences.SavePreferences(ctx, domainModel.NotificationPreferences{
+		UserId:          userId,
+		QuietHoursStart: 22 * 60,
+		QuietHoursEnd:   7 * 60,
+		TimeZone:        "Europe/Berlin",
+	}))
+	message := notificationMessage(t, domainModel.TransactionEvent{Type: domainModel.TransactionCreated, UserId: userId, Transaction: domainModel.Transaction{Amount: 10}})
+	message.NextAttemptAt = now
+	_, err = outbox.Enqueue(ctx, message)
+	assert.NoError(t, err)
+
+	_, err = relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	stored := outbox.Messages()[0]
+	assert.Equal(t, domainModel.OutboxPending, stored.Status)
+	assert.Equal(t, 0, stored.Attempts)
+	assert.True(t, stored.NextAttemptAt.Equal(time.Date(2024, 6, 2, 7, 0, 0, 0, location)), stored.NextAttemptAt.String())
+	assert.Equal(t, 0, len(notifications.notifications))
+
+	now = stored.NextAttemptAt
+	_, err = relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, domainModel.OutboxDelivered, outbox.Messages()[0].Status)
+	assert.Equal(t, 1, len(notifications.notifications))
+}
+
+func TestQuietHoursOnDaylightSavingTimeChanges(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	preferences := domainModel.NotificationPreferences{QuietHoursStart: 0, QuietHoursEnd: 7 * 60, TimeZone: "Europe/Berlin"}
+
+	// The clocks go forward at 02:00 and back at 03:00
+	for _, day := range []time.Time{time.Date(2024, 3, 31, 0, 0, 0, 0, location), time.Date(2024, 10, 27, 0, 0, 0, 0, location)} {
+		until, quiet := preferences.QuietUntil(day.Add(90 * time.Minute))
+		assert.True(t, quiet)
+		assert.Equal(t, 7, until.In(location).Hour(), until.String())
+	}
+
+	// Wrapping midnight into the day the clocks change
+	preferences.QuietHoursStart = 22 * 60
+	until, quiet := preferences.QuietUntil(time.Date(2024, 3, 30, 23, 0, 0, 0, location))
+	assert.True(t, quiet)
+	assert.True(t, until.Equal(time.Date(2024, 3, 31, 7, 0, 0, 0, location)), until.String())
+}
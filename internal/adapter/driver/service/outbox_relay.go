@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// blockedOrderingKeysInterval is how often Run refreshes the gauge of
+// ordering keys held back by dead messages.
+const blockedOrderingKeysInterval = time.Minute
+
+// OutboxHandler delivers a single outbox message. Returning an error schedules
+// the message for another attempt, so handlers must be idempotent.
+type OutboxHandler func(ctx context.Context, message domainModel.OutboxMessage) error
+
+// DeferredError is returned by handlers to attempt a message again at Until
+// without counting it as a failure.
+type DeferredError struct {
+	Until  time.Time
+	Reason string
+}
+
+func (e *DeferredError) Error() string {
+	return "deferred until " + e.Until.Format(time.RFC3339) + ": " + e.Reason
+}
+
+// OutboxRelay delivers pending outbox messages with at-least-once semantics.
+// A batch of messages is leased in a short database transaction and delivered
+// outside of it, every outcome is recorded in a database transaction of its
+// own. Several relays can run side by side, and a crash simply leaves the
+// message to be attempted again once its lease expired.
+type OutboxRelay struct {
+	dbTransactionFactory    db.DbTransactionFactory
+	outboxRepositoryFactory repository.OutboxRepositoryFactory
+	handlers                map[string]OutboxHandler
+	pollInterval            time.Duration
+	batchSize               int
+	lease                   time.Duration
+	maxAttempts             int
+	baseBackoff             time.Duration
+	maxBackoff              time.Duration
+	now                     func() time.Time
+}
+
+// OutboxRelayOption configures an OutboxRelay.
+type OutboxRelayOption func(*OutboxRelay)
+
+func WithPollInterval(interval time.Duration) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.pollInterval = interval
+	}
+}
+
+func WithBatchSize(size int) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.batchSize = size
+	}
+}
+
+// WithLease sets how long a batch of messages is leased to the relay, messages
+// that could not be handled in time are left to the next batch.
+func WithLease(lease time.Duration) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.lease = lease
+	}
+}
+
+// WithRetries sets how often a message is attempted before it is dead-lettered
+// and the bounds of the exponential backoff between attempts.
+func WithRetries(maxAttempts int, baseBackoff, maxBackoff time.Duration) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.maxAttempts = maxAttempts
+		r.baseBackoff = baseBackoff
+		r.maxBackoff = maxBackoff
+	}
+}
+
+// WithClock replaces the clock used to decide which messages are due.
+func WithClock(now func() time.Time) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.now = now
+	}
+}
+
+func NewOutboxRelay(dtf db.DbTransactionFactory, orf repository.OutboxRepositoryFactory, options ...OutboxRelayOption) *OutboxRelay {
+	r := &OutboxRelay{
+		dbTransactionFactory:    dtf,
+		outboxRepositoryFactory: orf,
+		handlers:                make(map[string]OutboxHandler),
+		pollInterval:            time.Second,
+		batchSize:               100,
+		lease:                   5 * time.Minute,
+		maxAttempts:             10,
+		baseBackoff:             time.Second,
+		maxBackoff:              time.Hour,
+		now:                     time.Now,
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// Handle registers the handler for messages of a kind.
+func (r *OutboxRelay) Handle(kind string, handler OutboxHandler) {
+	r.handlers[kind] = handler
+}
+
+// Run relays messages until ctx is done. The batch in progress when ctx is
+// done is still completed, so messages are not delivered without being marked.
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	gaugeTicker := time.NewTicker(blockedOrderingKeysInterval)
+	defer gaugeTicker.Stop()
+	r.updateBlockedOrderingKeys(ctx)
+
+	for {
+		for ctx.Err() == nil {
+			processed, err := r.ProcessBatch(context.WithoutCancel(ctx))
+			if err != nil {
+				slog.ErrorContext(ctx, "outbox relay failed", "error", err)
+			}
+			// Messages held back by their ordering key are claimable in the next batch
+			if err != nil || processed == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-gaugeTicker.C:
+			r.updateBlockedOrderingKeys(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateBlockedOrderingKeys refreshes the gauge of ordering keys held back by
+// a dead message.
+func (r *OutboxRelay) updateBlockedOrderingKeys(ctx context.Context) {
+	tx := r.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to count blocked ordering keys", "error", err)
+		return
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	count, err := r.outboxRepositoryFactory.New(handler).CountBlockedOrderingKeys(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to count blocked ordering keys", "error", err)
+		return
+	}
+	blockedOrderingKeys.Set(float64(count))
+}
+
+// Requeue sets a dead message back to pending so it is attempted again, with
+// as many attempts as a new message. The later messages of its ordering key
+// follow once it was delivered.
+func (r *OutboxRelay) Requeue(ctx context.Context, id int64) error {
+	tx := r.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	requeued, err := r.outboxRepositoryFactory.New(handler).RequeueDead(ctx, id, r.now())
+	if err != nil {
+		return err
+	}
+	if !requeued {
+		return domain.ErrDeadOutboxMessageNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+// ProcessBatch attempts one batch of due messages and returns how many were leased.
+func (r *OutboxRelay) ProcessBatch(ctx context.Context) (int, error) {
+	// The lease is stored by the clock of the relay, the handlers are bound by the wall clock
+	deadline := time.Now().Add(r.lease)
+	messages, err := r.leasePending(ctx, r.now().Add(r.lease))
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, message := range messages {
+		if err := r.deliver(ctx, message, deadline); err != nil {
+			errs = append(errs, fmt.Errorf("outbox message %d: %w", message.Id, err))
+		}
+	}
+	return len(messages), errors.Join(errs...)
+}
+
+func (r *OutboxRelay) leasePending(ctx context.Context, lockedUntil time.Time) ([]domainModel.OutboxMessage, error) {
+	tx := r.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	messages, err := r.outboxRepositoryFactory.New(handler).LeasePending(ctx, r.now(), lockedUntil, r.batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// deliver handles a leased message and records the outcome. The attempt was
+// counted by the lease already.
+func (r *OutboxRelay) deliver(ctx context.Context, message domainModel.OutboxMessage, deadline time.Time) error {
+	handle, ok := r.handlers[message.Kind]
+	if !ok {
+		return r.record(ctx, func(repository repository.OutboxRepository) error {
+			return repository.MarkDead(ctx, message, fmt.Sprintf("no handler for outbox messages of kind %q", message.Kind))
+		})
+	}
+
+	// The lease ran out while earlier messages of the batch were handled
+	if !time.Now().Before(deadline) {
+		return r.record(ctx, func(repository repository.OutboxRepository) error {
+			return repository.MarkFailed(ctx, message, message.Attempts-1, message.NextAttemptAt, message.LastError)
+		})
+	}
+
+	handleCtx, cancel := context.WithDeadline(ctx, deadline)
+	err := r.handle(handleCtx, handle, message)
+	cancel()
+
+	return r.record(ctx, func(repository repository.OutboxRepository) error {
+		if err == nil {
+			return repository.MarkDelivered(ctx, message, r.now())
+		}
+
+		var deferred *DeferredError
+		if errors.As(err, &deferred) {
+			return repository.MarkFailed(ctx, message, message.Attempts-1, deferred.Until, err.Error())
+		}
+
+		if message.Attempts >= r.maxAttempts {
+			// Later messages with its ordering key are held until it is requeued
+			// with "outbox requeue <id>"
+			slog.ErrorContext(ctx, "outbox message is dead", "message_id", message.Id, "ordering_key", message.OrderingKey, "attempts", message.Attempts, "error", err)
+			return repository.MarkDead(ctx, message, err.Error())
+		}
+		return repository.MarkFailed(ctx, message, message.Attempts, r.now().Add(r.backoff(message.Attempts)), err.Error())
+	})
+}
+
+// record stores the outcome of a delivery in a database transaction of its own.
+func (r *OutboxRelay) record(ctx context.Context, mark func(repository.OutboxRepository) error) error {
+	tx := r.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	if err := mark(r.outboxRepositoryFactory.New(handler)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// handle runs handle in a span continuing the trace of the request that
+// enqueued message.
+func (r *OutboxRelay) handle(ctx context.Context, handle OutboxHandler, message domainModel.OutboxMessage) error {
+	parent := otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(message.TraceContext))
+	ctx, span := tracer().Start(parent, "OutboxRelay.deliver "+message.Kind, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		attribute.Int64("outbox.message_id", message.Id),
+		attribute.String("outbox.kind", message.Kind),
+		attribute.Int("outbox.attempt", message.Attempts),
+	))
+	err := handle(ctx, message)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+// backoff doubles the delay with every attempt up to maxBackoff and adds up to
+// 20% jitter so failed messages do not retry in lockstep.
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	delay := r.baseBackoff
+	for i := 1; i < attempts && delay < r.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.maxBackoff {
+		delay = r.maxBackoff
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
@@ -0,0 +1,303 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/i18n"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingNotificationService records sent notifications and fails while err is set.
+type recordingNotificationService struct {
+	mu            sync.Mutex
+	err           error
+	notifications []domainModel.Notification
+}
+
+func (n *recordingNotificationService) SendTransactionNotification(ctx context.Context, notification domainModel.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.err != nil {
+		return n.err
+	}
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+// newTestDispatcher dispatches messages of outbox through channels without stored preferences.
+func newTestDispatcher(t *testing.T, outbox *repository.InMemoryOutboxRepository, channels map[string]driven.NotificationService, options ...service.NotificationDispatcherOption) (*service.NotificationDispatcher, *repository.InMemoryNotificationPreferencesRepository) {
+	renderer, err := i18n.NewRenderer()
+	assert.NoError(t, err)
+	preferences := repository.NewInMemoryNotificationPreferencesRepository()
+	dispatcher := service.NewNotificationDispatcher(&db.PostgresTransactionMockFactory{}, repository.NewInMemoryNotificationPreferencesRepositoryFactory(preferences), repository.NewInMemoryOutboxRepositoryFactory(outbox), renderer, channels, options...)
+	return dispatcher, preferences
+}
+
+func TestCreateTransactionNotifiesThroughOutbox(t *testing.T) {
+	repo := repository.NewInMemoryTransactionRepository()
+	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
+	txFactory := &db.PostgresTransactionMockFactory{}
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	service := service.NewTransactionService(repoFactory, txFactory, outboxFactory)
+
+	ctx := context.Background()
+	response, err := service.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: uuid.New().String(), Type: "deposit", Amount: 100})
+	assert.NoError(t, err)
+
+	messages := outbox.Messages()
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, domainModel.OutboxKindNotification, messages[0].Kind)
+	assert.Equal(t, domainModel.OutboxPending, messages[0].Status)
+	assert.Contains(t, string(messages[0].Payload), response.Id)
+
+	// Rejected withdrawals notify about the insufficient balance
+	_, err = service.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: uuid.New().String(), Type: "withdrawal", Amount: 100})
+	assert.ErrorIs(t, err, domain.ErrInsufficientBalance)
+	messages = outbox.Messages()
+	assert.Equal(t, 2, len(messages))
+	var event domainModel.TransactionEvent
+	assert.NoError(t, json.Unmarshal(messages[1].Payload, &event))
+	assert.Equal(t, domainModel.InsufficientBalance, event.Type)
+	assert.Equal(t, int64(100), event.Transaction.Amount)
+	assert.NotEmpty(t, event.Id)
+}
+
+func TestUpdateAndDeleteTransactionNotifyThroughOutbox(t *testing.T) {
+	repo := repository.NewInMemoryTransactionRepository()
+	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
+	txFactory := &db.PostgresTransactionMockFactory{}
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	service := service.NewTransactionService(repoFactory, txFactory, outboxFactory)
+
+	ctx := context.Background()
+	userId := uuid.New().String()
+	created, err := service.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: userId, Type: "deposit", Amount: 100})
+	assert.NoError(t, err)
+	assert.NoError(t, service.UpdateTransaction(ctx, model.UpdateTransactionRequest{Id: created.Id, UserId: userId, Type: "deposit", Amount: 150}))
+	assert.NoError(t, service.DeleteTransaction(ctx, model.DeleteTransactionRequest{Id: created.Id}))
+
+	var types []domainModel.TransactionEventType
+	for _, message := range outbox.Messages() {
+		assert.Equal(t, domainModel.OutboxKindNotification, message.Kind)
+		assert.Equal(t, userId, message.UserId)
+		var event domainModel.TransactionEvent
+		assert.NoError(t, json.Unmarshal(message.Payload, &event))
+		assert.Equal(t, created.Id, event.Transaction.Id)
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []domainModel.TransactionEventType{domainModel.TransactionCreated, domainModel.TransactionUpdated, domainModel.TransactionDeleted}, types)
+}
+
+func TestOutboxRelay(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	txFactory := &db.PostgresTransactionMockFactory{}
+
+	var now time.Time
+	clock := func() time.Time { return now }
+	notifications := &recordingNotificationService{err: errors.New("smtp unavailable")}
+	relay := service.NewOutboxRelay(txFactory, outboxFactory, service.WithClock(clock), service.WithRetries(3, time.Second, time.Minute))
+	dispatcher, _ := newTestDispatcher(t, outbox, map[string]driven.NotificationService{"test": notifications})
+	relay.Handle(domainModel.OutboxKindNotification, dispatcher.Handle)
+
+	ctx := context.Background()
+	_, err := outbox.Enqueue(ctx, domainModel.OutboxMessage{
+		Kind:    domainModel.OutboxKindNotification,
+		UserId:  uuid.New().String(),
+		Payload: []byte(`{"type":"created","transaction":{"id":"42"}}`),
+	})
+	assert.NoError(t, err)
+	now = time.Now()
+
+	// A failed delivery is retried with exponential backoff
+	processed, err := relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	message := outbox.Messages()[0]
+	assert.Equal(t, domainModel.OutboxPending, message.Status)
+	assert.Equal(t, 1, message.Attempts)
+	assert.Equal(t, "test: smtp unavailable", message.LastError)
+	firstDelay := message.NextAttemptAt.Sub(now)
+	assert.GreaterOrEqual(t, firstDelay, time.Second)
+
+	// Not due yet
+	processed, err = relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, processed)
+
+	now = message.NextAttemptAt
+	_, err = relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	message = outbox.Messages()[0]
+	assert.Equal(t, 2, message.Attempts)
+	assert.GreaterOrEqual(t, message.NextAttemptAt.Sub(now), 2*time.Second)
+
+	// Recovery delivers the message exactly once
+	notifications.err = nil
+	now = message.NextAttemptAt
+	_, err = relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	message = outbox.Messages()[0]
+	assert.Equal(t, domainModel.OutboxDelivered, message.Status)
+	assert.Equal(t, 1, len(notifications.notifications))
+	assert.Equal(t, domainModel.TransactionCreated, notifications.notifications[0].Event.Type)
+	assert.Equal(t, "42", notifications.notifications[0].Event.Transaction.Id)
+
+	processed, err = relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, processed)
+}
+
+func TestOutboxRelayRetriesOnlyFailedChannels(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	txFactory := &db.PostgresTransactionMockFactory{}
+
+	var now time.Time
+	clock := func() time.Time { return now }
+	email := &recordingNotificationService{}
+	webhook := &recordingNotificationService{err: errors.New("unreachable")}
+	relay := service.NewOutboxRelay(txFactory, outboxFactory, service.WithClock(clock), service.WithRetries(3, time.Second, time.Minute))
+	dispatcher, _ := newTestDispatcher(t, outbox, map[string]driven.NotificationService{"email": email, "webhook": webhook})
+	relay.Handle(domainModel.OutboxKindNotification, dispatcher.Handle)
+
+	ctx := context.Background()
+	_, err := outbox.Enqueue(ctx, domainModel.OutboxMessage{
+		Kind:    domainModel.OutboxKindNotification,
+		UserId:  uuid.New().String(),
+		Payload: []byte(`{"type":"created","transaction":{"id":"42"}}`),
+	})
+	assert.NoError(t, err)
+	now = time.Now()
+
+	_, err = relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	message := outbox.Messages()[0]
+	assert.Equal(t, domainModel.OutboxPending, message.Status)
+	assert.Equal(t, "webhook: unreachable", message.LastError)
+	assert.Equal(t, 1, len(email.notifications))
+
+	// The retry does not send the e-mail again
+	webhook.err = nil
+	now = message.NextAttemptAt
+	_, err = relay.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, domainModel.OutboxDelivered, outbox.Messages()[0].Status)
+	assert.Equal(t, 1, len(email.notifications))
+	assert.Equal(t, 1, len(webhook.notifications))
+}
+
+func TestOutboxRelayLeasesMessages(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	txFactory := &db.PostgresTransactionMockFactory{}
+
+	var now time.Time
+	clock := func() time.Time { return now }
+	first := service.NewOutboxRelay(txFactory, outboxFactory, service.WithClock(clock), service.WithLease(time.Minute))
+	second := service.NewOutboxRelay(txFactory, outboxFactory, service.WithClock(clock), service.WithLease(time.Minute))
+
+	ctx := context.Background()
+	_, err := outbox.Enqueue(ctx, domainModel.OutboxMessage{Kind: domainModel.OutboxKindNotification, UserId: uuid.New().String(), Payload: []byte(`{}`)})
+	assert.NoError(t, err)
+	now = time.Now()
+
+	var secondProcessed []int
+	second.Handle(domainModel.OutboxKindNotification, func(ctx context.Context, message domainModel.OutboxMessage) error {
+		return nil
+	})
+	first.Handle(domainModel.OutboxKindNotification, func(ctx context.Context, message domainModel.OutboxMessage) error {
+		// The message is not delivered twice while it is leased
+		processed, err := second.ProcessBatch(ctx)
+		assert.NoError(t, err)
+		secondProcessed = append(secondProcessed, processed)
+
+		// Once the lease expired the message is attempted again
+		now = now.Add(2 * time.Minute)
+		processed, err = second.ProcessBatch(ctx)
+		assert.NoError(t, err)
+		secondProcessed = append(secondProcessed, processed)
+		return errors.New("too slow")
+	})
+
+	processed, err := first.ProcessBatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, []int{0, 1}, secondProcessed)
+
+	// The outcome of the expired lease does not overwrite the delivery
+	message := outbox.Messages()[0]
+	assert.Equal(t, domainModel.OutboxDelivered, message.Status)
+	assert.Equal(t, 2, message.Attempts)
+	assert.Empty(t, message.LastError)
+	assert.Nil(t, message.LockedUntil)
+}
+
+func TestOutboxRelayCompletesTheBatchInProgressWhenStopped(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	relay := service.NewOutboxRelay(&db.PostgresTransactionMockFactory{}, repository.NewInMemoryOutboxRepositoryFactory(outbox), service.WithPollInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 2; i++ {
+		_, err := outbox.Enqueue(ctx, domainModel.OutboxMessage{Kind: domainModel.OutboxKindNotification, UserId: uuid.New().String(), Payload: []byte(`{}`)})
+		assert.NoError(t, err)
+	}
+
+	// The relay is stopped while the first message is delivered
+	var deliveryErrs []error
+	relay.Handle(domainModel.OutboxKindNotification, func(ctx context.Context, message domainModel.OutboxMessage) error {
+		cancel()
+		deliveryErrs = append(deliveryErrs, ctx.Err())
+		return nil
+	})
+	assert.NoError(t, relay.Run(ctx))
+
+	assert.Equal(t, []error{nil, nil}, deliveryErrs)
+	for _, message := range outbox.Messages() {
+		assert.Equal(t, domainModel.OutboxDelivered, message.Status)
+	}
+}
+
+func TestOutboxRelayDeadLetters(t *testing.T) {
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	txFactory := &db.PostgresTransactionMockFactory{}
+
+	now := time.Now().Add(time.Minute)
+	relay := service.NewOutboxRelay(txFactory, outboxFactory, service.WithClock(func() time.Time { return now }), service.WithRetries(2, time.Second, time.Minute))
+	dispatcher, _ := newTestDispatcher(t, outbox, map[string]driven.NotificationService{"test": &recordingNotificationService{err: errors.New("down")}})
+	relay.Handle(domainModel.OutboxKindNotification, dispatcher.Handle)
+
+	ctx := context.Background()
+	_, err := outbox.Enqueue(ctx, domainModel.OutboxMessage{Kind: domainModel.OutboxKindNotification, UserId: uuid.New().String(), Payload: []byte(`{}`)})
+	assert.NoError(t, err)
+	_, err = outbox.Enqueue(ctx, domainModel.OutboxMessage{Kind: "unknown", UserId: uuid.New().String(), Payload: []byte(`{}`)})
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = relay.ProcessBatch(ctx)
+		assert.NoError(t, err)
+		now = now.Add(time.Hour)
+	}
+
+	messages := outbox.Messages()
+	assert.Equal(t, domainModel.OutboxDead, messages[0].Status)
+	assert.Equal(t, 2, messages[0].Attempts)
+	assert.Equal(t, domainModel.OutboxDead, messages[1].Status)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+// pageToken is the content of an opaque page token. Query fingerprints the
+// request the token was issued for, so a token cannot be replayed against a
+// request with different filters or ordering.
+type pageToken struct {
+	Cursor json.RawMessage `json:"c"`
+	Query  string          `json:"q"`
+}
+
+// PageTokenCodec issues and verifies HMAC signed page tokens. All replicas
+// serving the same clients must share the key.
+type PageTokenCodec struct {
+	key []byte
+}
+
+func NewPageTokenCodec(key []byte) *PageTokenCodec {
+	return &PageTokenCodec{key: key}
+}
+
+// NewRandomPageTokenCodec creates a codec with a random key, tokens it issues
+// stop working once the process restarts.
+func NewRandomPageTokenCodec() *PageTokenCodec {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return NewPageTokenCodec(key)
+}
+
+func (c *PageTokenCodec) Encode(cursor domainModel.TransactionCursor, query string) (string, error) {
+	return c.encode(cursor, query)
+}
+
+// Decode verifies a token and returns its cursor, an empty token means the first page.
+func (c *PageTokenCodec) Decode(token, query string) (*domainModel.TransactionCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	var cursor domainModel.TransactionCursor
+	if err := c.decode(token, query, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// EncodeId issues a token for listings ordered by a numeric id.
+func (c *PageTokenCodec) EncodeId(id int64, query string) (string, error) {
+	return c.encode(id, query)
+}
+
+// DecodeId verifies a token issued by EncodeId, an empty token means the first page and returns zero.
+func (c *PageTokenCodec) DecodeId(token, query string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	var id int64
+	if err := c.decode(token, query, &id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (c *PageTokenCodec) encode(cursor interface{}, query string) (string, error) {
+	encodedCursor, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(pageToken{Cursor: encodedCursor, Query: query})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+func (c *PageTokenCodec) decode(token, query string, cursor interface{}) error {
+	encodedPayload, encodedSignature, found := strings.Cut(token, ".")
+	if !found {
+		return domain.ErrInvalidPageToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return domain.ErrInvalidPageToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, c.sign(payload)) {
+		return domain.ErrInvalidPageToken
+	}
+
+	var decoded pageToken
+	if err := json.Unmarshal(payload, &decoded); err != nil || decoded.Query != query {
+		return domain.ErrInvalidPageToken
+	}
+	if err := json.Unmarshal(decoded.Cursor, cursor); err != nil {
+		return domain.ErrInvalidPageToken
+	}
+	return nil
+}
+
+func (c *PageTokenCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// queryFingerprint hashes the parts of a request that must stay the same while paging.
+func queryFingerprint(parts ...interface{}) string {
+	encoded, _ := json.Marshal(parts)
+	sum := sha256.Sum256(encoded)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// encodeResumeToken wraps an event sequence in an opaque resume token.
+func encodeResumeToken(sequence int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(sequence, 10)))
+}
+
+func decodeResumeToken(token string) (int64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, domain.ErrInvalidResumeToken
+	}
+	sequence, err := strconv.ParseInt(string(decoded), 10, 64)
+	if err != nil || sequence < 0 {
+		return 0, domain.ErrInvalidResumeToken
+	}
+	return sequence, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/port/driver"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+	"github.com/nullexp/finman-transaction-service/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+
+// tracer is looked up on every use so spans go to the tracer provider
+// installed last.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// injectTraceContext returns the W3C trace context of ctx to store along
+// with asynchronous work, or nil outside of a trace.
+func injectTraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// tracedTransactionService wraps every method of a TransactionService in a span.
+type tracedTransactionService struct {
+	next driver.TransactionService
+}
+
+func NewTracedTransactionService(next driver.TransactionService) driver.TransactionService {
+	return &tracedTransactionService{next: next}
+}
+
+func (s *tracedTransactionService) CreateTransaction(ctx context.Context, request model.CreateTransactionRequest) (*model.CreateTransactionResponse, error) {
+	ctx, span := tracer().Start(ctx, "transactionService.CreateTransaction")
+	response, err := s.next.CreateTransaction(ctx, request)
+	tracing.EndSpan(span, err)
+	return response, err
+}
+
+func (s *tracedTransactionService) GetTransactionById(ctx context.Context, request model.GetTransactionByIdRequest) (*model.GetTransactionByIdResponse, error) {
+	ctx, span := tracer().Start(ctx, "transactionService.GetTransactionById")
+	response, err := s.next.GetTransactionById(ctx, request)
+	tracing.EndSpan(span, err)
+	return response, err
+}
+
+func (s *tracedTransactionService) GetOwnTransactionById(ctx context.Context, request model.GetOwnTransactionByIdRequest) (*model.GetOwnTransactionByIdResponse, error) {
+	ctx, span := tracer().Start(ctx, "transactionService.GetOwnTransactionById")
+	response, err := s.next.GetOwnTransactionById(ctx, request)
+	tracing.EndSpan(span, err)
+	return response, err
+}
+
+func (s *tracedTransactionService) GetAllTransactions(ctx context.Context) (*model.GetAllTransactionsResponse, error) {
+	ctx, span := tracer().Start(ctx, "transactionService.GetAllTransactions")
+	response, err := s.next.GetAllTransactions(ctx)
+	tracing.EndSpan(span, err)
+	return response, err
+}
+
+func (s *tracedTransactionService) UpdateTransaction(ctx context.Context, request model.UpdateTransactionRequest) error {
+	ctx, span := tracer().Start(ctx, "transactionService.UpdateTransaction")
+	err := s.next.UpdateTransaction(ctx, request)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (s *tracedTransactionService) DeleteTransaction(ctx context.Context, request model.DeleteTransactionRequest) error {
+	ctx, span := tracer().Start(ctx, "transactionService.DeleteTransaction")
+	err := s.next.DeleteTransaction(ctx, request)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (s *tracedTransactionService) GetTransactionsByUserId(ctx context.Context, request model.GetTransactionsByUserIdRequest) (*model.GetTransactionsByUserIdResponse, error) {
+	ctx, span := tracer().Start(ctx, "transactionService.GetTransactionsByUserId")
+	response, err := s.next.GetTransactionsByUserId(ctx, request)
+	tracing.EndSpan(span, err)
+	return response, err
+}
+
+func (s *tracedTransactionService) GetTransactionsWithPagination(ctx context.Context, request model.GetTransactionsWithPaginationRequest) (*model.GetTransactionsWithPaginationResponse, error) {
+	ctx, span := tracer().Start(ctx, "transactionService.GetTransactionsWithPagination")
+	response, err := s.next.GetTransactionsWithPagination(ctx, request)
+	tracing.EndSpan(span, err)
+	return response, err
+}
+
+func (s *tracedTransactionService) SearchTransactions(ctx context.Context, request model.SearchTransactionsRequest) (*model.SearchTransactionsResponse, error) {
+	ctx, span := tracer().Start(ctx, "transactionService.SearchTransactions")
+	response, err := s.next.SearchTransactions(ctx, request)
+	tracing.EndSpan(span, err)
+	return response, err
+}
+
+func (s *tracedTransactionService) ListTransactions(ctx context.Context, request model.ListTransactionsRequest) (*model.ListTransactionsResponse, error) {
+	ctx, span := tracer().Start(ctx, "transactionService.ListTransactions")
+	response, err := s.next.ListTransactions(ctx, request)
+	tracing.EndSpan(span, err)
+	return response, err
+}
+
+func (s *tracedTransactionService) StreamTransactions(ctx context.Context, request model.StreamTransactionsRequest, send func([]model.Transaction) error) error {
+	ctx, span := tracer().Start(ctx, "transactionService.StreamTransactions")
+	err := s.next.StreamTransactions(ctx, request, send)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (s *tracedTransactionService) SubscribeTransactions(ctx context.Context, request model.SubscribeTransactionsRequest, send func(model.TransactionEvent) error) error {
+	ctx, span := tracer().Start(ctx, "transactionService.SubscribeTransactions")
+	err := s.next.SubscribeTransactions(ctx, request, send)
+	tracing.EndSpan(span, err)
+	return err
+}
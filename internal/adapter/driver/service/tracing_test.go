@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driver"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useInMemoryTracing records the spans of the test in the returned exporter.
+func useInMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+func spanNamed(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not found", "no span named %s", name)
+	return tracetest.SpanStub{}
+}
+
+func newTracedTransactionService(outboxFactory *repository.InMemoryOutboxRepositoryFactory) driver.TransactionService {
+	repoFactory := repository.NewTracedTransactionRepositoryFactory(repository.NewInMemoryTransactionRepositoryFactory(repository.NewInMemoryTransactionRepository()))
+	return service.NewTracedTransactionService(service.NewTransactionService(repoFactory, &db.PostgresTransactionMockFactory{}, outboxFactory))
+}
+
+func TestTracedTransactionServiceSpans(t *testing.T) {
+	exporter := useInMemoryTracing(t)
+	txService := newTracedTransactionService(repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
+
+	_, err := txService.CreateTransaction(context.Background(), model.CreateTransactionRequest{UserId: uuid.New().String(), Type: "deposit", Amount: 100})
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	serviceSpan := spanNamed(t, spans, "transactionService.CreateTransaction")
+	querySpan := spanNamed(t, spans, "TransactionRepository.CreateTransaction")
+	assert.Equal(t, serviceSpan.SpanContext.TraceID(), querySpan.SpanContext.TraceID())
+	assert.Equal(t, serviceSpan.SpanContext.SpanID(), querySpan.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindClient, querySpan.SpanKind)
+	assert.Equal(t, codes.Unset, serviceSpan.Status.Code)
+}
+
+func TestTracedTransactionServiceRecordsErrors(t *testing.T) {
+	exporter := useInMemoryTracing(t)
+	txService := newTracedTransactionService(repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
+
+	_, err := txService.GetTransactionById(context.Background(), model.GetTransactionByIdRequest{Id: uuid.New().String()})
+	require.Error(t, err)
+
+	span := spanNamed(t, exporter.GetSpans(), "transactionService.GetTransactionById")
+	assert.Equal(t, codes.Error, span.Status.Code)
+	require.NotEmpty(t, span.Events)
+	assert.Equal(t, "exception", span.Events[0].Name)
+}
+
+func TestOutboxDeliveryContinuesTrace(t *testing.T) {
+	exporter := useInMemoryTracing(t)
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	txService := newTracedTransactionService(outboxFactory)
+
+	ctx, request := otel.Tracer("test").Start(context.Background(), "CreateTransaction RPC")
+	_, err := txService.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: uuid.New().String(), Type: "deposit", Amount: 100})
+	require.NoError(t, err)
+	request.End()
+
+	messages := outbox.Messages()
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0].TraceContext, "traceparent")
+
+	var handled trace.SpanContext
+	relay := service.NewOutboxRelay(&db.PostgresTransactionMockFactory{}, outboxFactory)
+	relay.Handle(domainModel.OutboxKindNotification, func(ctx context.Context, message domainModel.OutboxMessage) error {
+		handled = trace.SpanContextFromContext(ctx)
+		return nil
+	})
+	_, err = relay.ProcessBatch(context.Background())
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	serviceSpan := spanNamed(t, spans, "transactionService.CreateTransaction")
+	delivery := spanNamed(t, spans, "OutboxRelay.deliver "+domainModel.OutboxKindNotification)
+	assert.Equal(t, request.SpanContext().TraceID(), delivery.SpanContext.TraceID())
+	assert.Equal(t, serviceSpan.SpanContext.SpanID(), delivery.Parent.SpanID())
+	assert.True(t, delivery.Parent.IsRemote())
+	assert.Equal(t, trace.SpanKindConsumer, delivery.SpanKind)
+	assert.Equal(t, delivery.SpanContext.SpanID(), handled.SpanID())
+}
+
+func TestOutboxMessagesOutsideOfTracesHaveNoTraceContext(t *testing.T) {
+	useInMemoryTracing(t)
+	outbox := repository.NewInMemoryOutboxRepository()
+	txService := service.NewTransactionService(repository.NewInMemoryTransactionRepositoryFactory(repository.NewInMemoryTransactionRepository()), &db.PostgresTransactionMockFactory{}, repository.NewInMemoryOutboxRepositoryFactory(outbox))
+
+	_, err := txService.CreateTransaction(context.Background(), model.CreateTransactionRequest{UserId: uuid.New().String(), Type: "deposit", Amount: 100})
+	require.NoError(t, err)
+
+	messages := outbox.Messages()
+	require.Len(t, messages, 1)
+	assert.Nil(t, messages[0].TraceContext)
+}
+
+func TestAlertNotificationsContinueTrace(t *testing.T) {
+	useInMemoryTracing(t)
+	ctx := context.Background()
+	outbox := repository.NewInMemoryOutboxRepository()
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(outbox)
+	settings := repository.NewInMemoryAlertSettingsRepository()
+	transactionFactory := repository.NewInMemoryTransactionRepositoryFactory(repository.NewInMemoryTransactionRepository())
+	alerter := service.NewAlerter(transactionFactory, repository.NewInMemoryAlertSettingsRepositoryFactory(settings), outboxFactory)
+	txService := service.NewTransactionService(transactionFactory, &db.PostgresTransactionMockFactory{}, outboxFactory, service.WithAlerter(alerter))
+
+	userId := uuid.New().String()
+	require.NoError(t, settings.SaveAlertSettings(ctx, domainModel.AlertSettings{UserId: userId, LargeTransactionAmount: 100}))
+
+	ctx, request := otel.Tracer("test").Start(ctx, "CreateTransaction RPC")
+	_, err := txService.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: userId, Type: "deposit", Amount: 500})
+	require.NoError(t, err)
+	request.End()
+
+	messages := outbox.Messages()
+	require.Len(t, messages, 2)
+	for _, message := range messages {
+		assert.Contains(t, message.TraceContext, "traceparent")
+	}
+}
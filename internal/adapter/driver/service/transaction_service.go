@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
-	"log"
+	"encoding/json"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/nullexp/finman-transaction-service/internal/domain"
 	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
 	"github.com/nullexp/finman-transaction-service/internal/port/driven"
@@ -55,14 +57,253 @@ func ToModelTransactions(dms []domainModel.Transaction) []model.Transaction {
 	return models
 }
 
+func ToModelSearchResults(drs []domainModel.TransactionSearchResult) []model.TransactionSearchResult {
+	var results []model.TransactionSearchResult
+	for _, dr := range drs {
+		results = append(results, model.TransactionSearchResult{Transaction: ToModelTransaction(dr.Transaction), Rank: dr.Rank})
+	}
+	return results
+}
+
+func ToModelTransactionEvent(event domainModel.TransactionEvent) model.TransactionEvent {
+	return model.TransactionEvent{
+		Id:          event.Id,
+		Type:        string(event.Type),
+		Transaction: ToModelTransaction(event.Transaction),
+		OccurredAt:  event.OccurredAt,
+		ResumeToken: encodeResumeToken(event.Sequence),
+	}
+}
+
+func ToDomainFilter(f model.TransactionFilter) domainModel.TransactionFilter {
+	return domainModel.TransactionFilter{
+		UserIds:             f.UserIds,
+		Types:               f.Types,
+		FromDate:            f.FromDate,
+		ToDate:              f.ToDate,
+		MinAmount:           f.MinAmount,
+		MaxAmount:           f.MaxAmount,
+		DescriptionContains: f.DescriptionContains,
+		CreatedFrom:         f.CreatedFrom,
+		CreatedTo:           f.CreatedTo,
+		UpdatedFrom:         f.UpdatedFrom,
+		UpdatedTo:           f.UpdatedTo,
+	}
+}
+
+// ToDomainSort converts a validated sort field and order, falling back to the newest transactions first.
+func ToDomainSort(sortBy, sortOrder string) domainModel.TransactionSort {
+	sort := domainModel.DefaultTransactionSort
+	if sortBy != "" {
+		sort.Field = domainModel.SortField(sortBy)
+	}
+	if sortOrder != "" {
+		sort.Descending = sortOrder == "desc"
+	}
+	return sort
+}
+
+// validateRanges rejects filters whose lower bound is above their upper bound.
+func validateRanges(f domainModel.TransactionFilter) error {
+	if !isTimeRangeValid(f.FromDate, f.ToDate) || !isTimeRangeValid(f.CreatedFrom, f.CreatedTo) || !isTimeRangeValid(f.UpdatedFrom, f.UpdatedTo) {
+		return domain.ErrInvalidDateRange
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil && *f.MinAmount > *f.MaxAmount {
+		return domain.ErrInvalidAmountRange
+	}
+	return nil
+}
+
+func isTimeRangeValid(from, to *time.Time) bool {
+	return from == nil || to == nil || !from.After(*to)
+}
+
+const (
+	defaultSearchPageSize  = 20
+	defaultListPageSize    = 50
+	defaultStreamBatchSize = 500
+	replayBatchSize        = 500
+	// defaultAllTransactionsLimit keeps GetAllTransactions responses well
+	// below the 4 MB message limit of gRPC
+	defaultAllTransactionsLimit = 1000
+)
+
 type transactionService struct {
 	transactionRepositoryFactory repository.TransactionRepositoryFactory
 	dbTransactionFactory         db.DbTransactionFactory
-	notificationService          driven.NotificationService
+	outboxRepositoryFactory      repository.OutboxRepositoryFactory
+	pageTokenCodec               *PageTokenCodec
+	eventRepositoryFactory       repository.TransactionEventRepositoryFactory
+	eventSubscriber              driven.TransactionEventSubscriber
+	publishEvents                bool
+	alerter                      *Alerter
+	allTransactionsLimit         int
+}
+
+// Option configures optional dependencies of the transaction service.
+type Option func(*transactionService)
+
+// WithPageTokenCodec sets the codec used to sign page tokens, by default a
+// random key is used which only suits a single replica.
+func WithPageTokenCodec(codec *PageTokenCodec) Option {
+	return func(ts *transactionService) {
+		ts.pageTokenCodec = codec
+	}
+}
+
+// WithTransactionEvents records an event for every write in the same database
+// transaction and serves SubscribeTransactions from the given subscriber.
+func WithTransactionEvents(factory repository.TransactionEventRepositoryFactory, subscriber driven.TransactionEventSubscriber) Option {
+	return func(ts *transactionService) {
+		ts.eventRepositoryFactory = factory
+		ts.eventSubscriber = subscriber
+	}
+}
+
+// WithEventPublishing enqueues every recorded event in the outbox for a relay
+// handler to publish, in order per user.
+func WithEventPublishing() Option {
+	return func(ts *transactionService) {
+		ts.publishEvents = true
+	}
+}
+
+// WithAllTransactionsLimit makes GetAllTransactions fail with
+// ErrResultTooLarge once there are more than limit transactions, 1000 by
+// default. Zero returns all of them.
+func WithAllTransactionsLimit(limit int) Option {
+	return func(ts *transactionService) {
+		ts.allTransactionsLimit = limit
+	}
+}
+
+// WithAlerter checks the alerts of the user with every write.
+func WithAlerter(alerter *Alerter) Option {
+	return func(ts *transactionService) {
+		ts.alerter = alerter
+	}
+}
+
+func NewTransactionService(trf repository.TransactionRepositoryFactory, dtf db.DbTransactionFactory, orf repository.OutboxRepositoryFactory, options ...Option) *transactionService {
+	ts := &transactionService{transactionRepositoryFactory: trf, dbTransactionFactory: dtf, outboxRepositoryFactory: orf, allTransactionsLimit: defaultAllTransactionsLimit}
+	for _, option := range options {
+		option(ts)
+	}
+	if ts.pageTokenCodec == nil {
+		ts.pageTokenCodec = NewRandomPageTokenCodec()
+	}
+	return ts
+}
+
+// recordEvent appends a transaction event within the database transaction of
+// handler. Without an event repository the event is only returned, with an id
+// generated locally.
+func (ts *transactionService) recordEvent(ctx context.Context, handler db.DbHandler, eventType domainModel.TransactionEventType, transaction domainModel.Transaction) (*domainModel.TransactionEvent, error) {
+	event := &domainModel.TransactionEvent{
+		Type:        eventType,
+		UserId:      transaction.UserId,
+		Transaction: transaction,
+		OccurredAt:  time.Now(),
+	}
+	if ts.eventRepositoryFactory == nil {
+		event.Id = uuid.New().String()
+	} else {
+		recorded, err := ts.eventRepositoryFactory.New(handler).AppendEvent(ctx, *event)
+		if err != nil {
+			return nil, err
+		}
+		event = recorded
+	}
+
+	if ts.publishEvents {
+		if err := ts.enqueue(ctx, handler, domainModel.OutboxKindEvent, *event, event.UserId); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+// checkAlerts raises the alerts caused by event within the database
+// transaction of handler, before it commits.
+func (ts *transactionService) checkAlerts(ctx context.Context, handler db.DbHandler, event domainModel.TransactionEvent, previous *domainModel.Transaction) error {
+	if ts.alerter == nil {
+		return nil
+	}
+	return ts.alerter.Check(ctx, handler, event, previous)
+}
+
+// enqueue records event in the outbox as a message of kind, messages sharing
+// a non-empty orderingKey are delivered in order.
+func (ts *transactionService) enqueue(ctx context.Context, handler db.DbHandler, kind string, event domainModel.TransactionEvent, orderingKey string) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = ts.outboxRepositoryFactory.New(handler).Enqueue(ctx, domainModel.OutboxMessage{
+		Kind:         kind,
+		UserId:       event.UserId,
+		OrderingKey:  orderingKey,
+		TraceContext: injectTraceContext(ctx),
+		Payload:      payload,
+	})
+	return err
+}
+
+// enqueueNotification records a notification about event in the outbox, it is
+// delivered by the OutboxRelay once the database transaction of handler commits.
+func (ts *transactionService) enqueueNotification(ctx context.Context, handler db.DbHandler, event domainModel.TransactionEvent) error {
+	return ts.enqueue(ctx, handler, domainModel.OutboxKindNotification, event, "")
+}
+
+// notifyInsufficientBalance enqueues a notification about a rejected withdrawal
+// and commits it, as nothing else was written in the transaction.
+func (ts *transactionService) notifyInsufficientBalance(ctx context.Context, tx db.DbTransaction, handler db.DbHandler, request model.CreateTransactionRequest) error {
+	event := domainModel.TransactionEvent{
+		Id:     uuid.New().String(),
+		Type:   domainModel.InsufficientBalance,
+		UserId: request.UserId,
+		Transaction: domainModel.Transaction{
+			UserId:      request.UserId,
+			Type:        request.Type,
+			Amount:      request.Amount,
+			Date:        time.Now(),
+			Description: request.Description,
+		},
+		OccurredAt: time.Now(),
+	}
+	if err := ts.enqueueNotification(ctx, handler, event); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func NewTransactionService(trf repository.TransactionRepositoryFactory, dtf db.DbTransactionFactory, ns driven.NotificationService) *transactionService {
-	return &transactionService{transactionRepositoryFactory: trf, dbTransactionFactory: dtf, notificationService: ns}
+// listPage reads one page of transactions in keyset order. One extra row is
+// fetched to find out whether a next page exists.
+func (ts *transactionService) listPage(ctx context.Context, repository repository.TransactionRepository, filter domainModel.TransactionFilter, sort domainModel.TransactionSort, pageSize int, pageToken string) ([]domainModel.Transaction, string, error) {
+	if pageSize == 0 {
+		pageSize = defaultListPageSize
+	}
+
+	query := queryFingerprint(filter, sort)
+	after, err := ts.pageTokenCodec.Decode(pageToken, query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	transactions, err := repository.ListTransactions(ctx, filter, sort, after, pageSize+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(transactions) <= pageSize {
+		return transactions, "", nil
+	}
+
+	transactions = transactions[:pageSize]
+	nextPageToken, err := ts.pageTokenCodec.Encode(domainModel.CursorOf(transactions[pageSize-1]), query)
+	if err != nil {
+		return nil, "", err
+	}
+	return transactions, nextPageToken, nil
 }
 
 func (ts *transactionService) CreateTransaction(ctx context.Context, request model.CreateTransactionRequest) (*model.CreateTransactionResponse, error) {
@@ -86,6 +327,10 @@ func (ts *transactionService) CreateTransaction(ctx context.Context, request mod
 			return nil, err
 		}
 		if balance < request.Amount {
+			insufficientBalanceRejections.Inc()
+			if err := ts.notifyInsufficientBalance(ctx, tx, handler, request); err != nil {
+				return nil, err
+			}
 			return nil, domain.ErrInsufficientBalance
 		}
 	}
@@ -103,18 +348,28 @@ func (ts *transactionService) CreateTransaction(ctx context.Context, request mod
 		return nil, err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
+	created, err := repository.GetTransactionById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, domain.ErrTransactionNotFound
+	}
+	event, err := ts.recordEvent(ctx, handler, domainModel.TransactionCreated, *created)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.enqueueNotification(ctx, handler, *event); err != nil {
+		return nil, err
+	}
+	if err := ts.checkAlerts(ctx, handler, *event, nil); err != nil {
 		return nil, err
 	}
 
-	// Send notification asynchronously
-	go func(ctx context.Context) {
-		message := "Transaction created with ID: " + id
-		err := ts.notificationService.SendTransactionNotification(ctx, request.UserId, message)
-		if err != nil {
-			log.Printf("Failed to send notification: %v\n", err)
-		}
-	}(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	transactionsCreated.WithLabelValues(created.Type).Inc()
 
 	return &model.CreateTransactionResponse{Id: id}, nil
 }
@@ -192,7 +447,14 @@ func (ts *transactionService) GetAllTransactions(ctx context.Context) (*model.Ge
 
 	repository := ts.transactionRepositoryFactory.New(handler)
 
-	transactions, err := repository.GetAllTransactions(ctx)
+	var transactions []domainModel.Transaction
+	err = repository.StreamTransactions(ctx, domainModel.TransactionFilter{}, domainModel.DefaultTransactionSort, defaultStreamBatchSize, func(batch []domainModel.Transaction) error {
+		transactions = append(transactions, batch...)
+		if ts.allTransactionsLimit > 0 && len(transactions) > ts.allTransactionsLimit {
+			return domain.ErrResultTooLarge
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -218,6 +480,14 @@ func (ts *transactionService) UpdateTransaction(ctx context.Context, request mod
 
 	repository := ts.transactionRepositoryFactory.New(handler)
 
+	var previous *domainModel.Transaction
+	if ts.alerter != nil {
+		previous, err = repository.GetTransactionById(ctx, request.Id)
+		if err != nil {
+			return err
+		}
+	}
+
 	transaction := domainModel.Transaction{
 		Id:          request.Id,
 		UserId:      request.UserId,
@@ -231,6 +501,24 @@ func (ts *transactionService) UpdateTransaction(ctx context.Context, request mod
 		return err
 	}
 
+	updated, err := repository.GetTransactionById(ctx, request.Id)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return domain.ErrTransactionNotFound
+	}
+	event, err := ts.recordEvent(ctx, handler, domainModel.TransactionUpdated, *updated)
+	if err != nil {
+		return err
+	}
+	if err := ts.enqueueNotification(ctx, handler, *event); err != nil {
+		return err
+	}
+	if err := ts.checkAlerts(ctx, handler, *event, previous); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
@@ -248,11 +536,30 @@ func (ts *transactionService) DeleteTransaction(ctx context.Context, request mod
 
 	repository := ts.transactionRepositoryFactory.New(handler)
 
+	deleted, err := repository.GetTransactionById(ctx, request.Id)
+	if err != nil {
+		return err
+	}
+	if deleted == nil {
+		return domain.ErrTransactionNotFound
+	}
+
 	err = repository.DeleteTransaction(ctx, request.Id)
 	if err != nil {
 		return err
 	}
 
+	event, err := ts.recordEvent(ctx, handler, domainModel.TransactionDeleted, *deleted)
+	if err != nil {
+		return err
+	}
+	if err := ts.enqueueNotification(ctx, handler, *event); err != nil {
+		return err
+	}
+	if err := ts.checkAlerts(ctx, handler, *event, nil); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return err
 	}
@@ -274,7 +581,8 @@ func (ts *transactionService) GetTransactionsByUserId(ctx context.Context, reque
 
 	repository := ts.transactionRepositoryFactory.New(handler)
 
-	transactions, err := repository.GetTransactionsByUserId(ctx, request.UserId)
+	filter := domainModel.TransactionFilter{UserIds: []string{request.UserId}}
+	transactions, nextPageToken, err := ts.listPage(ctx, repository, filter, domainModel.DefaultTransactionSort, request.PageSize, request.PageToken)
 	if err != nil {
 		return nil, err
 	}
@@ -283,7 +591,7 @@ func (ts *transactionService) GetTransactionsByUserId(ctx context.Context, reque
 		return nil, err
 	}
 
-	return &model.GetTransactionsByUserIdResponse{Transactions: ToModelTransactions(transactions)}, nil
+	return &model.GetTransactionsByUserIdResponse{Transactions: ToModelTransactions(transactions), NextPageToken: nextPageToken}, nil
 }
 
 func (ts *transactionService) GetTransactionsWithPagination(ctx context.Context, request model.GetTransactionsWithPaginationRequest) (*model.GetTransactionsWithPaginationResponse, error) {
@@ -311,3 +619,236 @@ func (ts *transactionService) GetTransactionsWithPagination(ctx context.Context,
 
 	return &model.GetTransactionsWithPaginationResponse{Transactions: ToModelTransactions(transactions)}, nil
 }
+
+func (ts *transactionService) SearchTransactions(ctx context.Context, request model.SearchTransactionsRequest) (*model.SearchTransactionsResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+	filter := domainModel.TransactionFilter{
+		FromDate:  request.FromDate,
+		ToDate:    request.ToDate,
+		MinAmount: request.MinAmount,
+		MaxAmount: request.MaxAmount,
+	}
+	if err := validateRanges(filter); err != nil {
+		return nil, err
+	}
+
+	terms := domainModel.ParseSearchQuery(request.Query)
+	if len(terms) == 0 {
+		return nil, domain.ErrEmptySearchQuery
+	}
+
+	pageSize := request.PageSize
+	if pageSize == 0 {
+		pageSize = defaultSearchPageSize
+	}
+
+	query := queryFingerprint(request.UserId, terms, filter)
+	after, err := ts.pageTokenCodec.Decode(request.PageToken, query)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := ts.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := ts.transactionRepositoryFactory.New(handler)
+
+	results, err := repository.SearchTransactions(ctx, domainModel.TransactionSearch{
+		UserId: request.UserId,
+		Terms:  terms,
+		Filter: filter,
+		After:  after,
+		Limit:  pageSize + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	response := &model.SearchTransactionsResponse{}
+	if len(results) > pageSize {
+		results = results[:pageSize]
+		response.NextPageToken, err = ts.pageTokenCodec.Encode(domainModel.SearchCursorOf(results[pageSize-1]), query)
+		if err != nil {
+			return nil, err
+		}
+	}
+	response.Results = ToModelSearchResults(results)
+
+	return response, nil
+}
+
+func (ts *transactionService) ListTransactions(ctx context.Context, request model.ListTransactionsRequest) (*model.ListTransactionsResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	filter := ToDomainFilter(request.Filter)
+	if err := validateRanges(filter); err != nil {
+		return nil, err
+	}
+
+	tx := ts.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := ts.transactionRepositoryFactory.New(handler)
+
+	transactions, nextPageToken, err := ts.listPage(ctx, repository, filter, ToDomainSort(request.SortBy, request.SortOrder), request.PageSize, request.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.ListTransactionsResponse{Transactions: ToModelTransactions(transactions), NextPageToken: nextPageToken}
+	if request.IncludeTotalCount {
+		count, err := repository.CountTransactions(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		response.TotalCount = &count
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (ts *transactionService) StreamTransactions(ctx context.Context, request model.StreamTransactionsRequest, send func([]model.Transaction) error) error {
+	if err := request.Validate(ctx); err != nil {
+		return err
+	}
+
+	filter := ToDomainFilter(request.Filter)
+	if err := validateRanges(filter); err != nil {
+		return err
+	}
+
+	batchSize := request.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultStreamBatchSize
+	}
+
+	tx := ts.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := ts.transactionRepositoryFactory.New(handler)
+
+	err = repository.StreamTransactions(ctx, filter, ToDomainSort(request.SortBy, request.SortOrder), batchSize, func(batch []domainModel.Transaction) error {
+		return send(ToModelTransactions(batch))
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (ts *transactionService) SubscribeTransactions(ctx context.Context, request model.SubscribeTransactionsRequest, send func(model.TransactionEvent) error) error {
+	if err := request.Validate(ctx); err != nil {
+		return err
+	}
+	if ts.eventSubscriber == nil || ts.eventRepositoryFactory == nil {
+		return domain.ErrSubscriptionsUnavailable
+	}
+
+	var lastSequence int64
+	if request.ResumeToken != "" {
+		sequence, err := decodeResumeToken(request.ResumeToken)
+		if err != nil {
+			return err
+		}
+		lastSequence = sequence
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Subscribe before replaying so that no event can slip in between, events
+	// seen during the replay are skipped by their sequence.
+	events, err := ts.eventSubscriber.Subscribe(ctx, request.UserId)
+	if err != nil {
+		return err
+	}
+
+	if request.ResumeToken != "" {
+		for {
+			missed, err := ts.getEventsAfter(ctx, request.UserId, lastSequence)
+			if err != nil {
+				return err
+			}
+			for _, event := range missed {
+				if err := send(ToModelTransactionEvent(event)); err != nil {
+					return err
+				}
+				lastSequence = event.Sequence
+			}
+			if len(missed) < replayBatchSize {
+				break
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				select {
+				case <-ts.eventSubscriber.Done():
+					return domain.ErrShuttingDown
+				default:
+					return domain.ErrSubscriberTooSlow
+				}
+			}
+			if event.Sequence <= lastSequence {
+				continue
+			}
+			if err := send(ToModelTransactionEvent(event)); err != nil {
+				return err
+			}
+			lastSequence = event.Sequence
+		}
+	}
+}
+
+func (ts *transactionService) getEventsAfter(ctx context.Context, userId string, sequence int64) ([]domainModel.TransactionEvent, error) {
+	tx := ts.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	events, err := ts.eventRepositoryFactory.New(handler).GetEventsAfter(ctx, userId, sequence, replayBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -6,10 +6,11 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	adapterDriven "github.com/nullexp/finman-transaction-service/internal/adapter/driven"
 	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
 	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driven/event"
 	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	"github.com/nullexp/finman-transaction-service/internal/domain"
 	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
 	"github.com/nullexp/finman-transaction-service/internal/port/model"
 	"github.com/stretchr/testify/assert"
@@ -20,7 +21,7 @@ func TestCreateTransaction(t *testing.T) {
 	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
 	txFactory := &db.PostgresTransactionMockFactory{}
 
-	service := service.NewTransactionService(repoFactory, txFactory, adapterDriven.NewMockNotificationService())
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
 
 	request := model.CreateTransactionRequest{
 		UserId:      uuid.New().String(),
@@ -41,7 +42,7 @@ func TestGetTransactionById(t *testing.T) {
 	repo := repository.NewInMemoryTransactionRepository()
 	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
 	txFactory := &db.PostgresTransactionMockFactory{}
-	service := service.NewTransactionService(repoFactory, txFactory, adapterDriven.NewMockNotificationService())
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
 
 	transaction := domainModel.Transaction{
 		UserId:      uuid.New().String(),
@@ -72,7 +73,7 @@ func TestGetOwnTransactionById(t *testing.T) {
 	repo := repository.NewInMemoryTransactionRepository()
 	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
 	txFactory := &db.PostgresTransactionMockFactory{}
-	service := service.NewTransactionService(repoFactory, txFactory, adapterDriven.NewMockNotificationService())
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
 
 	userId := uuid.New().String()
 	transaction := domainModel.Transaction{
@@ -104,7 +105,7 @@ func TestGetAllTransactions(t *testing.T) {
 	repo := repository.NewInMemoryTransactionRepository()
 	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
 	txFactory := &db.PostgresTransactionMockFactory{}
-	service := service.NewTransactionService(repoFactory, txFactory, adapterDriven.NewMockNotificationService())
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
 
 	transaction1 := domainModel.Transaction{
 		UserId:      uuid.New().String(),
@@ -137,11 +138,36 @@ func TestGetAllTransactions(t *testing.T) {
 	assert.Equal(t, 2, len(response.Transactions))
 }
 
+func TestGetAllTransactionsLimit(t *testing.T) {
+	repo := repository.NewInMemoryTransactionRepository()
+	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
+	txFactory := &db.PostgresTransactionMockFactory{}
+	outboxFactory := repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository())
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := repo.CreateTransaction(ctx, domainModel.Transaction{UserId: uuid.New().String(), Type: "deposit", Amount: 100, Date: time.Now()})
+		assert.NoError(t, err)
+	}
+
+	response, err := service.NewTransactionService(repoFactory, txFactory, outboxFactory, service.WithAllTransactionsLimit(3)).GetAllTransactions(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, response.Transactions, 3)
+
+	_, err = service.NewTransactionService(repoFactory, txFactory, outboxFactory, service.WithAllTransactionsLimit(2)).GetAllTransactions(ctx)
+	assert.ErrorIs(t, err, domain.ErrResultTooLarge)
+
+	// Zero returns all of them
+	response, err = service.NewTransactionService(repoFactory, txFactory, outboxFactory, service.WithAllTransactionsLimit(0)).GetAllTransactions(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, response.Transactions, 3)
+}
+
 func TestUpdateTransaction(t *testing.T) {
 	repo := repository.NewInMemoryTransactionRepository()
 	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
 	txFactory := &db.PostgresTransactionMockFactory{}
-	service := service.NewTransactionService(repoFactory, txFactory, adapterDriven.NewMockNotificationService())
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
 
 	transaction := domainModel.Transaction{
 		UserId:      uuid.New().String(),
@@ -180,7 +206,7 @@ func TestDeleteTransaction(t *testing.T) {
 	repo := repository.NewInMemoryTransactionRepository()
 	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
 	txFactory := &db.PostgresTransactionMockFactory{}
-	service := service.NewTransactionService(repoFactory, txFactory, adapterDriven.NewMockNotificationService())
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
 
 	transaction := domainModel.Transaction{
 		UserId:      uuid.New().String(),
@@ -210,7 +236,7 @@ func TestGetTransactionsByUserId(t *testing.T) {
 	repo := repository.NewInMemoryTransactionRepository()
 	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
 	txFactory := &db.PostgresTransactionMockFactory{}
-	service := service.NewTransactionService(repoFactory, txFactory, adapterDriven.NewMockNotificationService())
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
 
 	userId := uuid.New().String()
 	transaction1 := domainModel.Transaction{
@@ -249,7 +275,7 @@ func TestGetTransactionsWithPagination(t *testing.T) {
 	repo := repository.NewInMemoryTransactionRepository()
 	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
 	txFactory := &db.PostgresTransactionMockFactory{}
-	service := service.NewTransactionService(repoFactory, txFactory, adapterDriven.NewMockNotificationService())
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
 
 	for i := 0; i < 10; i++ {
 		transaction := domainModel.Transaction{
@@ -273,3 +299,360 @@ func TestGetTransactionsWithPagination(t *testing.T) {
 	assert.NotNil(t, response)
 	assert.Equal(t, 5, len(response.Transactions))
 }
+
+func TestSearchTransactions(t *testing.T) {
+	repo := repository.NewInMemoryTransactionRepository()
+	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
+	txFactory := &db.PostgresTransactionMockFactory{}
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
+
+	userId := uuid.New().String()
+	descriptions := map[string]int64{
+		"Netflix payment for April": 15,
+		"Payment to Netflix":        15,
+		"Netflix payment, May":      20,
+		"Grocery store":             80,
+	}
+
+	ctx := context.Background()
+	for description, amount := range descriptions {
+		_, err := repo.CreateTransaction(ctx, domainModel.Transaction{
+			UserId:      userId,
+			Type:        "withdrawal",
+			Amount:      amount,
+			Date:        time.Now(),
+			Description: description,
+		})
+		assert.NoError(t, err)
+	}
+	// Another user's transaction must never show up
+	_, err := repo.CreateTransaction(ctx, domainModel.Transaction{
+		UserId:      uuid.New().String(),
+		Type:        "deposit",
+		Amount:      15,
+		Date:        time.Now(),
+		Description: "Netflix payment refund",
+	})
+	assert.NoError(t, err)
+
+	// Phrase query
+	response, err := service.SearchTransactions(ctx, model.SearchTransactionsRequest{UserId: userId, Query: `"netflix payment"`})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(response.Results))
+
+	// Prefix query combined with an amount filter
+	maxAmount := int64(15)
+	response, err = service.SearchTransactions(ctx, model.SearchTransactionsRequest{UserId: userId, Query: "netf* pay*", MaxAmount: &maxAmount})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(response.Results))
+	for _, result := range response.Results {
+		assert.Equal(t, userId, result.Transaction.UserId)
+		assert.LessOrEqual(t, result.Transaction.Amount, maxAmount)
+	}
+
+	// Paging through ranked results
+	page, err := service.SearchTransactions(ctx, model.SearchTransactionsRequest{UserId: userId, Query: "netflix", PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(page.Results))
+	assert.NotEmpty(t, page.NextPageToken)
+	page, err = service.SearchTransactions(ctx, model.SearchTransactionsRequest{UserId: userId, Query: "netflix", PageSize: 2, PageToken: page.NextPageToken})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(page.Results))
+	assert.Empty(t, page.NextPageToken)
+
+	// Date filter excluding everything
+	future := time.Now().Add(time.Hour)
+	response, err = service.SearchTransactions(ctx, model.SearchTransactionsRequest{UserId: userId, Query: "netflix", FromDate: &future})
+	assert.NoError(t, err)
+	assert.Empty(t, response.Results)
+
+	// Queries without searchable words are rejected
+	_, err = service.SearchTransactions(ctx, model.SearchTransactionsRequest{UserId: userId, Query: `"" *`})
+	assert.Error(t, err)
+}
+
+func TestListTransactions(t *testing.T) {
+	repo := repository.NewInMemoryTransactionRepository()
+	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
+	txFactory := &db.PostgresTransactionMockFactory{}
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
+
+	userId := uuid.New().String()
+	ctx := context.Background()
+	for i := 1; i <= 6; i++ {
+		transactionType := "deposit"
+		if i%2 == 0 {
+			transactionType = "withdrawal"
+		}
+		_, err := repo.CreateTransaction(ctx, domainModel.Transaction{
+			UserId:      userId,
+			Type:        transactionType,
+			Amount:      int64(i * 10),
+			Date:        time.Now().Add(time.Duration(i) * time.Minute),
+			Description: "Rent payment",
+		})
+		assert.NoError(t, err)
+	}
+	_, err := repo.CreateTransaction(ctx, domainModel.Transaction{
+		UserId:      uuid.New().String(),
+		Type:        "deposit",
+		Amount:      10,
+		Date:        time.Now(),
+		Description: "Rent payment",
+	})
+	assert.NoError(t, err)
+
+	minAmount := int64(20)
+	request := model.ListTransactionsRequest{
+		Filter: model.TransactionFilter{
+			UserIds:             []string{userId},
+			Types:               []string{"withdrawal"},
+			MinAmount:           &minAmount,
+			DescriptionContains: "RENT",
+		},
+		SortBy:            "amount",
+		SortOrder:         "asc",
+		PageSize:          2,
+		IncludeTotalCount: true,
+	}
+	response, err := service.ListTransactions(ctx, request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(response.Transactions))
+	assert.Equal(t, int64(20), response.Transactions[0].Amount)
+	assert.Equal(t, int64(40), response.Transactions[1].Amount)
+	assert.NotNil(t, response.TotalCount)
+	assert.Equal(t, int64(3), *response.TotalCount)
+
+	assert.NotEmpty(t, response.NextPageToken)
+
+	// Second and last page
+	request.PageToken = response.NextPageToken
+	response, err = service.ListTransactions(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(response.Transactions))
+	assert.Equal(t, int64(60), response.Transactions[0].Amount)
+	assert.Empty(t, response.NextPageToken)
+
+	// Default order is newest first
+	response, err = service.ListTransactions(ctx, model.ListTransactionsRequest{Filter: model.TransactionFilter{UserIds: []string{userId}}})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(response.Transactions))
+	assert.Nil(t, response.TotalCount)
+	assert.Equal(t, int64(60), response.Transactions[0].Amount)
+
+	// Unknown sort fields are rejected
+	_, err = service.ListTransactions(ctx, model.ListTransactionsRequest{SortBy: "amount; DROP TABLE transactions"})
+	assert.Error(t, err)
+}
+
+func TestGetTransactionsByUserIdPageTokens(t *testing.T) {
+	repo := repository.NewInMemoryTransactionRepository()
+	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
+	txFactory := &db.PostgresTransactionMockFactory{}
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
+
+	userId := uuid.New().String()
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := repo.CreateTransaction(ctx, domainModel.Transaction{
+			UserId:      userId,
+			Type:        "deposit",
+			Amount:      100,
+			Date:        start.Add(time.Duration(i) * time.Minute),
+			Description: "Test transaction",
+		})
+		assert.NoError(t, err)
+	}
+
+	first, err := service.GetTransactionsByUserId(ctx, model.GetTransactionsByUserIdRequest{UserId: userId, PageSize: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(first.Transactions))
+	assert.NotEmpty(t, first.NextPageToken)
+
+	// A transaction arriving between page loads must not shift the next page
+	_, err = repo.CreateTransaction(ctx, domainModel.Transaction{
+		UserId:      userId,
+		Type:        "deposit",
+		Amount:      100,
+		Date:        start.Add(time.Hour),
+		Description: "Newer transaction",
+	})
+	assert.NoError(t, err)
+
+	seen := map[string]bool{}
+	for _, transaction := range first.Transactions {
+		seen[transaction.Id] = true
+	}
+	pageToken := first.NextPageToken
+	for pageToken != "" {
+		page, err := service.GetTransactionsByUserId(ctx, model.GetTransactionsByUserIdRequest{UserId: userId, PageSize: 2, PageToken: pageToken})
+		assert.NoError(t, err)
+		for _, transaction := range page.Transactions {
+			assert.False(t, seen[transaction.Id], "transaction returned twice")
+			assert.NotEqual(t, "Newer transaction", transaction.Description)
+			seen[transaction.Id] = true
+		}
+		pageToken = page.NextPageToken
+	}
+	assert.Equal(t, 5, len(seen))
+
+	// Tokens are bound to the request they were issued for and cannot be forged
+	_, err = service.GetTransactionsByUserId(ctx, model.GetTransactionsByUserIdRequest{UserId: uuid.New().String(), PageToken: first.NextPageToken})
+	assert.Error(t, err)
+	_, err = service.GetTransactionsByUserId(ctx, model.GetTransactionsByUserIdRequest{UserId: userId, PageToken: first.NextPageToken + "x"})
+	assert.Error(t, err)
+}
+
+func TestStreamTransactions(t *testing.T) {
+	repo := repository.NewInMemoryTransactionRepository()
+	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
+	txFactory := &db.PostgresTransactionMockFactory{}
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()))
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		_, err := repo.CreateTransaction(ctx, domainModel.Transaction{
+			UserId:      uuid.New().String(),
+			Type:        "deposit",
+			Amount:      int64(i + 1),
+			Date:        time.Now(),
+			Description: "Test transaction",
+		})
+		assert.NoError(t, err)
+	}
+
+	var batches [][]model.Transaction
+	minAmount := int64(3)
+	err := service.StreamTransactions(ctx, model.StreamTransactionsRequest{
+		Filter:    model.TransactionFilter{MinAmount: &minAmount},
+		SortBy:    "amount",
+		SortOrder: "asc",
+		BatchSize: 3,
+	}, func(batch []model.Transaction) error {
+		batches = append(batches, batch)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(batches))
+	assert.Equal(t, 3, len(batches[0]))
+	assert.Equal(t, 2, len(batches[2]))
+	assert.Equal(t, int64(3), batches[0][0].Amount)
+	assert.Equal(t, int64(10), batches[2][1].Amount)
+
+	// The stream stops as soon as the client goes away
+	cancelCtx, cancel := context.WithCancel(ctx)
+	sent := 0
+	err = service.StreamTransactions(cancelCtx, model.StreamTransactionsRequest{BatchSize: 2}, func(batch []model.Transaction) error {
+		sent++
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, sent)
+}
+
+// signallingHub reports when a subscription has been registered.
+type signallingHub struct {
+	*event.Hub
+	subscribed chan struct{}
+}
+
+func (h signallingHub) Subscribe(ctx context.Context, userId string) (<-chan domainModel.TransactionEvent, error) {
+	events, err := h.Hub.Subscribe(ctx, userId)
+	h.subscribed <- struct{}{}
+	return events, err
+}
+
+func TestSubscribeTransactions(t *testing.T) {
+	repo := repository.NewInMemoryTransactionRepository()
+	repoFactory := repository.NewInMemoryTransactionRepositoryFactory(repo)
+	txFactory := &db.PostgresTransactionMockFactory{}
+	hub := signallingHub{Hub: event.NewHub(), subscribed: make(chan struct{}, 1)}
+	eventRepo := repository.NewInMemoryTransactionEventRepository(hub.Publish)
+	service := service.NewTransactionService(repoFactory, txFactory, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()),
+		service.WithTransactionEvents(repository.NewInMemoryTransactionEventRepositoryFactory(eventRepo), hub))
+
+	userId := uuid.New().String()
+	ctx := context.Background()
+	create := func(amount int64) string {
+		response, err := service.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: userId, Type: "deposit", Amount: amount})
+		assert.NoError(t, err)
+		return response.Id
+	}
+	subscribe := func(ctx context.Context, resumeToken string) (<-chan model.TransactionEvent, <-chan error) {
+		events := make(chan model.TransactionEvent, 10)
+		done := make(chan error, 1)
+		go func() {
+			done <- service.SubscribeTransactions(ctx, model.SubscribeTransactionsRequest{UserId: userId, ResumeToken: resumeToken}, func(event model.TransactionEvent) error {
+				events <- event
+				return nil
+			})
+		}()
+		<-hub.subscribed
+		return events, done
+	}
+
+	// Live events
+	subscriptionCtx, cancel := context.WithCancel(ctx)
+	events, done := subscribe(subscriptionCtx, "")
+	firstId := create(100)
+	first := <-events
+	assert.Equal(t, "created", first.Type)
+	assert.Equal(t, firstId, first.Transaction.Id)
+	assert.NotEmpty(t, first.ResumeToken)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	// Events missed while disconnected are replayed after the resume token, then live ones follow
+	secondId := create(200)
+	err := service.UpdateTransaction(ctx, model.UpdateTransactionRequest{Id: firstId, UserId: userId, Type: "deposit", Amount: 150})
+	assert.NoError(t, err)
+	// Other users' events are never delivered
+	_, err = service.CreateTransaction(ctx, model.CreateTransactionRequest{UserId: uuid.New().String(), Type: "deposit", Amount: 10})
+	assert.NoError(t, err)
+
+	subscriptionCtx, cancel = context.WithCancel(ctx)
+	defer cancel()
+	events, _ = subscribe(subscriptionCtx, first.ResumeToken)
+	replayed := <-events
+	assert.Equal(t, "created", replayed.Type)
+	assert.Equal(t, secondId, replayed.Transaction.Id)
+	replayed = <-events
+	assert.Equal(t, "updated", replayed.Type)
+	assert.Equal(t, int64(150), replayed.Transaction.Amount)
+
+	err = service.DeleteTransaction(ctx, model.DeleteTransactionRequest{Id: secondId})
+	assert.NoError(t, err)
+	live := <-events
+	assert.Equal(t, "deleted", live.Type)
+	assert.Equal(t, secondId, live.Transaction.Id)
+
+	_, err = service.GetTransactionById(ctx, model.GetTransactionByIdRequest{Id: secondId})
+	assert.Error(t, err)
+}
+
+func TestSubscribeTransactionsEndsWhenTheHubCloses(t *testing.T) {
+	hub := signallingHub{Hub: event.NewHub(), subscribed: make(chan struct{}, 1)}
+	eventRepo := repository.NewInMemoryTransactionEventRepository(hub.Publish)
+	service := service.NewTransactionService(repository.NewInMemoryTransactionRepositoryFactory(repository.NewInMemoryTransactionRepository()), &db.PostgresTransactionMockFactory{}, repository.NewInMemoryOutboxRepositoryFactory(repository.NewInMemoryOutboxRepository()),
+		service.WithTransactionEvents(repository.NewInMemoryTransactionEventRepositoryFactory(eventRepo), hub))
+
+	subscribe := func() error {
+		return service.SubscribeTransactions(context.Background(), model.SubscribeTransactionsRequest{UserId: uuid.New().String()}, func(event model.TransactionEvent) error {
+			return nil
+		})
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- subscribe()
+	}()
+	<-hub.subscribed
+
+	// Subscribers resume on another replica
+	hub.Close()
+	assert.ErrorIs(t, <-done, domain.ErrShuttingDown)
+	assert.ErrorIs(t, subscribe(), domain.ErrShuttingDown)
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+const (
+	maxWebhooksPerUser        = 10
+	webhookSecretGracePeriod  = 24 * time.Hour
+	defaultDeliveriesPageSize = 50
+)
+
+func ToModelWebhookSubscription(s domainModel.WebhookSubscription) model.WebhookSubscription {
+	eventTypes := make([]string, len(s.EventTypes))
+	for i, eventType := range s.EventTypes {
+		eventTypes[i] = string(eventType)
+	}
+	return model.WebhookSubscription{
+		Id:         s.Id,
+		UserId:     s.UserId,
+		Url:        s.Url,
+		EventTypes: eventTypes,
+		Paused:     s.Status == domainModel.WebhookPaused,
+		CreatedAt:  s.CreatedAt,
+		UpdatedAt:  s.UpdatedAt,
+	}
+}
+
+func ToModelWebhookDelivery(d domainModel.WebhookDelivery) model.WebhookDelivery {
+	return model.WebhookDelivery{
+		Id:          d.Id,
+		EventId:     d.EventId,
+		EventType:   string(d.EventType),
+		Attempt:     d.Attempt,
+		StatusCode:  d.StatusCode,
+		LatencyMs:   d.Latency.Milliseconds(),
+		Error:       d.Error,
+		Succeeded:   d.Succeeded(),
+		AttemptedAt: d.AttemptedAt,
+	}
+}
+
+type webhookService struct {
+	webhookRepositoryFactory repository.WebhookRepositoryFactory
+	dbTransactionFactory     db.DbTransactionFactory
+	pageTokenCodec           *PageTokenCodec
+}
+
+func NewWebhookService(wrf repository.WebhookRepositoryFactory, dtf db.DbTransactionFactory, codec *PageTokenCodec) *webhookService {
+	return &webhookService{webhookRepositoryFactory: wrf, dbTransactionFactory: dtf, pageTokenCodec: codec}
+}
+
+// newWebhookSecret returns a random secret for signing payloads.
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func (ws *webhookService) CreateWebhookSubscription(ctx context.Context, request model.CreateWebhookSubscriptionRequest) (*model.CreateWebhookSubscriptionResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	tx := ws.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := ws.webhookRepositoryFactory.New(handler)
+
+	// Concurrent requests must not both pass the limit
+	if err := repository.LockSubscriptions(ctx, request.UserId); err != nil {
+		return nil, err
+	}
+	existing, err := repository.GetSubscriptionsByUserId(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		return nil, domain.ErrTooManyWebhooks
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	subscription := domainModel.WebhookSubscription{
+		UserId: request.UserId,
+		Url:    request.Url,
+		Secret: secret,
+		Status: domainModel.WebhookActive,
+	}
+	seen := make(map[string]bool)
+	for _, eventType := range request.EventTypes {
+		if !seen[eventType] {
+			seen[eventType] = true
+			subscription.EventTypes = append(subscription.EventTypes, domainModel.TransactionEventType(eventType))
+		}
+	}
+
+	id, err := repository.CreateSubscription(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	created, err := repository.GetSubscriptionById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &model.CreateWebhookSubscriptionResponse{Subscription: ToModelWebhookSubscription(*created), Secret: secret}, nil
+}
+
+func (ws *webhookService) ListWebhookSubscriptions(ctx context.Context, request model.ListWebhookSubscriptionsRequest) (*model.ListWebhookSubscriptionsResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	tx := ws.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := ws.webhookRepositoryFactory.New(handler)
+
+	subscriptions, err := repository.GetSubscriptionsByUserId(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	response := &model.ListWebhookSubscriptionsResponse{}
+	for _, subscription := range subscriptions {
+		response.Subscriptions = append(response.Subscriptions, ToModelWebhookSubscription(subscription))
+	}
+	return response, nil
+}
+
+func (ws *webhookService) RotateWebhookSecret(ctx context.Context, request model.WebhookSubscriptionRequest) (*model.RotateWebhookSecretResponse, error) {
+	var secret string
+	var expiresAt time.Time
+	subscription, err := ws.updateSubscription(ctx, request, func(subscription *domainModel.WebhookSubscription) error {
+		var err error
+		secret, err = newWebhookSecret()
+		if err != nil {
+			return err
+		}
+		expiresAt = time.Now().Add(webhookSecretGracePeriod)
+		subscription.PreviousSecret = subscription.Secret
+		subscription.PreviousSecretExpiresAt = &expiresAt
+		subscription.Secret = secret
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RotateWebhookSecretResponse{Subscription: *subscription, Secret: secret, PreviousSecretExpiresAt: expiresAt}, nil
+}
+
+func (ws *webhookService) PauseWebhookSubscription(ctx context.Context, request model.WebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	return ws.updateSubscription(ctx, request, func(subscription *domainModel.WebhookSubscription) error {
+		subscription.Status = domainModel.WebhookPaused
+		return nil
+	})
+}
+
+func (ws *webhookService) ResumeWebhookSubscription(ctx context.Context, request model.WebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	return ws.updateSubscription(ctx, request, func(subscription *domainModel.WebhookSubscription) error {
+		subscription.Status = domainModel.WebhookActive
+		return nil
+	})
+}
+
+func (ws *webhookService) DeleteWebhookSubscription(ctx context.Context, request model.WebhookSubscriptionRequest) error {
+	if err := request.Validate(ctx); err != nil {
+		return err
+	}
+
+	tx := ws.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := ws.webhookRepositoryFactory.New(handler)
+
+	if _, err := ws.getOwnSubscription(ctx, repository, request); err != nil {
+		return err
+	}
+	if err := repository.DeleteSubscription(ctx, request.Id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (ws *webhookService) ListWebhookDeliveries(ctx context.Context, request model.ListWebhookDeliveriesRequest) (*model.ListWebhookDeliveriesResponse, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	query := queryFingerprint(request.UserId, request.SubscriptionId)
+	beforeId, err := ws.pageTokenCodec.DecodeId(request.PageToken, query)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := request.PageSize
+	if pageSize == 0 {
+		pageSize = defaultDeliveriesPageSize
+	}
+
+	tx := ws.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := ws.webhookRepositoryFactory.New(handler)
+
+	if _, err := ws.getOwnSubscription(ctx, repository, model.WebhookSubscriptionRequest{Id: request.SubscriptionId, UserId: request.UserId}); err != nil {
+		return nil, err
+	}
+	deliveries, err := repository.GetDeliveries(ctx, request.SubscriptionId, beforeId, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	response := &model.ListWebhookDeliveriesResponse{}
+	if len(deliveries) > pageSize {
+		deliveries = deliveries[:pageSize]
+		response.NextPageToken, err = ws.pageTokenCodec.EncodeId(deliveries[pageSize-1].Id, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, ToModelWebhookDelivery(delivery))
+	}
+	return response, nil
+}
+
+// updateSubscription applies change to a subscription of the requesting user and stores it.
+func (ws *webhookService) updateSubscription(ctx context.Context, request model.WebhookSubscriptionRequest, change func(*domainModel.WebhookSubscription) error) (*model.WebhookSubscription, error) {
+	if err := request.Validate(ctx); err != nil {
+		return nil, err
+	}
+
+	tx := ws.dbTransactionFactory.NewTransaction()
+	handler, err := tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.RollbackUnlessCommitted(ctx)
+
+	repository := ws.webhookRepositoryFactory.New(handler)
+
+	subscription, err := ws.getOwnSubscription(ctx, repository, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(subscription); err != nil {
+		return nil, err
+	}
+	if err := repository.UpdateSubscription(ctx, *subscription); err != nil {
+		return nil, err
+	}
+	updated, err := repository.GetSubscriptionById(ctx, subscription.Id)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	result := ToModelWebhookSubscription(*updated)
+	return &result, nil
+}
+
+// getOwnSubscription hides subscriptions of other users as if they did not exist.
+func (ws *webhookService) getOwnSubscription(ctx context.Context, repository repository.WebhookRepository, request model.WebhookSubscriptionRequest) (*domainModel.WebhookSubscription, error) {
+	subscription, err := repository.GetSubscriptionById(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil || subscription.UserId != request.UserId {
+		return nil, domain.ErrWebhookNotFound
+	}
+	return subscription, nil
+}
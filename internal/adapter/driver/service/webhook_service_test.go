@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	db "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db"
+	repository "github.com/nullexp/finman-transaction-service/internal/adapter/driven/db/repository"
+	"github.com/nullexp/finman-transaction-service/internal/adapter/driver/service"
+	"github.com/nullexp/finman-transaction-service/internal/domain"
+	domainModel "github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSubscriptions(t *testing.T) {
+	repo := repository.NewInMemoryWebhookRepository()
+	webhookService := service.NewWebhookService(repository.NewInMemoryWebhookRepositoryFactory(repo), &db.PostgresTransactionMockFactory{}, service.NewRandomPageTokenCodec())
+	ctx := context.Background()
+	userId := uuid.New().String()
+
+	// Validation
+	_, err := webhookService.CreateWebhookSubscription(ctx, model.CreateWebhookSubscriptionRequest{UserId: userId, Url: "ftp://example.com", EventTypes: []string{"created"}})
+	assert.Error(t, err)
+	_, err = webhookService.CreateWebhookSubscription(ctx, model.CreateWebhookSubscriptionRequest{UserId: userId, Url: "http://example.com/hook", EventTypes: []string{"created"}})
+	assert.Error(t, err)
+	_, err = webhookService.CreateWebhookSubscription(ctx, model.CreateWebhookSubscriptionRequest{UserId: userId, Url: "https://example.com/hook", EventTypes: []string{"archived"}})
+	assert.Error(t, err)
+	_, err = webhookService.CreateWebhookSubscription(ctx, model.CreateWebhookSubscriptionRequest{UserId: userId, Url: "https://example.com/hook"})
+	assert.Error(t, err)
+
+	created, err := webhookService.CreateWebhookSubscription(ctx, model.CreateWebhookSubscriptionRequest{
+		UserId:     userId,
+		Url:        "https://example.com/hook",
+		EventTypes: []string{"created", "insufficient_balance", "created"},
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, created.Secret)
+	assert.Equal(t, []string{"created", "insufficient_balance"}, created.Subscription.EventTypes)
+	assert.False(t, created.Subscription.Paused)
+
+	list, err := webhookService.ListWebhookSubscriptions(ctx, model.ListWebhookSubscriptionsRequest{UserId: userId})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(list.Subscriptions))
+	assert.Equal(t, created.Subscription.Id, list.Subscriptions[0].Id)
+
+	request := model.WebhookSubscriptionRequest{Id: created.Subscription.Id, UserId: userId}
+
+	// Rotation keeps the previous secret for a grace period
+	rotated, err := webhookService.RotateWebhookSecret(ctx, request)
+	assert.NoError(t, err)
+	assert.NotEqual(t, created.Secret, rotated.Secret)
+	assert.True(t, rotated.PreviousSecretExpiresAt.After(time.Now()))
+	stored, _ := repo.GetSubscriptionById(ctx, request.Id)
+	assert.Equal(t, []string{rotated.Secret, created.Secret}, stored.Secrets(time.Now()))
+	assert.Equal(t, []string{rotated.Secret}, stored.Secrets(rotated.PreviousSecretExpiresAt))
+
+	// Paused subscriptions receive nothing
+	paused, err := webhookService.PauseWebhookSubscription(ctx, request)
+	assert.NoError(t, err)
+	assert.True(t, paused.Paused)
+	active, _ := repo.GetActiveSubscriptions(ctx, userId, domainModel.TransactionCreated)
+	assert.Equal(t, 0, len(active))
+
+	resumed, err := webhookService.ResumeWebhookSubscription(ctx, request)
+	assert.NoError(t, err)
+	assert.False(t, resumed.Paused)
+	active, _ = repo.GetActiveSubscriptions(ctx, userId, domainModel.TransactionCreated)
+	assert.Equal(t, 1, len(active))
+	active, _ = repo.GetActiveSubscriptions(ctx, userId, domainModel.TransactionDeleted)
+	assert.Equal(t, 0, len(active))
+
+	// Subscriptions of other users are not found
+	other := model.WebhookSubscriptionRequest{Id: created.Subscription.Id, UserId: uuid.New().String()}
+	_, err = webhookService.PauseWebhookSubscription(ctx, other)
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+	err = webhookService.DeleteWebhookSubscription(ctx, other)
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+	_, err = webhookService.ListWebhookDeliveries(ctx, model.ListWebhookDeliveriesRequest{SubscriptionId: other.Id, UserId: other.UserId})
+	assert.ErrorIs(t, err, domain.ErrWebhookNotFound)
+
+	err = webhookService.DeleteWebhookSubscription(ctx, request)
+	assert.NoError(t, err)
+	list, err = webhookService.ListWebhookSubscriptions(ctx, model.ListWebhookSubscriptionsRequest{UserId: userId})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(list.Subscriptions))
+}
+
+func TestWebhookSubscriptionLimit(t *testing.T) {
+	webhookService := service.NewWebhookService(repository.NewInMemoryWebhookRepositoryFactory(repository.NewInMemoryWebhookRepository()), &db.PostgresTransactionMockFactory{}, service.NewRandomPageTokenCodec())
+	ctx := context.Background()
+	request := model.CreateWebhookSubscriptionRequest{UserId: uuid.New().String(), Url: "https://example.com/hook", EventTypes: []string{"created"}}
+
+	for i := 0; i < 10; i++ {
+		_, err := webhookService.CreateWebhookSubscription(ctx, request)
+		assert.NoError(t, err)
+	}
+	_, err := webhookService.CreateWebhookSubscription(ctx, request)
+	assert.ErrorIs(t, err, domain.ErrTooManyWebhooks)
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	repo := repository.NewInMemoryWebhookRepository()
+	webhookService := service.NewWebhookService(repository.NewInMemoryWebhookRepositoryFactory(repo), &db.PostgresTransactionMockFactory{}, service.NewRandomPageTokenCodec())
+	ctx := context.Background()
+	userId := uuid.New().String()
+
+	created, err := webhookService.CreateWebhookSubscription(ctx, model.CreateWebhookSubscriptionRequest{UserId: userId, Url: "https://example.com/hook", EventTypes: []string{"created"}})
+	assert.NoError(t, err)
+
+	eventId := uuid.New().String()
+	for attempt := 1; attempt <= 5; attempt++ {
+		delivery := domainModel.WebhookDelivery{
+			SubscriptionId: created.Subscription.Id,
+			EventId:        eventId,
+			EventType:      domainModel.TransactionCreated,
+			Attempt:        attempt,
+			StatusCode:     503,
+			Latency:        time.Duration(attempt) * time.Millisecond,
+			Error:          "webhook responded with status 503",
+			AttemptedAt:    time.Now(),
+		}
+		if attempt == 5 {
+			delivery.StatusCode = 200
+			delivery.Error = ""
+		}
+		assert.NoError(t, repo.RecordDelivery(ctx, delivery))
+	}
+
+	request := model.ListWebhookDeliveriesRequest{SubscriptionId: created.Subscription.Id, UserId: userId, PageSize: 3}
+	page, err := webhookService.ListWebhookDeliveries(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(page.Deliveries))
+	assert.NotEmpty(t, page.NextPageToken)
+	assert.Equal(t, 5, page.Deliveries[0].Attempt)
+	assert.True(t, page.Deliveries[0].Succeeded)
+	assert.Equal(t, 200, page.Deliveries[0].StatusCode)
+	assert.Equal(t, int64(5), page.Deliveries[0].LatencyMs)
+	assert.False(t, page.Deliveries[1].Succeeded)
+
+	firstPageToken := page.NextPageToken
+	request.PageToken = page.NextPageToken
+	page, err = webhookService.ListWebhookDeliveries(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(page.Deliveries))
+	assert.Empty(t, page.NextPageToken)
+	assert.Equal(t, 1, page.Deliveries[1].Attempt)
+
+	request.PageToken = "not a token"
+	_, err = webhookService.ListWebhookDeliveries(ctx, request)
+	assert.ErrorIs(t, err, domain.ErrInvalidPageToken)
+
+	// Tokens are only valid for the subscription they were issued for
+	other, err := webhookService.CreateWebhookSubscription(ctx, model.CreateWebhookSubscriptionRequest{UserId: userId, Url: "https://example.com/other", EventTypes: []string{"created"}})
+	assert.NoError(t, err)
+	request.PageToken = firstPageToken
+	request.SubscriptionId = other.Subscription.Id
+	_, err = webhookService.ListWebhookDeliveries(ctx, request)
+	assert.ErrorIs(t, err, domain.ErrInvalidPageToken)
+}
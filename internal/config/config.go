@@ -0,0 +1,208 @@
+package config
+
+import (
+	"time"
+)
+
+// Config is the configuration of the service. Every field is read from the
+// YAML key of its path, the environment variable of its env tag and the flag
+// named after its path, e.g. database.max_open_conns, DB_MAX_OPEN_CONNS and
+// -database.max_open_conns. Fields tagged secret are redacted when printed.
+type Config struct {
+	Server        Server        `yaml:"server"`
+	Database      Database      `yaml:"database"`
+	Log           Log           `yaml:"log"`
+	Tracing       Tracing       `yaml:"tracing"`
+	TLS           TLS           `yaml:"tls"`
+	Auth          Auth          `yaml:"auth"`
+	RateLimit     RateLimit     `yaml:"rate_limit"`
+	Notifications Notifications `yaml:"notifications"`
+	NATS          NATS          `yaml:"nats"`
+	Health        Health        `yaml:"health"`
+	Features      Features      `yaml:"features"`
+}
+
+type Server struct {
+	IP   string `yaml:"ip" env:"IP"`
+	Port int    `yaml:"port" env:"PORT"`
+	// HTTPAddr serves metrics and probes
+	HTTPAddr string `yaml:"http_addr" env:"HTTP_ADDR"`
+	// ShutdownTimeout bounds draining calls and workers on SIGTERM
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// DrainDelay is how long the service keeps serving after reporting
+	// NOT_SERVING, so probes and load balancers move traffic elsewhere. It
+	// is part of ShutdownTimeout.
+	DrainDelay time.Duration `yaml:"drain_delay" env:"DRAIN_DELAY"`
+	// PageTokenSecret signs page tokens, a random key is used when empty
+	PageTokenSecret string `yaml:"page_token_secret" env:"PAGE_TOKEN_SECRET" secret:"true"`
+	// AllTransactionsLimit fails GetAllTransactions above that many
+	// transactions, 1000 by default, zero returns all of them
+	AllTransactionsLimit int `yaml:"all_transactions_limit" env:"ALL_TRANSACTIONS_LIMIT"`
+}
+
+type Database struct {
+	Host           string        `yaml:"host" env:"DB_HOST"`
+	Port           int           `yaml:"port" env:"DB_PORT"`
+	User           string        `yaml:"user" env:"DB_USER"`
+	Password       string        `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name           string        `yaml:"name" env:"DB_NAME"`
+	SSLMode        string        `yaml:"ssl_mode" env:"DB_SSL_MODE"`
+	MigrationsDir  string        `yaml:"migrations_dir" env:"DB_MIGRATIONS_DIR"`
+	ConnectTimeout time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT"`
+	MaxOpenConns   int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns   int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	// ConnMaxLifetime and ConnMaxIdleTime of zero keep connections forever
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+}
+
+type Log struct {
+	// Level is debug, info, warn or error
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// Format is json or text
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+type Tracing struct {
+	// Exporter is otlp, stdout or none. The OTLP exporter is configured with
+	// the standard OTEL_EXPORTER_OTLP_* variables.
+	Exporter string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+}
+
+type TLS struct {
+	// Mode is tls, mtls or none and has no default, so the server never falls
+	// back to plaintext by accident
+	Mode         string `yaml:"mode" env:"TLS_MODE"`
+	CertFile     string `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile      string `yaml:"key_file" env:"TLS_KEY_FILE"`
+	ClientCAFile string `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+}
+
+type Auth struct {
+	// Disabled is only meant for local development
+	Disabled       bool          `yaml:"disabled" env:"AUTH_DISABLED"`
+	JWKSFile       string        `yaml:"jwks_file" env:"JWT_JWKS_FILE"`
+	PublicKeyFiles []string      `yaml:"public_key_files" env:"JWT_PUBLIC_KEY_FILES"`
+	Issuer         string        `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience       string        `yaml:"audience" env:"JWT_AUDIENCE"`
+	Leeway         time.Duration `yaml:"leeway" env:"JWT_LEEWAY"`
+	// CertificateRoles are the roles of services calling with a client
+	// certificate and no token, by certificate identity
+	CertificateRoles map[string][]string `yaml:"certificate_roles" env:"TLS_CLIENT_ROLES"`
+}
+
+type RateLimit struct {
+	// Limits are keyed by full method name, "*" applies to all other methods.
+	// No method is limited when empty.
+	Limits map[string]Limit `yaml:"limits" env:"RATE_LIMITS"`
+	// Store is memory, limiting each replica on its own, or postgres, sharing
+	// the limits between replicas
+	Store string `yaml:"store" env:"RATE_LIMIT_STORE"`
+	// PeerRate and PeerBurst limit the calls of every peer address before they
+	// are authenticated, in memory. Not limited when zero.
+	PeerRate  float64 `yaml:"peer_rate" env:"RATE_LIMIT_PEER_RATE"`
+	PeerBurst int     `yaml:"peer_burst" env:"RATE_LIMIT_PEER_BURST"`
+}
+
+type Limit struct {
+	Rate  float64 `yaml:"rate" json:"rate"`
+	Burst int     `yaml:"burst" json:"burst"`
+}
+
+type Notifications struct {
+	// Channels are log, webhook and email
+	Channels []string `yaml:"channels" env:"NOTIFICATION_CHANNELS"`
+	// TemplatesDir holds <locale>/<event>.tmpl files replacing the built-in templates
+	TemplatesDir string `yaml:"templates_dir" env:"NOTIFICATION_TEMPLATES_DIR"`
+	// WebhookEndpoints are keyed by user id, "*" receives the notifications of every user
+	WebhookEndpoints map[string][]WebhookEndpoint `yaml:"webhook_endpoints" env:"WEBHOOK_ENDPOINTS" secret:"true"`
+	// WebhookSecretKey encrypts the secrets of webhook subscriptions in the
+	// database, 32 random bytes encoded in base64
+	WebhookSecretKey string `yaml:"webhook_secret_key" env:"WEBHOOK_SECRET_KEY" secret:"true"`
+	SMTP             SMTP   `yaml:"smtp"`
+	// UserEmails are the addresses of the email channel by user id
+	UserEmails map[string]string `yaml:"user_emails" env:"USER_EMAILS" secret:"true"`
+}
+
+type WebhookEndpoint struct {
+	Id     string `yaml:"id,omitempty" json:"id,omitempty"`
+	URL    string `yaml:"url" json:"url"`
+	Secret string `yaml:"secret" json:"secret"`
+}
+
+type SMTP struct {
+	Host     string `yaml:"host" env:"SMTP_HOST"`
+	Port     int    `yaml:"port" env:"SMTP_PORT"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD" secret:"true"`
+	From     string `yaml:"from" env:"SMTP_FROM"`
+	// TLS is starttls or none
+	TLS string `yaml:"tls" env:"SMTP_TLS"`
+}
+
+type NATS struct {
+	// URL enables event publishing
+	URL            string        `yaml:"url" env:"NATS_URL"`
+	SubjectPrefix  string        `yaml:"subject_prefix" env:"NATS_SUBJECT_PREFIX"`
+	PublishTimeout time.Duration `yaml:"publish_timeout" env:"NATS_PUBLISH_TIMEOUT"`
+}
+
+type Health struct {
+	Interval     time.Duration `yaml:"interval" env:"HEALTH_CHECK_INTERVAL"`
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+}
+
+// Features switch optional parts of the service off.
+type Features struct {
+	Reflection    bool `yaml:"reflection" env:"FEATURE_REFLECTION"`
+	Subscriptions bool `yaml:"subscriptions" env:"FEATURE_SUBSCRIPTIONS"`
+	Digests       bool `yaml:"digests" env:"FEATURE_DIGESTS"`
+	Alerts        bool `yaml:"alerts" env:"FEATURE_ALERTS"`
+}
+
+// Default returns the configuration used for everything not configured.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:                 8082,
+			HTTPAddr:             ":9090",
+			ShutdownTimeout:      30 * time.Second,
+			DrainDelay:           5 * time.Second,
+			AllTransactionsLimit: 1000,
+		},
+		Database: Database{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "finman-transaction",
+			SSLMode:         "disable",
+			MigrationsDir:   "internal/adapter/driven/db/migration",
+			ConnectTimeout:  5 * time.Second,
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		Log:       Log{Level: "info", Format: "json"},
+		Tracing:   Tracing{Exporter: "none"},
+		RateLimit: RateLimit{Store: "memory"},
+		Notifications: Notifications{
+			Channels: []string{"webhook"},
+			SMTP:     SMTP{Port: 587, TLS: "starttls"},
+		},
+		NATS: NATS{
+			SubjectPrefix:  "finman.transactions.v1",
+			PublishTimeout: 5 * time.Second,
+		},
+		Health: Health{
+			Interval:     10 * time.Second,
+			CheckTimeout: 5 * time.Second,
+		},
+		Features: Features{
+			Reflection:    true,
+			Subscriptions: true,
+			Digests:       true,
+			Alerts:        true,
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DSN returns the lib/pq connection string of the database.
+func (d Database) DSN() string {
+	parameters := []string{
+		"host=" + quote(d.Host),
+		"port=" + strconv.Itoa(d.Port),
+		"user=" + quote(d.User),
+		"password=" + quote(d.Password),
+		"dbname=" + quote(d.Name),
+		"sslmode=" + quote(d.SSLMode),
+	}
+	if d.ConnectTimeout > 0 {
+		parameters = append(parameters, "connect_timeout="+strconv.Itoa(d.connectTimeoutSeconds()))
+	}
+	return strings.Join(parameters, " ")
+}
+
+// MigrationURL returns the URL golang-migrate connects to the database with.
+func (d Database) MigrationURL() string {
+	query := url.Values{"sslmode": {d.SSLMode}}
+	if d.ConnectTimeout > 0 {
+		query.Set("connect_timeout", strconv.Itoa(d.connectTimeoutSeconds()))
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+		Path:     "/" + d.Name,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// connectTimeoutSeconds rounds up, as Postgres only takes whole seconds.
+func (d Database) connectTimeoutSeconds() int {
+	seconds := int(d.ConnectTimeout.Seconds())
+	if d.ConnectTimeout > 0 && float64(seconds) < d.ConnectTimeout.Seconds() {
+		seconds++
+	}
+	return seconds
+}
+
+// quote escapes a connection string value.
+func quote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
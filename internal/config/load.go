@@ -0,0 +1,179 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the YAML file to read when the -config flag is not given.
+const FileEnv = "CONFIG_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// field is a configurable value of a Config.
+type field struct {
+	path   string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+// fields lists the configurable values of c in declaration order.
+func fields(c *Config) []field {
+	var result []field
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		for i := 0; i < v.NumField(); i++ {
+			structField := v.Type().Field(i)
+			name := strings.Split(structField.Tag.Get("yaml"), ",")[0]
+			path := prefix + name
+			env, ok := structField.Tag.Lookup("env")
+			if !ok {
+				walk(path+".", v.Field(i))
+				continue
+			}
+			result = append(result, field{path: path, env: env, secret: structField.Tag.Get("secret") == "true", value: v.Field(i)})
+		}
+	}
+	walk("", reflect.ValueOf(c).Elem())
+	return result
+}
+
+// Load reads the configuration from, in increasing precedence, the defaults,
+// the YAML file of the -config flag or CONFIG_FILE, the environment as seen
+// through lookupEnv, where empty variables count as unset, and the flags in
+// args. Maps and lists of objects are given as JSON in the environment and
+// flags, other lists separated by commas. The result still has to be validated.
+// With -h the flags are printed to stderr and flag.ErrHelp is returned.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	c := Default()
+	all := fields(&c)
+
+	// Flags are parsed first for the file name but applied last
+	flags := flag.NewFlagSet("finman-transaction-service", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	file := flags.String("config", "", "YAML configuration file, "+FileEnv+" by default")
+	var setFlags []func() error
+	for _, f := range all {
+		f := f
+		record := func(value string) error {
+			setFlags = append(setFlags, func() error {
+				if err := set(f.value, value); err != nil {
+					return fmt.Errorf("invalid -%s: %w", f.path, err)
+				}
+				return nil
+			})
+			return nil
+		}
+		usage := "overrides " + f.env
+		if f.value.Kind() == reflect.Bool {
+			flags.BoolFunc(f.path, usage, record)
+		} else {
+			flags.Func(f.path, usage, record)
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			flags.SetOutput(os.Stderr)
+			flags.PrintDefaults()
+		}
+		return c, err
+	}
+	if flags.NArg() > 0 {
+		return c, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	if *file == "" {
+		*file, _ = lookupEnv(FileEnv)
+	}
+	if *file != "" {
+		if err := loadFile(&c, *file); err != nil {
+			return c, err
+		}
+	}
+
+	for _, f := range all {
+		value, _ := lookupEnv(f.env)
+		if value == "" {
+			continue
+		}
+		if err := set(f.value, value); err != nil {
+			return c, fmt.Errorf("invalid %s: %w", f.env, err)
+		}
+	}
+
+	for _, setFlag := range setFlags {
+		if err := setFlag(); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+func loadFile(c *Config, name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("failed to read the configuration file: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// set parses value into v according to its type.
+func set(v reflect.Value, value string) error {
+	if v.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(duration))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		v.SetInt(int64(number))
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		v.SetBool(boolean)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		parsed := reflect.New(v.Type())
+		if err := json.Unmarshal([]byte(value), parsed.Interface()); err != nil {
+			return fmt.Errorf("not valid JSON: %w", err)
+		}
+		v.Set(parsed.Elem())
+	}
+	return nil
+}
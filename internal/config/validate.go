@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+)
+
+// Validate reports every invalid setting at once, by its path.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, path string, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+		}
+	}
+	oneOf := func(value string, path string, allowed ...string) {
+		check(slices.Contains(allowed, value), path, "must be one of %v, got %q", allowed, value)
+	}
+	port := func(port int, path string) {
+		check(port > 0 && port <= 65535, path, "must be a port between 1 and 65535, got %d", port)
+	}
+
+	port(c.Server.Port, "server.port")
+	check(c.Server.HTTPAddr != "", "server.http_addr", "is required")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	check(c.Server.DrainDelay >= 0 && c.Server.DrainDelay < c.Server.ShutdownTimeout, "server.drain_delay",
+		"must be between 0 and server.shutdown_timeout (%s), got %s", c.Server.ShutdownTimeout, c.Server.DrainDelay)
+	check(c.Server.AllTransactionsLimit >= 0, "server.all_transactions_limit", "must not be negative")
+
+	check(c.Database.Host != "", "database.host", "is required")
+	port(c.Database.Port, "database.port")
+	check(c.Database.User != "", "database.user", "is required")
+	check(c.Database.Name != "", "database.name", "is required")
+	oneOf(c.Database.SSLMode, "database.ssl_mode", "disable", "require", "verify-ca", "verify-full")
+	check(c.Database.MigrationsDir != "", "database.migrations_dir", "is required")
+	check(c.Database.ConnectTimeout >= 0, "database.connect_timeout", "must not be negative")
+	check(c.Database.MaxOpenConns > 0, "database.max_open_conns", "must be positive")
+	check(c.Database.MaxIdleConns >= 0 && c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "database.max_idle_conns",
+		"must be between 0 and database.max_open_conns (%d), got %d", c.Database.MaxOpenConns, c.Database.MaxIdleConns)
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime", "must not be negative")
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time", "must not be negative")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log.level", "must be debug, info, warn or error, got %q", c.Log.Level)
+	oneOf(c.Log.Format, "log.format", "json", "text")
+
+	oneOf(c.Tracing.Exporter, "tracing.exporter", "otlp", "stdout", "console", "none")
+
+	oneOf(c.TLS.Mode, "tls.mode", "tls", "mtls", "none")
+	if c.TLS.Mode == "tls" || c.TLS.Mode == "mtls" {
+		check(c.TLS.CertFile != "", "tls.cert_file", "is required with tls.mode %s", c.TLS.Mode)
+		check(c.TLS.KeyFile != "", "tls.key_file", "is required with tls.mode %s", c.TLS.Mode)
+	}
+	if c.TLS.Mode == "mtls" {
+		check(c.TLS.ClientCAFile != "", "tls.client_ca_file", "is required with tls.mode mtls")
+	}
+
+	if !c.Auth.Disabled {
+		check(c.Auth.JWKSFile != "" || len(c.Auth.PublicKeyFiles) > 0, "auth", "set jwks_file or public_key_files, or disabled")
+		check(c.Auth.Issuer != "", "auth.issuer", "is required unless auth is disabled")
+		check(c.Auth.Audience != "", "auth.audience", "is required unless auth is disabled")
+	}
+	check(c.Auth.Leeway >= 0, "auth.leeway", "must not be negative")
+
+	for method, limit := range c.RateLimit.Limits {
+		check(limit.Rate > 0 && limit.Burst >= 1, "rate_limit.limits", "%s needs a positive rate and burst", method)
+	}
+	oneOf(c.RateLimit.Store, "rate_limit.store", "memory", "postgres")
+	check(c.RateLimit.PeerRate >= 0 && c.RateLimit.PeerBurst >= 0, "rate_limit", "peer_rate and peer_burst must not be negative")
+	check((c.RateLimit.PeerRate > 0) == (c.RateLimit.PeerBurst > 0), "rate_limit", "set both peer_rate and peer_burst, or neither")
+
+	check(len(c.Notifications.Channels) > 0, "notifications.channels", "needs at least one channel")
+	for _, channel := range c.Notifications.Channels {
+		oneOf(channel, "notifications.channels", "log", "webhook", "email")
+	}
+	for userId, endpoints := range c.Notifications.WebhookEndpoints {
+		for _, endpoint := range endpoints {
+			parsed, err := url.Parse(endpoint.URL)
+			check(err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "",
+				"notifications.webhook_endpoints", "endpoint of %s needs an http or https URL", userId)
+			check(endpoint.Secret != "", "notifications.webhook_endpoints", "endpoint of %s needs a secret", userId)
+		}
+	}
+	if c.Notifications.WebhookSecretKey == "" {
+		check(false, "notifications.webhook_secret_key", "is required to encrypt webhook secrets")
+	} else {
+		key, err := base64.StdEncoding.DecodeString(c.Notifications.WebhookSecretKey)
+		check(err == nil && len(key) == 32, "notifications.webhook_secret_key", "must be 32 random bytes encoded in base64, e.g. from openssl rand -base64 32")
+	}
+	if slices.Contains(c.Notifications.Channels, "email") {
+		check(c.Notifications.SMTP.Host != "", "notifications.smtp.host", "is required by the email channel")
+		check(c.Notifications.SMTP.From != "", "notifications.smtp.from", "is required by the email channel")
+	}
+	port(c.Notifications.SMTP.Port, "notifications.smtp.port")
+	oneOf(c.Notifications.SMTP.TLS, "notifications.smtp.tls", "starttls", "none")
+
+	if c.NATS.URL != "" {
+		check(c.NATS.SubjectPrefix != "", "nats.subject_prefix", "is required to publish events")
+	}
+	check(c.NATS.PublishTimeout > 0, "nats.publish_timeout", "must be positive")
+
+	check(c.Health.Interval > 0, "health.interval", "must be positive")
+	check(c.Health.CheckTimeout > 0, "health.check_timeout", "must be positive")
+
+	return errors.Join(errs...)
+}
@@ -1,8 +1,55 @@
 package domain
 
-import "errors"
+// ErrorKind classifies domain errors by how a caller can react to them.
+type ErrorKind int
+
+const (
+	// KindInvalidArgument errors are fixed by changing the request
+	KindInvalidArgument ErrorKind = iota + 1
+	KindNotFound
+	// KindFailedPrecondition errors depend on the state of the system, e.g. the balance
+	KindFailedPrecondition
+	KindResourceExhausted
+	// KindAborted errors are solved by retrying at a higher level, e.g. resuming a stream
+	KindAborted
+	KindUnimplemented
+	KindUnauthenticated
+	// KindUnavailable errors are solved by retrying, possibly on another replica
+	KindUnavailable
+)
+
+// Error is a domain error. Reason is a stable UPPER_SNAKE_CASE code callers
+// can rely on, unlike the message.
+type Error struct {
+	Reason  string
+	Kind    ErrorKind
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(reason string, kind ErrorKind, message string) *Error {
+	return &Error{Reason: reason, Kind: kind, Message: message}
+}
 
 var (
-	ErrTransactionNotFound = errors.New("ErrTransactionNotFound: Transaction not found")
-	ErrInsufficientBalance = errors.New("ErrTransactionNotFound: Insufficient balance")
+	ErrTransactionNotFound       = newError("TRANSACTION_NOT_FOUND", KindNotFound, "Transaction not found")
+	ErrInsufficientBalance       = newError("INSUFFICIENT_BALANCE", KindFailedPrecondition, "Insufficient balance")
+	ErrEmptySearchQuery          = newError("EMPTY_SEARCH_QUERY", KindInvalidArgument, "Search query has no searchable words")
+	ErrInvalidDateRange          = newError("INVALID_DATE_RANGE", KindInvalidArgument, "From date is after to date")
+	ErrInvalidAmountRange        = newError("INVALID_AMOUNT_RANGE", KindInvalidArgument, "Minimum amount is greater than maximum amount")
+	ErrInvalidPageToken          = newError("INVALID_PAGE_TOKEN", KindInvalidArgument, "Page token is invalid or does not match the request")
+	ErrResultTooLarge            = newError("RESULT_TOO_LARGE", KindFailedPrecondition, "Too many transactions for a single response, use StreamTransactions")
+	ErrInvalidResumeToken        = newError("INVALID_RESUME_TOKEN", KindInvalidArgument, "Resume token is invalid")
+	ErrSubscriberTooSlow         = newError("SUBSCRIBER_TOO_SLOW", KindAborted, "Subscriber fell behind, resume from the last received event")
+	ErrShuttingDown              = newError("SHUTTING_DOWN", KindUnavailable, "Service is shutting down, resume from the last received event")
+	ErrSubscriptionsUnavailable  = newError("SUBSCRIPTIONS_UNAVAILABLE", KindUnimplemented, "Transaction subscriptions are not available")
+	ErrWebhookNotFound           = newError("WEBHOOK_NOT_FOUND", KindNotFound, "Webhook subscription not found")
+	ErrTooManyWebhooks           = newError("TOO_MANY_WEBHOOKS", KindResourceExhausted, "Webhook subscription limit reached")
+	ErrInvalidQuietHours         = newError("INVALID_QUIET_HOURS", KindInvalidArgument, "Quiet hours need both a start and an end")
+	ErrApiKeyNotFound            = newError("API_KEY_NOT_FOUND", KindNotFound, "API key not found")
+	ErrDeadOutboxMessageNotFound = newError("DEAD_OUTBOX_MESSAGE_NOT_FOUND", KindNotFound, "No dead outbox message with that id")
+	ErrInvalidApiKey             = newError("INVALID_API_KEY", KindUnauthenticated, "API key is invalid or revoked")
 )
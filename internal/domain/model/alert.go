@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// AlertSettings are the alert thresholds of a user, zero disables an alert.
+// A low balance alert is raised when the balance drops below
+// LowBalanceThreshold, a large transaction alert for every transaction above
+// LargeTransactionAmount.
+type AlertSettings struct {
+	UserId                 string
+	LowBalanceThreshold    int64
+	LargeTransactionAmount int64
+	State                  AlertState
+	UpdatedAt              time.Time
+}
+
+// AlertState debounces low balance alerts: once raised, the alert is only
+// raised again after the balance recovered and the cooldown since the last
+// alert passed.
+type AlertState struct {
+	LowBalance          bool
+	LowBalanceAlertedAt *time.Time
+}
+
+// Alert is attached to events of alert types.
+type Alert struct {
+	Balance   int64 `json:"balance"`
+	Threshold int64 `json:"threshold"`
+}
+
+// CheckLowBalance updates the state for balance and reports whether a low
+// balance alert is due.
+func (s *AlertSettings) CheckLowBalance(balance int64, now time.Time, cooldown time.Duration) bool {
+	if s.LowBalanceThreshold == 0 || balance >= s.LowBalanceThreshold {
+		s.State.LowBalance = false
+		return false
+	}
+	if s.State.LowBalance {
+		return false
+	}
+
+	s.State.LowBalance = true
+	if s.State.LowBalanceAlertedAt != nil && now.Sub(*s.State.LowBalanceAlertedAt) < cooldown {
+		return false
+	}
+	s.State.LowBalanceAlertedAt = &now
+	return true
+}
+
+// IsLarge reports whether transaction exceeds the large transaction amount.
+func (s AlertSettings) IsLarge(transaction Transaction) bool {
+	return s.LargeTransactionAmount > 0 && transaction.Amount > s.LargeTransactionAmount
+}
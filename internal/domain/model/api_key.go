@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+type ApiKeyScope string
+
+const (
+	ScopeTransactionsRead  ApiKeyScope = "transactions:read"
+	ScopeTransactionsWrite ApiKeyScope = "transactions:write"
+	ScopeAdmin             ApiKeyScope = "admin"
+)
+
+// ApiKey authenticates a service that cannot use JWTs. Only the SHA-256 hash
+// of its secret is stored. LastUsedAt and RequestCount are recorded in batches
+// of authenticated requests.
+type ApiKey struct {
+	Id           string
+	Service      string
+	SecretHash   []byte
+	Scopes       []ApiKeyScope
+	CreatedAt    time.Time
+	RevokedAt    *time.Time
+	LastUsedAt   *time.Time
+	RequestCount int64
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// TransactionCursor marks a position in an ordered listing of transactions. It
+// holds every sortable field of the last transaction of a page so that the next
+// page can continue right after it, whatever the sort order is.
+type TransactionCursor struct {
+	Id        string    `json:"id"`
+	Type      string    `json:"type,omitempty"`
+	Amount    int64     `json:"amount,omitempty"`
+	Date      time.Time `json:"date"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Rank      float64   `json:"rank,omitempty"`
+}
+
+func CursorOf(t Transaction) TransactionCursor {
+	return TransactionCursor{
+		Id:        t.Id,
+		Type:      t.Type,
+		Amount:    t.Amount,
+		Date:      t.Date,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+func SearchCursorOf(r TransactionSearchResult) TransactionCursor {
+	cursor := CursorOf(r.Transaction)
+	cursor.Rank = r.Rank
+	return cursor
+}
+
+// IsAfter reports whether t comes after the cursor in the sort order.
+func (s TransactionSort) IsAfter(t Transaction, cursor TransactionCursor) bool {
+	return s.Less(cursor.transaction(), t)
+}
+
+// IsAfter reports whether r comes after the cursor in search result order,
+// which is by rank, then date, then id, all descending.
+func (c TransactionCursor) IsAfter(r TransactionSearchResult) bool {
+	if r.Rank != c.Rank {
+		return r.Rank < c.Rank
+	}
+	if !r.Transaction.Date.Equal(c.Date) {
+		return r.Transaction.Date.Before(c.Date)
+	}
+	return strings.Compare(r.Transaction.Id, c.Id) < 0
+}
+
+func (c TransactionCursor) transaction() Transaction {
+	return Transaction{
+		Id:        c.Id,
+		Type:      c.Type,
+		Amount:    c.Amount,
+		Date:      c.Date,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
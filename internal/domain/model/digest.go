@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// TransactionSummary aggregates the transactions of a user in a period.
+type TransactionSummary struct {
+	Count    int   `json:"count"`
+	TotalIn  int64 `json:"totalIn"`
+	TotalOut int64 `json:"totalOut"`
+	// ClosingBalance covers every transaction dated before the end of the period
+	ClosingBalance int64        `json:"closingBalance"`
+	Largest        *Transaction `json:"largest,omitempty"`
+}
+
+// DailyDigest summarizes the transactions of a user on Day, a date in TimeZone.
+type DailyDigest struct {
+	UserId   string `json:"userId"`
+	Day      string `json:"day"`
+	TimeZone string `json:"timeZone"`
+	TransactionSummary
+}
+
+// DigestDayLayout formats the Day of a DailyDigest.
+const DigestDayLayout = "2006-01-02"
+
+// PreviousDay returns the last day that ended before now in location, as the
+// instants it starts and ends at.
+func PreviousDay(now time.Time, location *time.Location) (time.Time, time.Time) {
+	local := now.In(location)
+	end := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	return end.AddDate(0, 0, -1), end
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type TransactionEventType string
+
+const (
+	TransactionCreated TransactionEventType = "created"
+	TransactionUpdated TransactionEventType = "updated"
+	TransactionDeleted TransactionEventType = "deleted"
+	// InsufficientBalance is raised for rejected withdrawals, its transaction was never stored.
+	InsufficientBalance TransactionEventType = "insufficient_balance"
+	// TransactionDigest summarizes a day of transactions, it only occurs in notifications.
+	TransactionDigest TransactionEventType = "daily_digest"
+	// LowBalance and LargeTransaction are alerts, raised after the write causing them committed.
+	LowBalance       TransactionEventType = "low_balance"
+	LargeTransaction TransactionEventType = "large_transaction"
+)
+
+// TransactionEvent records a change to a transaction. Sequence increases with
+// every event and, for a single user, follows the order of the commits. Alert
+// is only set for alert events.
+type TransactionEvent struct {
+	Id          string               `json:"id"`
+	Sequence    int64                `json:"sequence"`
+	Type        TransactionEventType `json:"type"`
+	UserId      string               `json:"userId"`
+	Transaction Transaction          `json:"transaction"`
+	OccurredAt  time.Time            `json:"occurredAt"`
+	Alert       *Alert               `json:"alert,omitempty"`
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// TransactionFilter narrows down listed transactions. Zero values mean no
+// restriction, all set criteria must match.
+type TransactionFilter struct {
+	UserIds             []string
+	Types               []string
+	FromDate            *time.Time
+	ToDate              *time.Time
+	MinAmount           *int64
+	MaxAmount           *int64
+	DescriptionContains string
+	CreatedFrom         *time.Time
+	CreatedTo           *time.Time
+	UpdatedFrom         *time.Time
+	UpdatedTo           *time.Time
+}
+
+// Matches reports whether t satisfies the filter.
+func (f TransactionFilter) Matches(t Transaction) bool {
+	return (len(f.UserIds) == 0 || contains(f.UserIds, t.UserId)) &&
+		(len(f.Types) == 0 || contains(f.Types, t.Type)) &&
+		inTimeRange(t.Date, f.FromDate, f.ToDate) &&
+		(f.MinAmount == nil || t.Amount >= *f.MinAmount) &&
+		(f.MaxAmount == nil || t.Amount <= *f.MaxAmount) &&
+		(f.DescriptionContains == "" || strings.Contains(strings.ToLower(t.Description), strings.ToLower(f.DescriptionContains))) &&
+		inTimeRange(t.CreatedAt, f.CreatedFrom, f.CreatedTo) &&
+		inTimeRange(t.UpdatedAt, f.UpdatedFrom, f.UpdatedTo)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func inTimeRange(t time.Time, from, to *time.Time) bool {
+	return (from == nil || !t.Before(*from)) && (to == nil || !t.After(*to))
+}
+
+type SortField string
+
+const (
+	SortByDate      SortField = "date"
+	SortByAmount    SortField = "amount"
+	SortByType      SortField = "type"
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+)
+
+// TransactionSort orders listed transactions, ties are always broken by id in
+// the same direction so the order is stable.
+type TransactionSort struct {
+	Field      SortField
+	Descending bool
+}
+
+// DefaultTransactionSort lists the most recent transactions first.
+var DefaultTransactionSort = TransactionSort{Field: SortByDate, Descending: true}
+
+// Less reports whether a sorts before b.
+func (s TransactionSort) Less(a, b Transaction) bool {
+	cmp := compareField(s.Field, a, b)
+	if cmp == 0 {
+		cmp = strings.Compare(a.Id, b.Id)
+	}
+	if s.Descending {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+func compareField(field SortField, a, b Transaction) int {
+	switch field {
+	case SortByAmount:
+		return compareInt64(a.Amount, b.Amount)
+	case SortByType:
+		return strings.Compare(a.Type, b.Type)
+	case SortByCreatedAt:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case SortByUpdatedAt:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	default:
+		return a.Date.Compare(b.Date)
+	}
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
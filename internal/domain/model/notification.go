@@ -0,0 +1,119 @@
+package model
+
+import "time"
+
+// Notification is a transaction event rendered for a user. Digest is only set
+// for events of type TransactionDigest. Attempt counts the deliveries of the
+// notification, starting at 1.
+type Notification struct {
+	Event   TransactionEvent
+	Digest  *DailyDigest
+	Locale  string
+	Subject string
+	Message string
+	Attempt int
+}
+
+// NotificationPreferences decide which notifications a user receives and how.
+// Empty EventTypes or Channels mean all of them. Quiet hours are minutes after
+// midnight in TimeZone; notifications falling into them are held back until
+// they end. Start equal to end means no quiet hours.
+type NotificationPreferences struct {
+	UserId          string
+	EventTypes      []TransactionEventType
+	Channels        []string
+	QuietHoursStart int
+	QuietHoursEnd   int
+	TimeZone        string
+	MinAmount       int64
+	Locale          string
+	DailyDigest     bool
+	UpdatedAt       time.Time
+}
+
+// DefaultLocale is used for users without a locale.
+const DefaultLocale = "en"
+
+// DefaultNotificationPreferences apply to users who never set any.
+func DefaultNotificationPreferences(userId string) NotificationPreferences {
+	return NotificationPreferences{UserId: userId, Locale: DefaultLocale}
+}
+
+// Wants reports whether the user wants to be notified about event at all.
+func (p NotificationPreferences) Wants(event TransactionEvent) bool {
+	switch event.Type {
+	case TransactionCreated, TransactionUpdated, TransactionDeleted:
+		if p.DailyDigest {
+			return false
+		}
+	}
+	if event.Alert == nil && event.Transaction.Amount < p.MinAmount {
+		return false
+	}
+	if len(p.EventTypes) == 0 {
+		return true
+	}
+	for _, eventType := range p.EventTypes {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+func (p NotificationPreferences) UsesChannel(channel string) bool {
+	if len(p.Channels) == 0 {
+		return true
+	}
+	for _, c := range p.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Location returns the time zone of the user, UTC when unknown.
+func (p NotificationPreferences) Location() *time.Location {
+	if p.TimeZone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// QuietUntil returns when the quiet hours around now end, or false when now is
+// outside of them.
+func (p NotificationPreferences) QuietUntil(now time.Time) (time.Time, bool) {
+	if p.QuietHoursStart == p.QuietHoursEnd {
+		return time.Time{}, false
+	}
+
+	local := now.In(p.Location())
+	minute := local.Hour()*60 + local.Minute()
+	// time.Date rather than adding minutes to midnight, which is off by an hour
+	// on days the clocks change
+	end := func(days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, p.QuietHoursEnd/60, p.QuietHoursEnd%60, 0, 0, local.Location())
+	}
+
+	if p.QuietHoursStart < p.QuietHoursEnd {
+		// e.g. 13:00 to 15:00
+		if minute >= p.QuietHoursStart && minute < p.QuietHoursEnd {
+			return end(0), true
+		}
+		return time.Time{}, false
+	}
+
+	// Wrapping midnight, e.g. 22:00 to 07:00
+	if minute >= p.QuietHoursStart {
+		return end(1), true
+	}
+	if minute < p.QuietHoursEnd {
+		return end(0), true
+	}
+	return time.Time{}, false
+}
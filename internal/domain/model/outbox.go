@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxDelivered OutboxStatus = "delivered"
+	// OutboxDead marks messages that ran out of delivery attempts
+	OutboxDead OutboxStatus = "dead"
+)
+
+const (
+	// OutboxKindNotification messages carry a TransactionEvent to notify its user about.
+	OutboxKindNotification = "notification"
+	// OutboxKindEvent messages carry a TransactionEvent to publish to the message broker.
+	OutboxKindEvent = "event"
+	// OutboxKindDigest messages carry a DailyDigest to notify its user about.
+	OutboxKindDigest = "digest"
+)
+
+// OutboxMessage is a side effect recorded in the same database transaction as
+// the change causing it and delivered afterwards by a relay.
+type OutboxMessage struct {
+	Id     int64
+	Kind   string
+	UserId string
+	// OrderingKey makes messages sharing it deliver one at a time in the order
+	// they were enqueued, a message is held back while an earlier one is pending
+	// or dead, so a dead-lettered message stops its key until it is retried.
+	OrderingKey string
+	// TraceContext carries the W3C trace context of the enqueuing request, so
+	// the delivery continues its trace.
+	TraceContext  map[string]string
+	Payload       []byte
+	Status        OutboxStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	// LockedUntil is set while a relay delivers the message
+	LockedUntil *time.Time
+	LastError   string
+	CreatedAt   time.Time
+	DeliveredAt *time.Time
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit allows Rate requests per second on average and bursts of up to
+// Burst requests.
+type RateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// TokenBucket is the state of a rate limited key. A new bucket is full.
+type TokenBucket struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+func NewTokenBucket(key string, limit RateLimit, now time.Time) TokenBucket {
+	return TokenBucket{Key: key, Tokens: float64(limit.Burst), UpdatedAt: now}
+}
+
+// Take refills the bucket for the time passed since its last update and
+// takes a token. Without a token left it returns how long until one is.
+func (b *TokenBucket) Take(limit RateLimit, now time.Time) (bool, time.Duration) {
+	if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed.Seconds()*limit.Rate)
+		b.UpdatedAt = now
+	}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return true, 0
+	}
+	if limit.Rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	return false, time.Duration((1 - b.Tokens) / limit.Rate * float64(time.Second))
+}
+
+// FullAt returns when the bucket is full again, after which it can be dropped.
+func (b TokenBucket) FullAt(limit RateLimit) time.Time {
+	if limit.Rate <= 0 {
+		return b.UpdatedAt.Add(24 * time.Hour)
+	}
+	missing := float64(limit.Burst) - b.Tokens
+	return b.UpdatedAt.Add(time.Duration(missing / limit.Rate * float64(time.Second)))
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SearchTerm is a single full-text search term. A term with more than one word
+// is a phrase and only matches when the words appear next to each other in
+// order. When Prefix is set the last word matches any word starting with it.
+type SearchTerm struct {
+	Words  []string
+	Prefix bool
+}
+
+// TransactionSearch describes a full-text search over the descriptions of a
+// single user's transactions. All terms and the filter must match. Results
+// are ordered by rank and continue after the After cursor when it is set.
+type TransactionSearch struct {
+	UserId string
+	Terms  []SearchTerm
+	Filter TransactionFilter
+	After  *TransactionCursor
+	Limit  int
+}
+
+type TransactionSearchResult struct {
+	Transaction Transaction
+	Rank        float64
+}
+
+// ParseSearchQuery splits a user supplied query into search terms.
+// Double quoted text becomes a phrase and a trailing '*' turns the last word of
+// a term into a prefix match, e.g. `"netflix pay*" spring`.
+func ParseSearchQuery(query string) []SearchTerm {
+	var terms []SearchTerm
+
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			// Inside quotes: the whole part is a single phrase
+			if term, ok := newSearchTerm(part); ok {
+				terms = append(terms, term)
+			}
+			continue
+		}
+		for _, field := range strings.Fields(part) {
+			if term, ok := newSearchTerm(field); ok {
+				terms = append(terms, term)
+			}
+		}
+	}
+
+	return terms
+}
+
+func newSearchTerm(text string) (SearchTerm, bool) {
+	text = strings.TrimSpace(text)
+	prefix := strings.HasSuffix(text, "*")
+
+	words := SearchWords(text)
+	if len(words) == 0 {
+		return SearchTerm{}, false
+	}
+	return SearchTerm{Words: words, Prefix: prefix}, true
+}
+
+// SearchWords lower-cases text and splits it into words made of letters and
+// digits, which mirrors how the 'simple' text search configuration tokenizes
+// descriptions.
+func SearchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
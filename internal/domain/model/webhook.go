@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+type WebhookStatus string
+
+const (
+	WebhookActive WebhookStatus = "active"
+	WebhookPaused WebhookStatus = "paused"
+)
+
+// WebhookSubscription is an endpoint registered by a user to receive events of
+// the given types. After a rotation payloads are also signed with the previous
+// secret until PreviousSecretExpiresAt, so receivers can switch without gaps.
+type WebhookSubscription struct {
+	Id                      string
+	UserId                  string
+	Url                     string
+	Secret                  string
+	PreviousSecret          string
+	PreviousSecretExpiresAt *time.Time
+	EventTypes              []TransactionEventType
+	Status                  WebhookStatus
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+}
+
+func (s WebhookSubscription) Accepts(eventType TransactionEventType) bool {
+	if s.Status != WebhookActive {
+		return false
+	}
+	for _, accepted := range s.EventTypes {
+		if accepted == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Secrets returns the secrets payloads are signed with at now.
+func (s WebhookSubscription) Secrets(now time.Time) []string {
+	secrets := []string{s.Secret}
+	if s.PreviousSecret != "" && s.PreviousSecretExpiresAt != nil && now.Before(*s.PreviousSecretExpiresAt) {
+		secrets = append(secrets, s.PreviousSecret)
+	}
+	return secrets
+}
+
+// WebhookDelivery records a single attempt to post an event to a subscription.
+// StatusCode is zero when no response was received.
+type WebhookDelivery struct {
+	Id             int64
+	SubscriptionId string
+	EventId        string
+	EventType      TransactionEventType
+	Attempt        int
+	StatusCode     int
+	Latency        time.Duration
+	Error          string
+	AttemptedAt    time.Time
+}
+
+func (d WebhookDelivery) Succeeded() bool {
+	return d.Error == "" && d.StatusCode >= 200 && d.StatusCode <= 299
+}
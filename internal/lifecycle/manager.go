@@ -0,0 +1,162 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Component is a part of the service that has to be started and stopped. All
+// hooks are optional.
+type Component struct {
+	Name string
+	// Start prepares the component and must not block.
+	Start func(ctx context.Context) error
+	// Run does the work of the component until its context is done. Returning
+	// earlier shuts the service down.
+	Run func(ctx context.Context) error
+	// Stop releases the component, forcibly once ctx is done.
+	Stop func(ctx context.Context) error
+}
+
+type running struct {
+	Component
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type exit struct {
+	name string
+	err  error
+}
+
+// Manager starts components in order and stops them in reverse order, so a
+// component only stops once the ones started after it, which may depend on
+// it, are stopped.
+type Manager struct {
+	shutdownTimeout time.Duration
+
+	mu         sync.Mutex
+	components []*running
+	exits      chan exit
+	stopped    bool
+}
+
+// ManagerOption configures a Manager.
+type ManagerOption func(*Manager)
+
+// WithShutdownTimeout bounds how long stopping all components may take, 30
+// seconds by default.
+func WithShutdownTimeout(timeout time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.shutdownTimeout = timeout
+	}
+}
+
+func NewManager(options ...ManagerOption) *Manager {
+	m := &Manager{
+		shutdownTimeout: 30 * time.Second,
+		exits:           make(chan exit, 1),
+	}
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+// Start starts component and, if it has a Run hook, runs it in the background.
+func (m *Manager) Start(ctx context.Context, component Component) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return fmt.Errorf("failed to start %s: shutting down", component.Name)
+	}
+
+	if component.Start != nil {
+		if err := component.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start %s: %w", component.Name, err)
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	r := &running{Component: component, cancel: cancel, done: make(chan struct{})}
+	m.components = append(m.components, r)
+	slog.Debug("component started", "component", component.Name)
+	if component.Run == nil {
+		close(r.done)
+		return nil
+	}
+
+	go func() {
+		defer close(r.done)
+		err := component.Run(runCtx)
+		if runCtx.Err() != nil {
+			if err != nil {
+				slog.Error("component failed while stopping", "component", component.Name, "error", err)
+			}
+			return
+		}
+		if err == nil {
+			err = errors.New("stopped unexpectedly")
+		}
+		select {
+		case m.exits <- exit{name: component.Name, err: err}:
+		default:
+			slog.Error("component failed", "component", component.Name, "error", err)
+		}
+	}()
+	return nil
+}
+
+// Wait blocks until ctx is done or a component stops running on its own, and
+// returns why that component stopped.
+func (m *Manager) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case exit := <-m.exits:
+		return fmt.Errorf("%s failed: %w", exit.name, exit.err)
+	}
+}
+
+// Shutdown stops the started components in reverse order. Running components
+// are cancelled and awaited after their Stop hook returned. Components still
+// running at the shutdown timeout are abandoned and reported.
+func (m *Manager) Shutdown() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(m.components) - 1; i >= 0; i-- {
+		component := m.components[i]
+		if err := m.stop(ctx, component); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", component.Name, err))
+		}
+	}
+	m.components = nil
+	return errors.Join(errs...)
+}
+
+func (m *Manager) stop(ctx context.Context, component *running) error {
+	start := time.Now()
+	component.cancel()
+	var err error
+	if component.Stop != nil {
+		err = component.Stop(ctx)
+	}
+
+	select {
+	case <-component.done:
+	case <-ctx.Done():
+		err = errors.Join(err, fmt.Errorf("still running: %w", ctx.Err()))
+	}
+	slog.Info("component stopped", "component", component.Name, "duration_ms", time.Since(start).Milliseconds())
+	return err
+}
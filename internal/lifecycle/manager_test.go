@@ -0,0 +1,120 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/lifecycle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// journal records the hooks called, in order.
+type journal struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (j *journal) record(entry string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, entry)
+}
+
+func (j *journal) get() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]string{}, j.entries...)
+}
+
+func (j *journal) component(name string) lifecycle.Component {
+	return lifecycle.Component{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			j.record("start " + name)
+			return nil
+		},
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			j.record("drained " + name)
+			return nil
+		},
+	}
+}
+
+func TestManagerStopsComponentsInReverseOrder(t *testing.T) {
+	j := &journal{}
+	manager := lifecycle.NewManager()
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, manager.Start(ctx, j.component("database")))
+	require.NoError(t, manager.Start(ctx, lifecycle.Component{Name: "pool", Stop: func(ctx context.Context) error {
+		j.record("stop pool")
+		return nil
+	}}))
+	require.NoError(t, manager.Start(ctx, j.component("grpc")))
+
+	cancel()
+	assert.NoError(t, manager.Wait(ctx))
+	assert.NoError(t, manager.Shutdown())
+	assert.Equal(t, []string{
+		"start database", "start grpc",
+		"drained grpc", "stop pool", "drained database",
+	}, j.get())
+
+	assert.Error(t, manager.Start(context.Background(), j.component("late")))
+}
+
+func TestManagerWaitReportsFailedComponents(t *testing.T) {
+	manager := lifecycle.NewManager()
+	require.NoError(t, manager.Start(context.Background(), lifecycle.Component{Name: "relay", Run: func(ctx context.Context) error {
+		return errors.New("connection lost")
+	}}))
+	require.NoError(t, manager.Start(context.Background(), lifecycle.Component{Name: "listener", Run: func(ctx context.Context) error {
+		return nil
+	}}))
+
+	err := manager.Wait(context.Background())
+	assert.Error(t, err)
+	assert.NoError(t, manager.Shutdown())
+}
+
+func TestManagerStartFailure(t *testing.T) {
+	j := &journal{}
+	manager := lifecycle.NewManager()
+	require.NoError(t, manager.Start(context.Background(), j.component("database")))
+
+	failure := errors.New("address already in use")
+	err := manager.Start(context.Background(), lifecycle.Component{Name: "grpc", Start: func(ctx context.Context) error {
+		return failure
+	}, Stop: func(ctx context.Context) error {
+		j.record("stop grpc")
+		return nil
+	}})
+	assert.ErrorIs(t, err, failure)
+
+	// Components that failed to start are not stopped
+	assert.NoError(t, manager.Shutdown())
+	assert.Equal(t, []string{"start database", "drained database"}, j.get())
+}
+
+func TestManagerShutdownTimeout(t *testing.T) {
+	manager := lifecycle.NewManager(lifecycle.WithShutdownTimeout(10 * time.Millisecond))
+	release := make(chan struct{})
+	defer close(release)
+	require.NoError(t, manager.Start(context.Background(), lifecycle.Component{Name: "digest job", Run: func(ctx context.Context) error {
+		<-release
+		return nil
+	}}))
+	stopErr := errors.New("still connected")
+	require.NoError(t, manager.Start(context.Background(), lifecycle.Component{Name: "nats", Stop: func(ctx context.Context) error {
+		return stopErr
+	}}))
+
+	err := manager.Shutdown()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, stopErr)
+	assert.Contains(t, err.Error(), "failed to stop digest job")
+}
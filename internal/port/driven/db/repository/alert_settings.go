@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+)
+
+type AlertSettingsRepository interface {
+	// GetAlertSettings returns nil for users who never configured alerts.
+	GetAlertSettings(ctx context.Context, userId string) (*model.AlertSettings, error)
+	// LockAlertSettings is GetAlertSettings locking the settings until the end
+	// of the database transaction, so alerts of a user are checked one at a time.
+	LockAlertSettings(ctx context.Context, userId string) (*model.AlertSettings, error)
+	// SaveAlertSettings stores the thresholds and keeps the alert state.
+	SaveAlertSettings(ctx context.Context, settings model.AlertSettings) error
+	SaveAlertState(ctx context.Context, userId string, state model.AlertState) error
+}
+
+type AlertSettingsRepositoryFactory interface {
+	New(handler db.DbHandler) AlertSettingsRepository
+}
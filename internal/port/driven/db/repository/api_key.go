@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+)
+
+type ApiKeyRepository interface {
+	CreateApiKey(ctx context.Context, key model.ApiKey) error
+	GetApiKeyById(ctx context.Context, id string) (*model.ApiKey, error)
+	// ListApiKeys returns the keys of service, or all keys when it is empty.
+	ListApiKeys(ctx context.Context, service string) ([]model.ApiKey, error)
+	RevokeApiKey(ctx context.Context, id string, at time.Time) error
+	// RecordApiKeyUsage adds requests to the request count of a key and moves
+	// its last use forward to lastUsedAt.
+	RecordApiKeyUsage(ctx context.Context, id string, requests int64, lastUsedAt time.Time) error
+}
+
+type ApiKeyRepositoryFactory interface {
+	New(handler db.DbHandler) ApiKeyRepository
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+)
+
+type DigestRunRepository interface {
+	// RecordDigestRun records that the digest of day was produced for a user and
+	// returns false when it already was.
+	RecordDigestRun(ctx context.Context, userId, day string) (bool, error)
+}
+
+type DigestRunRepositoryFactory interface {
+	New(handler db.DbHandler) DigestRunRepository
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+)
+
+type NotificationPreferencesRepository interface {
+	// GetPreferences returns nil for users who never stored preferences.
+	GetPreferences(ctx context.Context, userId string) (*model.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, preferences model.NotificationPreferences) error
+	// ListDigestSubscribers returns up to limit users receiving the daily
+	// digest, ordered by user id and starting after afterUserId.
+	ListDigestSubscribers(ctx context.Context, afterUserId string, limit int) ([]model.NotificationPreferences, error)
+}
+
+type NotificationPreferencesRepositoryFactory interface {
+	New(handler db.DbHandler) NotificationPreferencesRepository
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+)
+
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, message model.OutboxMessage) (int64, error)
+	// LeasePending leases up to limit pending messages that are due at now,
+	// oldest first, until lockedUntil and counts the attempt. Messages leased by
+	// another relay are skipped until their lease expires, as are messages with
+	// an earlier pending or dead message of the same ordering key; a dead one
+	// holds them back until it is requeued with RequeueDead.
+	LeasePending(ctx context.Context, now, lockedUntil time.Time, limit int) ([]model.OutboxMessage, error)
+	// MarkDelivered, MarkFailed and MarkDead end the lease of a message as
+	// returned by LeasePending. They leave the message alone once it was leased
+	// again after its lease expired.
+	MarkDelivered(ctx context.Context, message model.OutboxMessage, deliveredAt time.Time) error
+	MarkFailed(ctx context.Context, message model.OutboxMessage, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkDead(ctx context.Context, message model.OutboxMessage, lastError string) error
+	// RequeueDead sets a dead message back to pending with no attempts, due at
+	// now, releasing the later messages of its ordering key. It reports false
+	// when there is no dead message with the id.
+	RequeueDead(ctx context.Context, id int64, now time.Time) (bool, error)
+	// CountBlockedOrderingKeys counts the ordering keys held back by a dead message.
+	CountBlockedOrderingKeys(ctx context.Context) (int, error)
+	// GetDeliveredChannels and MarkChannelDelivered track the channels a
+	// message fanning out to several of them was delivered through already.
+	GetDeliveredChannels(ctx context.Context, messageId int64) ([]string, error)
+	MarkChannelDelivered(ctx context.Context, messageId int64, channel string, deliveredAt time.Time) error
+}
+
+type OutboxRepositoryFactory interface {
+	New(handler db.DbHandler) OutboxRepository
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+)
+
+type RateLimitRepository interface {
+	// LockBucket creates the bucket from initial unless it exists and locks it
+	// until the end of the database transaction.
+	LockBucket(ctx context.Context, initial model.TokenBucket, fullAt time.Time) (*model.TokenBucket, error)
+	SaveBucket(ctx context.Context, bucket model.TokenBucket, fullAt time.Time) error
+	// DeleteFullBuckets drops buckets that were full again before now.
+	DeleteFullBuckets(ctx context.Context, now time.Time) error
+}
+
+type RateLimitRepositoryFactory interface {
+	New(handler db.DbHandler) RateLimitRepository
+}
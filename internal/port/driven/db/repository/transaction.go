@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/nullexp/finman-transaction-service/internal/domain/model"
 	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
@@ -10,12 +11,17 @@ import (
 type TransactionRepository interface {
 	CreateTransaction(ctx context.Context, transaction model.Transaction) (string, error)
 	GetTransactionById(ctx context.Context, id string) (*model.Transaction, error)
-	GetAllTransactions(ctx context.Context) ([]model.Transaction, error)
 	UpdateTransaction(ctx context.Context, transaction model.Transaction) error
 	DeleteTransaction(ctx context.Context, id string) error
-	GetTransactionsByUserId(ctx context.Context, userId string) ([]model.Transaction, error)
 	GetTransactionsWithPagination(ctx context.Context, offset, limit int) ([]model.Transaction, error)
 	GetBalanceByUserId(ctx context.Context, userId string) (int64, error)
+	SearchTransactions(ctx context.Context, search model.TransactionSearch) ([]model.TransactionSearchResult, error)
+	ListTransactions(ctx context.Context, filter model.TransactionFilter, sort model.TransactionSort, after *model.TransactionCursor, limit int) ([]model.Transaction, error)
+	CountTransactions(ctx context.Context, filter model.TransactionFilter) (int64, error)
+	// SummarizeTransactions aggregates the transactions of a user dated in [from, to).
+	SummarizeTransactions(ctx context.Context, userId string, from, to time.Time) (*model.TransactionSummary, error)
+	// StreamTransactions walks all matching transactions in order and hands them to fn in batches.
+	StreamTransactions(ctx context.Context, filter model.TransactionFilter, sort model.TransactionSort, batchSize int, fn func([]model.Transaction) error) error
 }
 
 type TransactionRepositoryFactory interface {
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+)
+
+type TransactionEventRepository interface {
+	// AppendEvent stores an event and assigns its id and sequence. Subscribers
+	// are notified once the enclosing database transaction commits.
+	AppendEvent(ctx context.Context, event model.TransactionEvent) (*model.TransactionEvent, error)
+	GetEventBySequence(ctx context.Context, sequence int64) (*model.TransactionEvent, error)
+	// GetEventsAfter returns up to limit events following sequence in order, for all users when userId is empty.
+	GetEventsAfter(ctx context.Context, userId string, sequence int64, limit int) ([]model.TransactionEvent, error)
+	// GetLatestSequence returns the sequence of the latest event, of all users when userId is empty.
+	GetLatestSequence(ctx context.Context, userId string) (int64, error)
+}
+
+type TransactionEventRepositoryFactory interface {
+	New(handler db.DbHandler) TransactionEventRepository
+}
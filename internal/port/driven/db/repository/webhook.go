@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+	"github.com/nullexp/finman-transaction-service/internal/port/driven/db"
+)
+
+type WebhookRepository interface {
+	// LockSubscriptions serializes changes to the subscriptions of a user until
+	// the enclosing database transaction ends.
+	LockSubscriptions(ctx context.Context, userId string) error
+	CreateSubscription(ctx context.Context, subscription model.WebhookSubscription) (string, error)
+	GetSubscriptionById(ctx context.Context, id string) (*model.WebhookSubscription, error)
+	GetSubscriptionsByUserId(ctx context.Context, userId string) ([]model.WebhookSubscription, error)
+	// GetActiveSubscriptions returns the active subscriptions of a user accepting eventType.
+	GetActiveSubscriptions(ctx context.Context, userId string, eventType model.TransactionEventType) ([]model.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription model.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+	RecordDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	// GetDeliveries returns up to limit deliveries of a subscription with an id
+	// below beforeId, newest first. A zero beforeId starts with the newest.
+	GetDeliveries(ctx context.Context, subscriptionId string, beforeId int64, limit int) ([]model.WebhookDelivery, error)
+}
+
+type WebhookRepositoryFactory interface {
+	New(handler db.DbHandler) WebhookRepository
+}
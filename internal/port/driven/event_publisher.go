@@ -0,0 +1,13 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+// EventPublisher publishes committed transaction events for other services.
+// Events may be published more than once, consumers deduplicate them by id.
+type EventPublisher interface {
+	PublishTransactionEvent(ctx context.Context, event model.TransactionEvent) error
+}
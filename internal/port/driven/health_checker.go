@@ -0,0 +1,9 @@
+package driven
+
+import "context"
+
+// HealthChecker is implemented by backends that can tell whether they are
+// currently usable, readiness fails while CheckHealth returns an error.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
@@ -2,9 +2,18 @@ package driven
 
 import (
 	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
 )
 
 // NotificationService is an interface for sending notifications
 type NotificationService interface {
-	SendTransactionNotification(ctx context.Context, userId, message string) error
+	SendTransactionNotification(ctx context.Context, notification model.Notification) error
+}
+
+// NotificationRenderer renders the subject and message of a notification about
+// an event in the language of locale, with times shown in timeZone.
+type NotificationRenderer interface {
+	RenderNotification(event model.TransactionEvent, locale, timeZone string) (subject, message string, err error)
+	RenderDigest(digest model.DailyDigest, locale string) (subject, message string, err error)
 }
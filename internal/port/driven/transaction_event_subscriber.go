@@ -0,0 +1,17 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/domain/model"
+)
+
+// TransactionEventSubscriber delivers committed transaction events of a user as
+// they happen. The channel is closed when ctx is done, when the subscriber
+// falls too far behind to keep up or when the service shuts down.
+type TransactionEventSubscriber interface {
+	Subscribe(ctx context.Context, userId string) (<-chan model.TransactionEvent, error)
+	// Done is closed once the service shuts down, telling apart subscribers
+	// whose channel was closed for it from those that fell behind.
+	Done() <-chan struct{}
+}
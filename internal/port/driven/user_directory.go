@@ -0,0 +1,9 @@
+package driven
+
+import "context"
+
+// UserDirectory looks up contact details of users, which this service does not own.
+type UserDirectory interface {
+	// GetUserEmail returns the email address of a user, empty when the user has none.
+	GetUserEmail(ctx context.Context, userId string) (string, error)
+}
@@ -0,0 +1,13 @@
+package driver
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+type AlertSettingsService interface {
+	// GetAlertSettings returns disabled alerts for users who never configured any.
+	GetAlertSettings(ctx context.Context, request model.GetAlertSettingsRequest) (*model.GetAlertSettingsResponse, error)
+	UpdateAlertSettings(ctx context.Context, request model.UpdateAlertSettingsRequest) (*model.UpdateAlertSettingsResponse, error)
+}
@@ -0,0 +1,16 @@
+package driver
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+type ApiKeyService interface {
+	// CreateApiKey returns the new key, which is not shown again.
+	CreateApiKey(ctx context.Context, request model.CreateApiKeyRequest) (*model.CreateApiKeyResponse, error)
+	ListApiKeys(ctx context.Context, request model.ListApiKeysRequest) (*model.ListApiKeysResponse, error)
+	RevokeApiKey(ctx context.Context, request model.RevokeApiKeyRequest) (*model.ApiKey, error)
+	// AuthenticateApiKey returns the key a caller presented and records its use.
+	AuthenticateApiKey(ctx context.Context, request model.AuthenticateApiKeyRequest) (*model.ApiKey, error)
+}
@@ -0,0 +1,13 @@
+package driver
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+type NotificationPreferencesService interface {
+	// GetNotificationPreferences returns the defaults for users who never changed their preferences.
+	GetNotificationPreferences(ctx context.Context, request model.GetNotificationPreferencesRequest) (*model.GetNotificationPreferencesResponse, error)
+	UpdateNotificationPreferences(ctx context.Context, request model.UpdateNotificationPreferencesRequest) (*model.UpdateNotificationPreferencesResponse, error)
+}
@@ -15,4 +15,11 @@ type TransactionService interface {
 	DeleteTransaction(ctx context.Context, request model.DeleteTransactionRequest) error
 	GetTransactionsByUserId(ctx context.Context, request model.GetTransactionsByUserIdRequest) (*model.GetTransactionsByUserIdResponse, error)
 	GetTransactionsWithPagination(ctx context.Context, request model.GetTransactionsWithPaginationRequest) (*model.GetTransactionsWithPaginationResponse, error)
+	SearchTransactions(ctx context.Context, request model.SearchTransactionsRequest) (*model.SearchTransactionsResponse, error)
+	ListTransactions(ctx context.Context, request model.ListTransactionsRequest) (*model.ListTransactionsResponse, error)
+	// StreamTransactions calls send with consecutive batches of matching transactions until all were sent.
+	StreamTransactions(ctx context.Context, request model.StreamTransactionsRequest, send func([]model.Transaction) error) error
+	// SubscribeTransactions calls send for every committed event of a user until ctx is done,
+	// replaying the events after the resume token first when one is given.
+	SubscribeTransactions(ctx context.Context, request model.SubscribeTransactionsRequest, send func(model.TransactionEvent) error) error
 }
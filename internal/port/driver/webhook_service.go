@@ -0,0 +1,19 @@
+package driver
+
+import (
+	"context"
+
+	"github.com/nullexp/finman-transaction-service/internal/port/model"
+)
+
+type WebhookService interface {
+	CreateWebhookSubscription(ctx context.Context, request model.CreateWebhookSubscriptionRequest) (*model.CreateWebhookSubscriptionResponse, error)
+	ListWebhookSubscriptions(ctx context.Context, request model.ListWebhookSubscriptionsRequest) (*model.ListWebhookSubscriptionsResponse, error)
+	// RotateWebhookSecret replaces the signing secret, the previous one stays valid for a grace period.
+	RotateWebhookSecret(ctx context.Context, request model.WebhookSubscriptionRequest) (*model.RotateWebhookSecretResponse, error)
+	PauseWebhookSubscription(ctx context.Context, request model.WebhookSubscriptionRequest) (*model.WebhookSubscription, error)
+	ResumeWebhookSubscription(ctx context.Context, request model.WebhookSubscriptionRequest) (*model.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, request model.WebhookSubscriptionRequest) error
+	// ListWebhookDeliveries returns the delivery attempts of a subscription, newest first.
+	ListWebhookDeliveries(ctx context.Context, request model.ListWebhookDeliveriesRequest) (*model.ListWebhookDeliveriesResponse, error)
+}
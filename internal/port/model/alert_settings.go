@@ -0,0 +1,42 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	validator "github.com/go-playground/validator/v10"
+)
+
+// AlertSettings of a user, zero disables an alert.
+type AlertSettings struct {
+	UserId                 string    `json:"userId" validate:"required,uuid"`
+	LowBalanceThreshold    int64     `json:"lowBalanceThreshold" validate:"gte=0"`
+	LargeTransactionAmount int64     `json:"largeTransactionAmount" validate:"gte=0"`
+	UpdatedAt              time.Time `json:"updatedAt"`
+}
+
+type GetAlertSettingsRequest struct {
+	UserId string `json:"userId" validate:"required,uuid"`
+}
+
+func (dto GetAlertSettingsRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type GetAlertSettingsResponse struct {
+	Settings AlertSettings `json:"settings"`
+}
+
+type UpdateAlertSettingsRequest struct {
+	Settings AlertSettings `json:"settings"`
+}
+
+func (dto UpdateAlertSettingsRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type UpdateAlertSettingsResponse struct {
+	Settings AlertSettings `json:"settings"`
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	validator "github.com/go-playground/validator/v10"
+)
+
+// ApiKey never carries the key, it is only returned when created.
+type ApiKey struct {
+	Id           string     `json:"id"`
+	Service      string     `json:"service"`
+	Scopes       []string   `json:"scopes"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	RevokedAt    *time.Time `json:"revokedAt"`
+	LastUsedAt   *time.Time `json:"lastUsedAt"`
+	RequestCount int64      `json:"requestCount"`
+}
+
+type CreateApiKeyRequest struct {
+	Service string   `json:"service" validate:"required,max=100"`
+	Scopes  []string `json:"scopes" validate:"required,min=1,dive,oneof=transactions:read transactions:write admin"`
+}
+
+func (dto CreateApiKeyRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type CreateApiKeyResponse struct {
+	ApiKey ApiKey `json:"apiKey"`
+	Key    string `json:"key"`
+}
+
+type ListApiKeysRequest struct {
+	Service string `json:"service"`
+}
+
+func (dto ListApiKeysRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type ListApiKeysResponse struct {
+	ApiKeys []ApiKey `json:"apiKeys"`
+}
+
+type RevokeApiKeyRequest struct {
+	Id string `json:"id" validate:"required,uuid"`
+}
+
+func (dto RevokeApiKeyRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type AuthenticateApiKeyRequest struct {
+	Key string `json:"key" validate:"required"`
+}
+
+func (dto AuthenticateApiKeyRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	validator "github.com/go-playground/validator/v10"
+)
+
+// NotificationPreferences of a user. Empty EventTypes or Channels mean all of
+// them, quiet hours are "HH:MM" in TimeZone and are off when both are empty.
+// DailyDigest replaces notifications about single transactions with a daily
+// summary.
+type NotificationPreferences struct {
+	UserId          string    `json:"userId" validate:"required,uuid"`
+	EventTypes      []string  `json:"eventTypes" validate:"omitempty,dive,oneof=created updated deleted insufficient_balance low_balance large_transaction"`
+	Channels        []string  `json:"channels" validate:"omitempty,dive,oneof=log webhook email"`
+	QuietHoursStart string    `json:"quietHoursStart" validate:"omitempty,datetime=15:04"`
+	QuietHoursEnd   string    `json:"quietHoursEnd" validate:"omitempty,datetime=15:04"`
+	TimeZone        string    `json:"timeZone" validate:"omitempty,timezone"`
+	MinAmount       int64     `json:"minAmount" validate:"gte=0"`
+	Locale          string    `json:"locale" validate:"omitempty,bcp47_language_tag"`
+	DailyDigest     bool      `json:"dailyDigest"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+type GetNotificationPreferencesRequest struct {
+	UserId string `json:"userId" validate:"required,uuid"`
+}
+
+func (dto GetNotificationPreferencesRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type GetNotificationPreferencesResponse struct {
+	Preferences NotificationPreferences `json:"preferences"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences NotificationPreferences `json:"preferences"`
+}
+
+func (dto UpdateNotificationPreferencesRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type UpdateNotificationPreferencesResponse struct {
+	Preferences NotificationPreferences `json:"preferences"`
+}
@@ -83,7 +83,9 @@ type DeleteTransactionRequest struct {
 }
 
 type GetTransactionsByUserIdRequest struct {
-	UserId string `json:"userId" validate:"required,uuid"`
+	UserId    string `json:"userId" validate:"required,uuid"`
+	PageSize  int    `json:"pageSize" validate:"gte=0,lte=1000"`
+	PageToken string `json:"pageToken"`
 }
 
 func (dto GetTransactionsByUserIdRequest) Validate(ctx context.Context) error {
@@ -92,7 +94,8 @@ func (dto GetTransactionsByUserIdRequest) Validate(ctx context.Context) error {
 }
 
 type GetTransactionsByUserIdResponse struct {
-	Transactions []Transaction `json:"transactions"`
+	Transactions  []Transaction `json:"transactions"`
+	NextPageToken string        `json:"nextPageToken"`
 }
 
 type GetTransactionsWithPaginationRequest struct {
@@ -108,3 +111,93 @@ func (dto GetTransactionsWithPaginationRequest) Validate(ctx context.Context) er
 type GetTransactionsWithPaginationResponse struct {
 	Transactions []Transaction `json:"transactions"`
 }
+
+type SearchTransactionsRequest struct {
+	UserId    string     `json:"userId" validate:"required,uuid"`
+	Query     string     `json:"query" validate:"required,max=256"`
+	FromDate  *time.Time `json:"fromDate"`
+	ToDate    *time.Time `json:"toDate"`
+	MinAmount *int64     `json:"minAmount" validate:"omitempty,gte=0"`
+	MaxAmount *int64     `json:"maxAmount" validate:"omitempty,gte=0"`
+	PageSize  int        `json:"pageSize" validate:"gte=0,lte=100"`
+	PageToken string     `json:"pageToken"`
+}
+
+func (dto SearchTransactionsRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type TransactionSearchResult struct {
+	Transaction Transaction `json:"transaction"`
+	Rank        float64     `json:"rank"`
+}
+
+type SearchTransactionsResponse struct {
+	Results       []TransactionSearchResult `json:"results"`
+	NextPageToken string                    `json:"nextPageToken"`
+}
+
+type TransactionFilter struct {
+	UserIds             []string   `json:"userIds" validate:"omitempty,dive,uuid"`
+	Types               []string   `json:"types" validate:"omitempty,dive,oneof=deposit withdrawal"`
+	FromDate            *time.Time `json:"fromDate"`
+	ToDate              *time.Time `json:"toDate"`
+	MinAmount           *int64     `json:"minAmount" validate:"omitempty,gte=0"`
+	MaxAmount           *int64     `json:"maxAmount" validate:"omitempty,gte=0"`
+	DescriptionContains string     `json:"descriptionContains" validate:"max=256"`
+	CreatedFrom         *time.Time `json:"createdFrom"`
+	CreatedTo           *time.Time `json:"createdTo"`
+	UpdatedFrom         *time.Time `json:"updatedFrom"`
+	UpdatedTo           *time.Time `json:"updatedTo"`
+}
+
+type ListTransactionsRequest struct {
+	Filter            TransactionFilter `json:"filter"`
+	SortBy            string            `json:"sortBy" validate:"omitempty,oneof=date amount type created_at updated_at"`
+	SortOrder         string            `json:"sortOrder" validate:"omitempty,oneof=asc desc"`
+	PageSize          int               `json:"pageSize" validate:"gte=0,lte=1000"`
+	PageToken         string            `json:"pageToken"`
+	IncludeTotalCount bool              `json:"includeTotalCount"`
+}
+
+func (dto ListTransactionsRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type ListTransactionsResponse struct {
+	Transactions  []Transaction `json:"transactions"`
+	NextPageToken string        `json:"nextPageToken"`
+	TotalCount    *int64        `json:"totalCount,omitempty"`
+}
+
+type StreamTransactionsRequest struct {
+	Filter    TransactionFilter `json:"filter"`
+	SortBy    string            `json:"sortBy" validate:"omitempty,oneof=date amount type created_at updated_at"`
+	SortOrder string            `json:"sortOrder" validate:"omitempty,oneof=asc desc"`
+	BatchSize int               `json:"batchSize" validate:"gte=0,lte=5000"`
+}
+
+func (dto StreamTransactionsRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type SubscribeTransactionsRequest struct {
+	UserId      string `json:"userId" validate:"required,uuid"`
+	ResumeToken string `json:"resumeToken"`
+}
+
+func (dto SubscribeTransactionsRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type TransactionEvent struct {
+	Id          string      `json:"id"`
+	Type        string      `json:"type"`
+	Transaction Transaction `json:"transaction"`
+	OccurredAt  time.Time   `json:"occurredAt"`
+	ResumeToken string      `json:"resumeToken"`
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	validator "github.com/go-playground/validator/v10"
+)
+
+// WebhookSubscription never carries the secret, it is only returned when created or rotated.
+type WebhookSubscription struct {
+	Id         string    `json:"id"`
+	UserId     string    `json:"userId"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	Paused     bool      `json:"paused"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type WebhookDelivery struct {
+	Id          int64     `json:"id"`
+	EventId     string    `json:"eventId"`
+	EventType   string    `json:"eventType"`
+	Attempt     int       `json:"attempt"`
+	StatusCode  int       `json:"statusCode"`
+	LatencyMs   int64     `json:"latencyMs"`
+	Error       string    `json:"error"`
+	Succeeded   bool      `json:"succeeded"`
+	AttemptedAt time.Time `json:"attemptedAt"`
+}
+
+type CreateWebhookSubscriptionRequest struct {
+	UserId     string   `json:"userId" validate:"required,uuid"`
+	Url        string   `json:"url" validate:"required,url,startswith=https://"`
+	EventTypes []string `json:"eventTypes" validate:"required,min=1,dive,oneof=created updated deleted insufficient_balance daily_digest low_balance large_transaction"`
+}
+
+func (dto CreateWebhookSubscriptionRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type CreateWebhookSubscriptionResponse struct {
+	Subscription WebhookSubscription `json:"subscription"`
+	Secret       string              `json:"secret"`
+}
+
+type ListWebhookSubscriptionsRequest struct {
+	UserId string `json:"userId" validate:"required,uuid"`
+}
+
+func (dto ListWebhookSubscriptionsRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type ListWebhookSubscriptionsResponse struct {
+	Subscriptions []WebhookSubscription `json:"subscriptions"`
+}
+
+// WebhookSubscriptionRequest identifies a subscription of a user.
+type WebhookSubscriptionRequest struct {
+	Id     string `json:"id" validate:"required,uuid"`
+	UserId string `json:"userId" validate:"required,uuid"`
+}
+
+func (dto WebhookSubscriptionRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type RotateWebhookSecretResponse struct {
+	Subscription WebhookSubscription `json:"subscription"`
+	Secret       string              `json:"secret"`
+	// PreviousSecretExpiresAt is when payloads stop being signed with the replaced secret.
+	PreviousSecretExpiresAt time.Time `json:"previousSecretExpiresAt"`
+}
+
+type ListWebhookDeliveriesRequest struct {
+	SubscriptionId string `json:"subscriptionId" validate:"required,uuid"`
+	UserId         string `json:"userId" validate:"required,uuid"`
+	PageSize       int    `json:"pageSize" validate:"gte=0,lte=1000"`
+	PageToken      string `json:"pageToken"`
+}
+
+func (dto ListWebhookDeliveriesRequest) Validate(ctx context.Context) error {
+	validate := validator.New()
+	return validate.StructCtx(ctx, dto)
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries    []WebhookDelivery `json:"deliveries"`
+	NextPageToken string            `json:"nextPageToken"`
+}